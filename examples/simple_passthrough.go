@@ -48,10 +48,10 @@ func main() {
 
 	// 创建混音器配置(只使用一路输入)
 	config := audio.DefaultMixerConfig()
-	config.Input1Device = inputDevice
-	config.Input2Device = nil // 不使用第二路输入
+	config.Inputs = []audio.InputConfig{
+		{Name: "Microphone", Device: inputDevice, Gain: 1.0, Filter: audio.DefaultInputFilterConfig()}, // 100%音量, 去除直流偏移
+	}
 	config.OutputDevice = outputDevice
-	config.Input1Gain = 1.0 // 100%音量
 	config.MasterGain = 1.0
 
 	// 创建混音器
@@ -82,7 +82,7 @@ func main() {
 		for {
 			select {
 			case <-ticker.C:
-				level := mixer.GetInput1Level()
+				level := mixer.GetInputLevel(0)
 				db := levelToDB(level)
 				bar := getLevelBar(level, 40)
 				fmt.Printf("\rInput Level: %6.1f dB [%s]", db, bar)
@@ -0,0 +1,292 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// agcWindow is the time constant of the AGC's level measurement, the
+// short-term loudness window of EBU R 128
+const agcWindow = 3 * time.Second
+
+// AGCMode selects how the AGC measures level
+type AGCMode int
+
+const (
+	AGCModeRMS      AGCMode = iota // Unweighted RMS level in dBFS
+	AGCModeLoudness                // K-weighted loudness in LUFS after ITU-R BS.1770
+)
+
+// agcModeNames are the names used by String and ParseAGCMode
+var agcModeNames = map[AGCMode]string{
+	AGCModeRMS:      "rms",
+	AGCModeLoudness: "lufs",
+}
+
+// String returns the AGC mode name
+func (m AGCMode) String() string {
+	if name, ok := agcModeNames[m]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseAGCMode returns the AGC mode with the given name
+func ParseAGCMode(name string) (AGCMode, error) {
+	for mode, modeName := range agcModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return AGCModeRMS, fmt.Errorf("unknown AGC mode %q", name)
+}
+
+// AGCConfig configures the automatic gain control of an input
+type AGCConfig struct {
+	Enabled  bool
+	Mode     AGCMode
+	Target   float32 // Level to reach, in dBFS RMS or LUFS depending on Mode
+	MaxBoost float32 // Most gain the AGC may add, in dB
+	MaxCut   float32 // Most gain the AGC may take away, in dB
+	Rate     float32 // How fast the gain may change, in dB per second
+	Freeze   float32 // RMS level in dBFS below which the input counts as silent and the gain holds
+}
+
+// DefaultAGCConfig returns settings that bring speech to a common level
+func DefaultAGCConfig() AGCConfig {
+	return AGCConfig{
+		Target:   -20,
+		MaxBoost: 20,
+		MaxCut:   12,
+		Rate:     3,
+		Freeze:   -50,
+	}
+}
+
+// agc is an automatic gain control. It measures the input's level over
+// agcWindow, unweighted or K-weighted, and moves its gain dB-linearly
+// towards the difference from the target, no faster than the rate and
+// within the boost and cut limits. Buffers quieter than the freeze level
+// neither count towards the measurement nor change the gain, so pauses do
+// not pull silence up.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type agc struct {
+	config     atomic.Value // AGCConfig
+	sampleRate float64
+	channels   int
+
+	// K-weighting filters: a high shelf for the head's acoustic effect and
+	// a high-pass filter, after ITU-R BS.1770
+	shelf    biquadCoeffs
+	highPass biquadCoeffs
+
+	// Output callback state: the settings in use, the filter memory of
+	// every channel, the mean square of every channel over the window and
+	// the gain in dB
+	active      AGCConfig
+	freeze      float32
+	shelfState  []biquadState
+	highState   []biquadState
+	meanSquares []float64
+	measured    bool
+	gain        float32
+
+	applied atomic.Value // float32, dB: the published gain
+}
+
+// newAGC creates an AGC for interleaved audio at sampleRate
+func newAGC(cfg AGCConfig, channels int, sampleRate float64) *agc {
+	a := &agc{
+		sampleRate:  sampleRate,
+		channels:    channels,
+		shelfState:  make([]biquadState, channels),
+		highState:   make([]biquadState, channels),
+		meanSquares: make([]float64, channels),
+	}
+	a.shelf, a.highPass = kWeighting(sampleRate)
+	a.config.Store(cfg)
+	a.configure(cfg)
+	a.reset()
+	return a
+}
+
+// kWeighting returns the two filters of the ITU-R BS.1770 K-weighting at
+// sampleRate, derived from their analog prototypes as in libebur128
+func kWeighting(sampleRate float64) (shelf, highPass biquadCoeffs) {
+	// High shelf of about +4 dB above 1.5 kHz
+	const shelfFreq, shelfGain, shelfQ = 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * shelfFreq / sampleRate)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf = biquadCoeffs{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	// Second-order high-pass filter at 38 Hz
+	const highPassFreq, highPassQ = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * highPassFreq / sampleRate)
+	a0 = 1 + k/highPassQ + k*k
+	highPass = biquadCoeffs{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/highPassQ + k*k) / a0,
+	}
+	return shelf, highPass
+}
+
+// set publishes new settings, applied from the next buffer on
+func (a *agc) set(cfg AGCConfig) {
+	a.config.Store(cfg)
+}
+
+// get returns the current settings
+func (a *agc) get() AGCConfig {
+	return a.config.Load().(AGCConfig)
+}
+
+// configure derives the freeze level for cfg, measuring afresh when the
+// AGC is switched on or changes mode
+func (a *agc) configure(cfg AGCConfig) {
+	if cfg.Enabled != a.active.Enabled || cfg.Mode != a.active.Mode {
+		a.reset()
+	}
+	a.active = cfg
+	a.freeze = dbToGain(cfg.Freeze)
+}
+
+// reset forgets the measured level and returns to unity gain
+func (a *agc) reset() {
+	clear(a.shelfState)
+	clear(a.highState)
+	clear(a.meanSquares)
+	a.measured = false
+	a.gain = 0
+	a.applied.Store(float32(0))
+}
+
+// process measures buf, interleaved with channels channels, and applies
+// the AGC gain to it in place
+func (a *agc) process(buf []float32) {
+	if cfg := a.get(); cfg != a.active {
+		a.configure(cfg)
+	}
+	if !a.active.Enabled {
+		return
+	}
+
+	ch := a.channels
+	frames := len(buf) / ch
+	if frames == 0 {
+		return
+	}
+
+	// Hold the gain through silence, otherwise follow the level
+	target := a.gain
+	if calculateRMS(buf) >= a.freeze {
+		level := a.measure(buf, frames)
+		target = min(max(a.active.Target-level, -a.active.MaxCut), a.active.MaxBoost)
+	}
+
+	step := max(a.active.Rate, 0) / float32(a.sampleRate)
+	for f := 0; f < frames; f++ {
+		switch {
+		case a.gain < target:
+			a.gain = min(a.gain+step, target)
+		case a.gain > target:
+			a.gain = max(a.gain-step, target)
+		}
+		gain := dbToGain(a.gain)
+		for i := f * ch; i < f*ch+ch; i++ {
+			buf[i] *= gain
+		}
+	}
+	a.applied.Store(a.gain)
+}
+
+// measure adds buf to the level measurement and returns the level over
+// the window, in dBFS RMS or LUFS
+func (a *agc) measure(buf []float32, frames int) float32 {
+	ch := a.channels
+	coeff := 1 - math.Exp(-float64(frames)/(agcWindow.Seconds()*a.sampleRate))
+	if !a.measured {
+		coeff = 1
+		a.measured = true
+	}
+
+	total := 0.0
+	for c := 0; c < ch; c++ {
+		sum := 0.0
+		for i := c; i < len(buf); i += ch {
+			x := float64(buf[i])
+			if a.active.Mode == AGCModeLoudness {
+				x = a.highPass.process(&a.highState[c], a.shelf.process(&a.shelfState[c], x))
+			}
+			sum += x * x
+		}
+		a.meanSquares[c] += coeff * (sum/float64(frames) - a.meanSquares[c])
+		total += a.meanSquares[c]
+	}
+
+	if a.active.Mode == AGCModeLoudness {
+		// Channels add up in loudness
+		return float32(-0.691 + 10*math.Log10(total+1e-12))
+	}
+	return float32(10 * math.Log10(total/float64(ch)+1e-12))
+}
+
+// agcParams are the AGC's processor parameters; mode is 0 for RMS and 1
+// for loudness
+var agcParams = params[AGCConfig]{
+	boolParam("enabled", true, func(c *AGCConfig) *bool { return &c.Enabled }),
+	{
+		Parameter: Parameter{Name: "mode", Max: float64(AGCModeLoudness), Step: 1},
+		get:       func(c *AGCConfig) float64 { return float64(c.Mode) },
+		set:       func(c *AGCConfig, value float64) { c.Mode = AGCMode(math.Round(value)) },
+	},
+	floatParam("target", -60, 0, -20, "dB", func(c *AGCConfig) *float32 { return &c.Target }),
+	floatParam("max_boost", 0, 40, 20, "dB", func(c *AGCConfig) *float32 { return &c.MaxBoost }),
+	floatParam("max_cut", 0, 40, 12, "dB", func(c *AGCConfig) *float32 { return &c.MaxCut }),
+	floatParam("rate", 0.1, 60, 3, "dB/s", func(c *AGCConfig) *float32 { return &c.Rate }),
+	floatParam("freeze", -90, 0, -50, "dB", func(c *AGCConfig) *float32 { return &c.Freeze }),
+}
+
+// Type implements Processor
+func (a *agc) Type() string { return "agc" }
+
+// Channels implements Processor
+func (a *agc) Channels() int { return a.channels }
+
+// Process implements Processor
+func (a *agc) Process(buf []float32) { a.process(buf) }
+
+// Latency implements Processor; the AGC adds none
+func (a *agc) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (a *agc) Reset() { a.reset() }
+
+// Parameters implements Processor
+func (a *agc) Parameters() []Parameter { return agcParams.parameters() }
+
+// Parameter implements Processor
+func (a *agc) Parameter(name string) (float64, error) { return agcParams.value(a.get(), name) }
+
+// SetParameter implements Processor
+func (a *agc) SetParameter(name string, value float64) error {
+	cfg, err := agcParams.update(a.get(), name, value)
+	if err == nil {
+		a.set(cfg)
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"time"
+)
+
+// AlignCaptureTime is how long AlignInput listens to both inputs; the
+// pulse has to fall within it on both
+const AlignCaptureTime = 3 * time.Second
+
+// Thresholds below which AlignInput does not trust its measurement
+const (
+	alignMinLevel      = -50 // dBFS, peak level each input must reach
+	alignMinConfidence = 8   // Correlation peak over its RMS
+)
+
+// Alignment is the result of lining an input up with a reference input
+type Alignment struct {
+	Offset         time.Duration // How much later the input heard the pulse than the reference; negative if earlier
+	Confidence     float64       // How clearly the pulse stood out, the correlation peak over its RMS
+	ReferenceDelay time.Duration // Delay given to the reference input
+	Delay          time.Duration // Delay given to the input
+}
+
+// FormatDelay formats a delay in milliseconds and samples at sampleRate
+func FormatDelay(delay time.Duration, sampleRate float64) string {
+	return fmt.Sprintf("%.2f ms (%.0f samples)", float64(delay)/float64(time.Millisecond), delay.Seconds()*sampleRate)
+}
+
+// alignCapture records the processed, not yet delayed audio of two inputs,
+// mixed down to mono, for AlignInput. Only the output callback writes to
+// it; it closes done once both recordings are full.
+type alignCapture struct {
+	reference, target *inputStrip
+	ref, tgt          []float32
+	refPos, tgtPos    int
+	done              chan struct{}
+}
+
+// add records in, interleaved with channels channels, if it belongs to one
+// of the captured inputs
+func (c *alignCapture) add(strip *inputStrip, in []float32, channels int) {
+	var rec []float32
+	var pos *int
+	switch strip {
+	case c.reference:
+		rec, pos = c.ref, &c.refPos
+	case c.target:
+		rec, pos = c.tgt, &c.tgtPos
+	default:
+		return
+	}
+
+	for f := 0; f < len(in)/channels && *pos < len(rec); f++ {
+		var sum float32
+		for _, sample := range in[f*channels : f*channels+channels] {
+			sum += sample
+		}
+		rec[*pos] = sum / float32(channels)
+		*pos++
+	}
+}
+
+// full reports whether both recordings are complete
+func (c *alignCapture) full() bool {
+	return c.refPos == len(c.ref) && c.tgtPos == len(c.tgt)
+}
+
+// AlignInput measures how far the input at index lags the reference input
+// by cross-correlating a clap or test pulse both pick up within
+// AlignCaptureTime, then sets their delays so the pulse lines up; only the
+// later one of the two is held back further. It blocks while it listens,
+// so make the sound after calling it.
+func (m *Mixer) AlignInput(reference, index int) (Alignment, error) {
+	ref, tgt := m.input(reference), m.input(index)
+	if ref == nil || tgt == nil || ref == tgt {
+		return Alignment{}, fmt.Errorf("alignment needs two different inputs")
+	}
+	if !m.IsRunning() {
+		return Alignment{}, fmt.Errorf("mixer is not running")
+	}
+
+	m.alignMu.Lock()
+	defer m.alignMu.Unlock()
+
+	frames := int(AlignCaptureTime.Seconds() * m.config.SampleRate)
+	capture := &alignCapture{
+		reference: ref,
+		target:    tgt,
+		ref:       make([]float32, frames),
+		tgt:       make([]float32, frames),
+		done:      make(chan struct{}),
+	}
+	m.alignment.Store(capture)
+	select {
+	case <-capture.done:
+	case <-time.After(2 * AlignCaptureTime):
+		m.alignment.CompareAndSwap(capture, nil)
+		return Alignment{}, fmt.Errorf("no audio arrived within %v", 2*AlignCaptureTime)
+	}
+
+	for i, rec := range [][]float32{capture.ref, capture.tgt} {
+		peak := float32(0)
+		for _, sample := range rec {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+		if gainToDB(peak) < alignMinLevel {
+			return Alignment{}, fmt.Errorf("input %d heard no pulse", []int{reference, index}[i]+1)
+		}
+	}
+
+	maxLag := int(MaxInputDelay.Seconds() * m.config.SampleRate)
+	lag, confidence := crossCorrelate(capture.ref, capture.tgt, maxLag)
+	result := Alignment{
+		Offset:     framesDuration(lag, m.config.SampleRate),
+		Confidence: confidence,
+	}
+	if confidence < alignMinConfidence {
+		return result, fmt.Errorf("no clear pulse found (confidence %.1f); clap once, sharply, where both inputs hear it", confidence)
+	}
+
+	// The pulse lines up once the reference's delay exceeds the input's by
+	// lag; keep the reference's delay and change the input's if possible
+	refDelay := ref.delay.get()
+	delay := refDelay - lag
+	if delay < 0 {
+		refDelay, delay = lag, 0
+	}
+	if refDelay > ref.delay.maxFrames || delay > tgt.delay.maxFrames {
+		return result, fmt.Errorf("offset of %v needs more than the maximum delay of %v", result.Offset.Round(time.Millisecond), MaxInputDelay)
+	}
+	ref.delay.set(refDelay)
+	tgt.delay.set(delay)
+	result.ReferenceDelay = framesDuration(refDelay, m.config.SampleRate)
+	result.Delay = framesDuration(delay, m.config.SampleRate)
+	return result, nil
+}
+
+// crossCorrelate returns the lag of at most maxLag frames by which tgt
+// follows ref, negative if it leads, and how clearly the correlation peaks
+// there. It uses the phase transform (GCC-PHAT), which whitens both
+// signals so the peak stays sharp even when the two inputs colour the
+// pulse differently.
+func crossCorrelate(ref, tgt []float32, maxLag int) (lag int, confidence float64) {
+	size := 1
+	for size < len(ref)+len(tgt) {
+		size *= 2
+	}
+	transform := newFFT(size)
+	x := make([]complex128, size)
+	y := make([]complex128, size)
+	for i, sample := range ref {
+		x[i] = complex(float64(sample), 0)
+	}
+	for i, sample := range tgt {
+		y[i] = complex(float64(sample), 0)
+	}
+	transform.forward(x)
+	transform.forward(y)
+
+	for k := range x {
+		cross := cmplx.Conj(x[k]) * y[k]
+		if magnitude := cmplx.Abs(cross); magnitude > 1e-12 {
+			x[k] = cross / complex(magnitude, 0)
+		} else {
+			x[k] = 0
+		}
+	}
+	transform.inverse(x)
+
+	// Lags from -maxLag to maxLag; negative ones wrap around to the end
+	best, sumSquares, count := 0.0, 0.0, 0
+	for l := -maxLag; l <= maxLag; l++ {
+		v := real(x[(l+size)%size])
+		sumSquares += v * v
+		count++
+		if math.Abs(v) > best {
+			best, lag = math.Abs(v), l
+		}
+	}
+	rms := math.Sqrt(sumSquares / float64(count))
+	if rms == 0 {
+		return 0, 0
+	}
+	return lag, best / rms
+}
@@ -0,0 +1,156 @@
+package audio
+
+import "sync/atomic"
+
+// MaxAuxBuses is the most aux buses a mixer can have
+const MaxAuxBuses = 4
+
+// AuxBusConfig describes an aux bus: inputs send to it at their own send
+// levels, its insert chain turns the sum into an effect, such as a reverb or
+// echo, and it returns to the master bus
+type AuxBusConfig struct {
+	Name    string         // Display name, e.g. "Reverb"
+	Return  float32        // Return level to the master bus, 0.0 to 2.0
+	Inserts []InsertConfig // Effects, usually set to effect only (mix 1)
+}
+
+// auxBus is an aux bus of the mixer
+type auxBus struct {
+	name  string
+	chain *InsertChain
+	ret   atomic.Value // float32
+
+	// Output callback state: the sum of the sends and the ramp towards ret
+	buf      []float32
+	smoother gainSmoother
+}
+
+// newAuxBus creates an aux bus for buffers of up to bufferSize samples
+func newAuxBus(cfg AuxBusConfig, bufferSize, channels int, sampleRate float64) (*auxBus, error) {
+	chain, err := newInsertChain(cfg.Inserts, nil, channels, sampleRate, bufferSize/channels)
+	if err != nil {
+		return nil, err
+	}
+	bus := &auxBus{
+		name:  cfg.Name,
+		chain: chain,
+		buf:   make([]float32, bufferSize),
+	}
+	bus.ret.Store(clampGain(cfg.Return))
+	return bus, nil
+}
+
+// reset silences the bus before the mixer starts again
+func (b *auxBus) reset() {
+	clear(b.buf)
+	b.smoother.reset()
+	b.chain.Reset()
+}
+
+// mixReturn runs the sends summed into the bus through its chain and adds
+// the result to mix at the return level, ramped over rampFrames frames
+func (b *auxBus) mixReturn(mix []float32, channels, rampFrames int) {
+	buf := b.buf[:len(mix)]
+	b.chain.Process(buf)
+
+	b.smoother.setTarget(b.ret.Load().(float32), rampFrames)
+	for f := 0; f < len(mix)/channels; f++ {
+		gain := b.smoother.next()
+		for i := f * channels; i < f*channels+channels; i++ {
+			mix[i] += buf[i] * gain
+		}
+	}
+}
+
+// sending reports whether the strip sends to any aux bus, or is still
+// ramping a send down
+func (s *inputStrip) sending() bool {
+	for b := range s.sends {
+		if s.sends[b].Load().(float32) != 0 || s.sendSmoothers[b].current != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// send adds post, the strip's output after gain, pan and mute, to every aux
+// bus at the strip's send level for it, ramped over rampFrames frames
+func (s *inputStrip) send(buses []*auxBus, post []float32, channels, rampFrames int) {
+	for b, bus := range buses {
+		smoother := &s.sendSmoothers[b]
+		smoother.setTarget(s.sends[b].Load().(float32), rampFrames)
+		if smoother.settled() && smoother.current == 0 {
+			continue
+		}
+
+		buf := bus.buf[:len(post)]
+		for f := 0; f < len(post)/channels; f++ {
+			level := smoother.next()
+			for i := f * channels; i < f*channels+channels; i++ {
+				buf[i] += post[i] * level
+			}
+		}
+	}
+}
+
+// auxBus returns the aux bus at index, or nil if out of range
+func (m *Mixer) auxBus(index int) *auxBus {
+	if index < 0 || index >= len(m.auxBuses) {
+		return nil
+	}
+	return m.auxBuses[index]
+}
+
+// NumAuxBuses returns the number of aux buses
+func (m *Mixer) NumAuxBuses() int {
+	return len(m.auxBuses)
+}
+
+// GetAuxBusName returns the display name of an aux bus
+func (m *Mixer) GetAuxBusName(bus int) string {
+	if b := m.auxBus(bus); b != nil {
+		return b.name
+	}
+	return ""
+}
+
+// AuxChain returns the insert chain of an aux bus, or nil if there is no
+// such bus; it may be edited while audio runs
+func (m *Mixer) AuxChain(bus int) *InsertChain {
+	if b := m.auxBus(bus); b != nil {
+		return b.chain
+	}
+	return nil
+}
+
+// SetAuxReturn sets the level an aux bus returns to the master bus at (0.0
+// to 2.0)
+func (m *Mixer) SetAuxReturn(bus int, gain float32) {
+	if b := m.auxBus(bus); b != nil {
+		b.ret.Store(clampGain(gain))
+	}
+}
+
+// GetAuxReturn returns the return level of an aux bus
+func (m *Mixer) GetAuxReturn(bus int) float32 {
+	if b := m.auxBus(bus); b != nil {
+		return b.ret.Load().(float32)
+	}
+	return 0
+}
+
+// SetInputSend sets the level an input sends to an aux bus at (0.0 to 2.0),
+// taken after the input's gain, pan and mute
+func (m *Mixer) SetInputSend(index, bus int, level float32) {
+	if strip := m.input(index); strip != nil && bus >= 0 && bus < len(strip.sends) {
+		strip.sends[bus].Store(clampGain(level))
+	}
+}
+
+// GetInputSend returns the level an input sends to an aux bus at
+func (m *Mixer) GetInputSend(index, bus int) float32 {
+	if strip := m.input(index); strip != nil && bus >= 0 && bus < len(strip.sends) {
+		return strip.sends[bus].Load().(float32)
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+)
+
+// StreamParams describes a single-direction stream opened through a Backend
+type StreamParams struct {
+	Device          *DeviceInfo
+	Channels        int
+	SampleRate      float64
+	FramesPerBuffer int
+	Latency         time.Duration // 0 lets the backend pick the device's low-latency default
+}
+
+// Stream is an opened audio stream owned by a Backend
+type Stream interface {
+	// Start begins invoking the stream callback
+	Start() error
+
+	// Stop stops invoking the callback; a stopped stream may be started again
+	Stop() error
+
+	// Close releases the stream; it must not be used afterwards
+	Close() error
+}
+
+// Backend opens audio streams for the mixer. Callbacks run on the backend's
+// audio thread and receive interleaved float32 samples of
+// FramesPerBuffer * Channels length.
+type Backend interface {
+	// Name returns a human readable backend name
+	Name() string
+
+	// OpenInputStream opens a capture stream that delivers samples to callback
+	OpenInputStream(params StreamParams, callback func(in []float32)) (Stream, error)
+
+	// OpenOutputStream opens a playback stream that asks callback to fill out
+	OpenOutputStream(params StreamParams, callback func(out []float32)) (Stream, error)
+}
+
+// StreamInfo reports how a device stream was opened by the mixer
+type StreamInfo struct {
+	Device     *DeviceInfo
+	SampleRate float64    // Rate the device stream runs at, 0 if not open
+	MixerRate  float64    // Internal mixer rate the stream is converted to or from
+	ChannelMap ChannelMap // Device channels to mixer channels, or the reverse for output
+}
+
+// Resampled returns whether the stream is converted to or from the mixer rate
+func (s StreamInfo) Resampled() bool {
+	return s.SampleRate > 0 && s.SampleRate != s.MixerRate
+}
+
+// String describes the stream's device and sample rate conversion
+func (s StreamInfo) String() string {
+	name := "<none>"
+	if s.Device != nil {
+		name = s.Device.Name
+	}
+
+	if s.SampleRate <= 0 {
+		return fmt.Sprintf("%s (not open)", name)
+	}
+
+	desc := fmt.Sprintf("%s @ %.0f Hz", name, s.SampleRate)
+	if s.Resampled() {
+		desc += fmt.Sprintf(" (resampled, mixer %.0f Hz)", s.MixerRate)
+	}
+	if s.ChannelMap != nil && !s.ChannelMap.IsIdentity() {
+		desc += fmt.Sprintf(" [channels %s]", s.ChannelMap)
+	}
+	return desc
+}
@@ -0,0 +1,10 @@
+//go:build !cgo
+// +build !cgo
+
+package audio
+
+// DefaultBackend returns the backend used when MixerConfig.Backend is nil.
+// Without cgo PortAudio is unavailable, so the null backend is used.
+func DefaultBackend() Backend {
+	return NewNullBackend()
+}
@@ -0,0 +1,318 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Device indices exposed by the null backend
+const (
+	NullInputDeviceIndex  = 0
+	NullOutputDeviceIndex = 1
+)
+
+// nullDeviceChannels is the channel count of every null backend device
+const nullDeviceChannels = 8
+
+// nullDevices returns the virtual devices offered by the null backend
+func nullDevices() []*DeviceInfo {
+	return []*DeviceInfo{
+		{
+			Index:             NullInputDeviceIndex,
+			Name:              "Null Input",
+			MaxInputChannels:  nullDeviceChannels,
+			DefaultSampleRate: DefaultSampleRate,
+			IsDefaultInput:    true,
+			HostAPI:           "Null",
+		},
+		{
+			Index:             NullOutputDeviceIndex,
+			Name:              "Null Output",
+			MaxOutputChannels: nullDeviceChannels,
+			DefaultSampleRate: DefaultSampleRate,
+			IsDefaultOutput:   true,
+			HostAPI:           "Null",
+		},
+	}
+}
+
+// NullBackend is a pure-Go in-memory backend. Input streams read from
+// optional per-device sources (silence otherwise) and output streams hand
+// the mixed signal to optional per-device sinks, so the complete mixing path
+// runs without a sound card or cgo.
+//
+// A backend created with NewNullBackend clocks every stream in real time on
+// its own goroutine. One created with NewManualNullBackend never runs on its
+// own; call Pump to process one buffer period deterministically.
+type NullBackend struct {
+	manual bool
+
+	mu      sync.Mutex
+	sources map[int]func(buf []float32)
+	sinks   map[int]func(buf []float32)
+	rates   map[int]float64
+	streams []*nullStream
+}
+
+// NewNullBackend creates a null backend clocked in real time
+func NewNullBackend() *NullBackend {
+	return &NullBackend{
+		sources: make(map[int]func(buf []float32)),
+		sinks:   make(map[int]func(buf []float32)),
+		rates:   make(map[int]float64),
+	}
+}
+
+// NewManualNullBackend creates a null backend that only advances on Pump
+func NewManualNullBackend() *NullBackend {
+	b := NewNullBackend()
+	b.manual = true
+	return b
+}
+
+// Name returns the backend name
+func (b *NullBackend) Name() string {
+	return "Null"
+}
+
+// Devices returns the virtual devices of the null backend
+func (b *NullBackend) Devices() []*DeviceInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	devices := nullDevices()
+	for _, dev := range devices {
+		if rate, ok := b.rates[dev.Index]; ok {
+			dev.DefaultSampleRate = rate
+		}
+	}
+	return devices
+}
+
+// SetSampleRate restricts a device index to a single sample rate, which it
+// also reports as its default. Passing 0 accepts any rate again.
+func (b *NullBackend) SetSampleRate(deviceIndex int, rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if rate <= 0 {
+		delete(b.rates, deviceIndex)
+		return
+	}
+	b.rates[deviceIndex] = rate
+}
+
+// SetSource installs a generator that fills capture buffers for a device index.
+// Passing nil restores silence.
+func (b *NullBackend) SetSource(deviceIndex int, source func(buf []float32)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if source == nil {
+		delete(b.sources, deviceIndex)
+		return
+	}
+	b.sources[deviceIndex] = source
+}
+
+// SetSink installs a consumer that receives every playback buffer for a device index.
+// Passing nil discards the output.
+func (b *NullBackend) SetSink(deviceIndex int, sink func(buf []float32)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sink == nil {
+		delete(b.sinks, deviceIndex)
+		return
+	}
+	b.sinks[deviceIndex] = sink
+}
+
+// OpenInputStream opens a virtual capture stream
+func (b *NullBackend) OpenInputStream(params StreamParams, callback func(in []float32)) (Stream, error) {
+	return b.openStream(params, false, callback)
+}
+
+// OpenOutputStream opens a virtual playback stream
+func (b *NullBackend) OpenOutputStream(params StreamParams, callback func(out []float32)) (Stream, error) {
+	return b.openStream(params, true, callback)
+}
+
+// Pump processes one buffer period on every started stream, inputs first
+func (b *NullBackend) Pump() {
+	b.mu.Lock()
+	streams := make([]*nullStream, len(b.streams))
+	copy(streams, b.streams)
+	b.mu.Unlock()
+
+	for _, s := range streams {
+		if !s.output && s.running.Load() {
+			s.process()
+		}
+	}
+	for _, s := range streams {
+		if s.output && s.running.Load() {
+			s.process()
+		}
+	}
+}
+
+func (b *NullBackend) openStream(params StreamParams, output bool, callback func([]float32)) (Stream, error) {
+	if params.Device == nil {
+		return nil, fmt.Errorf("no device specified")
+	}
+	if params.Channels <= 0 {
+		return nil, fmt.Errorf("invalid channel count %d", params.Channels)
+	}
+	if params.SampleRate <= 0 || params.FramesPerBuffer <= 0 {
+		return nil, fmt.Errorf("invalid stream format")
+	}
+
+	b.mu.Lock()
+	rate, restricted := b.rates[params.Device.Index]
+	b.mu.Unlock()
+	if restricted && params.SampleRate != rate {
+		return nil, fmt.Errorf("invalid sample rate %.0f Hz (device supports %.0f Hz)", params.SampleRate, rate)
+	}
+
+	s := &nullStream{
+		backend:  b,
+		device:   params.Device.Index,
+		output:   output,
+		callback: callback,
+		buf:      make([]float32, params.FramesPerBuffer*params.Channels),
+		period:   time.Duration(float64(params.FramesPerBuffer) / params.SampleRate * float64(time.Second)),
+	}
+
+	b.mu.Lock()
+	b.streams = append(b.streams, s)
+	b.mu.Unlock()
+
+	return s, nil
+}
+
+func (b *NullBackend) removeStream(s *nullStream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, stream := range b.streams {
+		if stream == s {
+			b.streams = append(b.streams[:i], b.streams[i+1:]...)
+			return
+		}
+	}
+}
+
+// nullStream is a Stream of the null backend
+type nullStream struct {
+	backend  *NullBackend
+	device   int
+	output   bool
+	callback func([]float32)
+	buf      []float32
+	period   time.Duration
+
+	running atomic.Bool
+	mu      sync.Mutex
+	stopCh  chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+// Start starts the stream
+func (s *nullStream) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("stream closed")
+	}
+	if s.running.Load() {
+		return nil
+	}
+
+	s.running.Store(true)
+	if !s.backend.manual {
+		s.stopCh = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.run(s.stopCh, s.done)
+	}
+	return nil
+}
+
+// Stop stops the stream
+func (s *nullStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running.Load() {
+		return nil
+	}
+
+	s.running.Store(false)
+	if s.stopCh != nil {
+		close(s.stopCh)
+		<-s.done
+		s.stopCh = nil
+		s.done = nil
+	}
+	return nil
+}
+
+// Close stops and releases the stream
+func (s *nullStream) Close() error {
+	if err := s.Stop(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.backend.removeStream(s)
+	return nil
+}
+
+// run clocks the stream in real time until stopCh is closed
+func (s *nullStream) run(stopCh, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.process()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// process runs one buffer period through the callback
+func (s *nullStream) process() {
+	for i := range s.buf {
+		s.buf[i] = 0
+	}
+
+	s.backend.mu.Lock()
+	source := s.backend.sources[s.device]
+	sink := s.backend.sinks[s.device]
+	s.backend.mu.Unlock()
+
+	if s.output {
+		s.callback(s.buf)
+		if sink != nil {
+			sink(s.buf)
+		}
+		return
+	}
+
+	if source != nil {
+		source(s.buf)
+	}
+	s.callback(s.buf)
+}
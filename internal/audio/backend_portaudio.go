@@ -0,0 +1,105 @@
+//go:build cgo
+// +build cgo
+
+package audio
+
+import (
+	"fmt"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// PortAudioBackend opens streams on real sound cards through PortAudio.
+// PortAudio must already be initialized (see DeviceManager.Initialize).
+type PortAudioBackend struct{}
+
+// NewPortAudioBackend creates a new PortAudio backend
+func NewPortAudioBackend() *PortAudioBackend {
+	return &PortAudioBackend{}
+}
+
+// DefaultBackend returns the backend used when MixerConfig.Backend is nil
+func DefaultBackend() Backend {
+	return NewPortAudioBackend()
+}
+
+// Name returns the backend name
+func (b *PortAudioBackend) Name() string {
+	return "PortAudio"
+}
+
+// OpenInputStream opens a PortAudio capture stream
+func (b *PortAudioBackend) OpenInputStream(params StreamParams, callback func(in []float32)) (Stream, error) {
+	dev, err := portAudioDevice(params.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	latency := params.Latency
+	if latency == 0 {
+		latency = dev.DefaultLowInputLatency
+	}
+
+	paParams := portaudio.StreamParameters{
+		Input: portaudio.StreamDeviceParameters{
+			Device:   dev,
+			Channels: params.Channels,
+			Latency:  latency,
+		},
+		SampleRate:      params.SampleRate,
+		FramesPerBuffer: params.FramesPerBuffer,
+	}
+
+	stream, err := portaudio.OpenStream(paParams, callback)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// OpenOutputStream opens a PortAudio playback stream
+func (b *PortAudioBackend) OpenOutputStream(params StreamParams, callback func(out []float32)) (Stream, error) {
+	dev, err := portAudioDevice(params.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	latency := params.Latency
+	if latency == 0 {
+		latency = dev.DefaultLowOutputLatency
+	}
+
+	paParams := portaudio.StreamParameters{
+		Output: portaudio.StreamDeviceParameters{
+			Device:   dev,
+			Channels: params.Channels,
+			Latency:  latency,
+		},
+		SampleRate:      params.SampleRate,
+		FramesPerBuffer: params.FramesPerBuffer,
+	}
+
+	stream, err := portaudio.OpenStream(paParams, callback)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// portAudioDevice resolves a DeviceInfo to the PortAudio device with the same index
+func portAudioDevice(info *DeviceInfo) (*portaudio.DeviceInfo, error) {
+	if info == nil {
+		return nil, fmt.Errorf("no device specified")
+	}
+
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate devices: %w", err)
+	}
+
+	if info.Index < 0 || info.Index >= len(devices) {
+		return nil, fmt.Errorf("device index %d out of range", info.Index)
+	}
+
+	return devices[info.Index], nil
+}
@@ -2,6 +2,7 @@ package audio
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // BufferPool manages audio buffer allocation to reduce GC pressure
@@ -38,58 +39,166 @@ func (bp *BufferPool) Put(buf []float32) {
 	bp.pool.Put(&buf)
 }
 
-// AudioBuffer represents a thread-safe ring buffer for audio samples
+// UnderrunFill selects what AudioBuffer.Read returns for samples it does not have
+type UnderrunFill int
+
+const (
+	UnderrunSilence UnderrunFill = iota // Fill with zeros
+	UnderrunFadeOut                     // Repeat the last frame, fading it to zero
+)
+
+// XrunStats counts buffer underruns and overruns
+type XrunStats struct {
+	Underruns uint64 // Reads that found fewer samples than requested
+	Overruns  uint64 // Writes that dropped samples because the buffer was full
+}
+
+// AudioBuffer is a lock-free single-producer/single-consumer ring buffer for
+// audio samples. One goroutine may call Write while another calls Read;
+// neither ever blocks, so both can run on realtime audio callbacks.
+//
+// Read and write positions are free-running sample counters published with
+// atomics: the producer owns writePos, the consumer owns readPos.
 type AudioBuffer struct {
-	data     []float32
-	readPos  int
-	writePos int
-	size     int
-	mu       sync.RWMutex
+	data []float32
+	size uint64
+
+	writePos atomic.Uint64
+	_        [56]byte // Keep the two positions on separate cache lines
+	readPos  atomic.Uint64
+
+	// Underrun handling, consumer side: lastFrame is the most recent frame
+	// handed out, primed is set by the first write so start-up is not counted
+	fill      UnderrunFill
+	lastFrame []float32
+	primed    atomic.Bool
+
+	underruns atomic.Uint64
+	overruns  atomic.Uint64
 }
 
-// NewAudioBuffer creates a new ring buffer
+// NewAudioBuffer creates a new ring buffer holding up to size samples
 func NewAudioBuffer(size int) *AudioBuffer {
 	return &AudioBuffer{
-		data: make([]float32, size),
-		size: size,
+		data:      make([]float32, size),
+		size:      uint64(size),
+		lastFrame: make([]float32, 1),
 	}
 }
 
-// Write writes samples to the buffer
+// SetUnderrunFill selects how underruns are filled; channels is the number
+// of interleaved channels, needed to repeat whole frames. It must not be
+// called while Read may run.
+func (ab *AudioBuffer) SetUnderrunFill(fill UnderrunFill, channels int) {
+	ab.fill = fill
+	ab.lastFrame = make([]float32, max(channels, 1))
+}
+
+// Write stores samples in the buffer and returns how many fit. Samples that
+// do not fit because the consumer fell behind are dropped and an overrun is
+// counted. Only one goroutine may write at a time.
 func (ab *AudioBuffer) Write(samples []float32) int {
-	ab.mu.Lock()
-	defer ab.mu.Unlock()
-
-	written := 0
-	for _, sample := range samples {
-		ab.data[ab.writePos] = sample
-		ab.writePos = (ab.writePos + 1) % ab.size
-		written++
+	ab.primed.Store(true)
+
+	writePos := ab.writePos.Load()
+	free := ab.size - (writePos - ab.readPos.Load())
+
+	n := uint64(len(samples))
+	if n > free {
+		n = free
+		ab.overruns.Add(1)
 	}
-	return written
+
+	// Copy in at most two blocks around the end of the ring
+	offset := writePos % ab.size
+	first := copy(ab.data[offset:], samples[:n])
+	copy(ab.data, samples[first:n])
+
+	ab.writePos.Store(writePos + n)
+	return int(n)
 }
 
-// Read reads samples from the buffer
+// Read reads samples from the buffer and returns how many were available.
+// Missing samples are filled according to the underrun fill mode and an
+// underrun is counted. Only one goroutine may read at a time.
 func (ab *AudioBuffer) Read(samples []float32) int {
-	ab.mu.RLock()
-	defer ab.mu.RUnlock()
+	readPos := ab.readPos.Load()
+	available := ab.writePos.Load() - readPos
 
-	read := 0
-	for i := range samples {
-		samples[i] = ab.data[ab.readPos]
-		ab.readPos = (ab.readPos + 1) % ab.size
-		read++
+	n := min(uint64(len(samples)), available)
+
+	// Copy out at most two blocks around the end of the ring
+	offset := readPos % ab.size
+	first := copy(samples[:n], ab.data[offset:])
+	copy(samples[first:n], ab.data)
+
+	ab.readPos.Store(readPos + n)
+
+	read := int(n)
+	ch := len(ab.lastFrame)
+	if read >= ch {
+		// Remember the last complete frame for fading out a later underrun
+		copy(ab.lastFrame, samples[read-read%ch-ch:read-read%ch])
+	}
+
+	if read < len(samples) {
+		ab.fillUnderrun(samples[read:])
+		if ab.primed.Load() {
+			ab.underruns.Add(1)
+		}
 	}
 	return read
 }
 
+// fillUnderrun fills the samples a read could not provide
+func (ab *AudioBuffer) fillUnderrun(samples []float32) {
+	if ab.fill == UnderrunSilence {
+		for i := range samples {
+			samples[i] = 0
+		}
+		return
+	}
+
+	// Ramp the last frame linearly down to zero across the gap; the
+	// silenced frame is remembered so a longer dropout stays silent
+	ch := len(ab.lastFrame)
+	frames := (len(samples) + ch - 1) / ch
+	for i := range samples {
+		f := i / ch
+		gain := float32(frames-f-1) / float32(frames)
+		samples[i] = ab.lastFrame[i%ch] * gain
+	}
+	for c := range ab.lastFrame {
+		ab.lastFrame[c] = 0
+	}
+}
+
+// Reset discards all buffered samples and clears the xrun statistics.
+// It must not run concurrently with Read or Write.
+func (ab *AudioBuffer) Reset() {
+	for i := range ab.data {
+		ab.data[i] = 0
+	}
+	for c := range ab.lastFrame {
+		ab.lastFrame[c] = 0
+	}
+	ab.readPos.Store(0)
+	ab.writePos.Store(0)
+	ab.primed.Store(false)
+	ab.underruns.Store(0)
+	ab.overruns.Store(0)
+}
+
 // Available returns the number of samples available to read
 func (ab *AudioBuffer) Available() int {
-	ab.mu.RLock()
-	defer ab.mu.RUnlock()
+	readPos := ab.readPos.Load()
+	return int(ab.writePos.Load() - readPos)
+}
 
-	if ab.writePos >= ab.readPos {
-		return ab.writePos - ab.readPos
+// Stats returns the underrun and overrun counts since the last Reset
+func (ab *AudioBuffer) Stats() XrunStats {
+	return XrunStats{
+		Underruns: ab.underruns.Load(),
+		Overruns:  ab.overruns.Load(),
 	}
-	return ab.size - ab.readPos + ab.writePos
 }
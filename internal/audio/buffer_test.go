@@ -0,0 +1,258 @@
+package audio
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// sampleRing is what the benchmarks need of a ring buffer
+type sampleRing interface {
+	Write(samples []float32) int
+	Read(samples []float32) int
+}
+
+// lockedAudioBuffer is the ring buffer AudioBuffer replaced, exactly as it
+// was, kept to compare against: a lock around a sample-by-sample copy that
+// does not track how much is buffered, so it never runs dry or full
+type lockedAudioBuffer struct {
+	data     []float32
+	readPos  int
+	writePos int
+	size     int
+	mu       sync.RWMutex
+}
+
+func newLockedAudioBuffer(size int) *lockedAudioBuffer {
+	return &lockedAudioBuffer{
+		data: make([]float32, size),
+		size: size,
+	}
+}
+
+func (ab *lockedAudioBuffer) Write(samples []float32) int {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	written := 0
+	for _, sample := range samples {
+		ab.data[ab.writePos] = sample
+		ab.writePos = (ab.writePos + 1) % ab.size
+		written++
+	}
+	return written
+}
+
+func (ab *lockedAudioBuffer) Read(samples []float32) int {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	read := 0
+	for i := range samples {
+		samples[i] = ab.data[ab.readPos]
+		ab.readPos = (ab.readPos + 1) % ab.size
+		read++
+	}
+	return read
+}
+
+func (ab *lockedAudioBuffer) Available() int {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	if ab.writePos >= ab.readPos {
+		return ab.writePos - ab.readPos
+	}
+	return ab.size - ab.readPos + ab.writePos
+}
+
+// TestAudioBufferConcurrent streams a counting sequence through a small
+// buffer from one goroutine to another, in chunk sizes that do not divide
+// the buffer so reads and writes wrap around at every offset; run it with
+// -race
+func TestAudioBufferConcurrent(t *testing.T) {
+	const total = 1 << 20
+	ab := NewAudioBuffer(257)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		chunk := make([]float32, 61)
+		next := 0
+		for next < total {
+			n := min(len(chunk), total-next)
+			for i := range chunk[:n] {
+				chunk[i] = float32(next + i)
+			}
+			for written := 0; written < n; {
+				written += ab.Write(chunk[written:n])
+				if written < n {
+					runtime.Gosched()
+				}
+			}
+			next += n
+		}
+	}()
+
+	buf := make([]float32, 37)
+	expected := 0
+	for expected < total {
+		n := ab.Read(buf)
+		for i, sample := range buf[:n] {
+			if sample != float32(expected) {
+				t.Fatalf("sample %d of a read: got %v, want %d", i, sample, expected)
+			}
+			expected++
+		}
+		if n == 0 {
+			runtime.Gosched()
+		}
+	}
+	<-done
+
+	if n := ab.Available(); n != 0 {
+		t.Errorf("Available after draining = %d, want 0", n)
+	}
+}
+
+func TestAudioBufferOverrun(t *testing.T) {
+	ab := NewAudioBuffer(8)
+	in := []float32{1, 2, 3, 4, 5, 6}
+
+	if n := ab.Write(in); n != 6 {
+		t.Fatalf("first Write = %d, want 6", n)
+	}
+	if n := ab.Write(in); n != 2 {
+		t.Fatalf("Write into a nearly full buffer = %d, want 2", n)
+	}
+	if stats := ab.Stats(); stats.Overruns != 1 || stats.Underruns != 0 {
+		t.Errorf("Stats = %+v, want 1 overrun", stats)
+	}
+
+	// The samples that did not fit are dropped, the ones before kept
+	out := make([]float32, 8)
+	ab.Read(out)
+	want := []float32{1, 2, 3, 4, 5, 6, 1, 2}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("Read after overrun = %v, want %v", out, want)
+		}
+	}
+
+	ab.Reset()
+	if stats := ab.Stats(); stats != (XrunStats{}) {
+		t.Errorf("Stats after Reset = %+v, want none", stats)
+	}
+}
+
+func TestAudioBufferUnderrun(t *testing.T) {
+	ab := NewAudioBuffer(16)
+	out := make([]float32, 4)
+
+	// Reads before the first write are start-up, not underruns
+	ab.Read(out)
+	if stats := ab.Stats(); stats.Underruns != 0 {
+		t.Fatalf("underruns before the first write = %d, want 0", stats.Underruns)
+	}
+
+	ab.Write([]float32{1, 2})
+	if n := ab.Read(out); n != 2 {
+		t.Fatalf("Read = %d, want 2", n)
+	}
+	if out[2] != 0 || out[3] != 0 {
+		t.Errorf("silence fill = %v, want zeros after the samples read", out)
+	}
+	ab.Read(out)
+	if stats := ab.Stats(); stats.Underruns != 2 || stats.Overruns != 0 {
+		t.Errorf("Stats = %+v, want 2 underruns", stats)
+	}
+}
+
+func TestAudioBufferUnderrunFadeOut(t *testing.T) {
+	ab := NewAudioBuffer(16)
+	ab.SetUnderrunFill(UnderrunFadeOut, 2)
+	ab.Write([]float32{0.5, -0.5, 1, -1})
+
+	out := make([]float32, 12)
+	ab.Read(out)
+
+	// The last frame fades linearly over the four missing frames
+	want := []float32{0.5, -0.5, 1, -1, 0.75, -0.75, 0.5, -0.5, 0.25, -0.25, 0, 0}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("fade out = %v, want %v", out, want)
+		}
+	}
+
+	// A dropout that goes on stays silent
+	ab.Read(out)
+	for i, sample := range out {
+		if sample != 0 {
+			t.Fatalf("sample %d after the fade = %v, want 0", i, sample)
+		}
+	}
+}
+
+// benchmarkWriteRead moves one stereo buffer of 512 frames in and out
+func benchmarkWriteRead(b *testing.B, ab sampleRing) {
+	buf := make([]float32, 1024)
+	b.SetBytes(int64(len(buf) * 4))
+	for i := 0; i < b.N; i++ {
+		ab.Write(buf)
+		ab.Read(buf)
+	}
+}
+
+// benchmarkConcurrent streams buffers from a producer goroutine to the
+// benchmark goroutine, as an input callback feeds the output callback
+func benchmarkConcurrent(b *testing.B, ab sampleRing) {
+	const chunk = 1024
+	b.SetBytes(chunk * 4)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in := make([]float32, chunk)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if ab.Write(in) == 0 {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	out := make([]float32, chunk)
+	for i := 0; i < b.N; i++ {
+		for read := 0; read < chunk; {
+			n := ab.Read(out[:chunk-read])
+			if n == 0 {
+				runtime.Gosched()
+			}
+			read += n
+		}
+	}
+	b.StopTimer()
+	close(stop)
+	<-done
+}
+
+func BenchmarkAudioBufferWriteRead(b *testing.B) {
+	benchmarkWriteRead(b, NewAudioBuffer(1024*10))
+}
+
+func BenchmarkLockedAudioBufferWriteRead(b *testing.B) {
+	benchmarkWriteRead(b, newLockedAudioBuffer(1024*10))
+}
+
+func BenchmarkAudioBufferConcurrent(b *testing.B) {
+	benchmarkConcurrent(b, NewAudioBuffer(1024*10))
+}
+
+func BenchmarkLockedAudioBufferConcurrent(b *testing.B) {
+	benchmarkConcurrent(b, newLockedAudioBuffer(1024*10))
+}
@@ -0,0 +1,427 @@
+package audio
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxInserts is the maximum number of slots of an insert chain
+const MaxInserts = 16
+
+// InsertConfig describes one slot of an insert chain
+type InsertConfig struct {
+	Type   string             // Registered processor type, see ProcessorTypes
+	Bypass bool               // Pass audio around the processor
+	Params map[string]float64 // Parameter values of an added processor
+}
+
+// InsertSlot describes one slot of a running insert chain
+type InsertSlot struct {
+	Processor Processor
+	Bypass    bool
+	Builtin   bool // The slot holds one of the strip's built-in processors and cannot be removed
+}
+
+// insertSlot is a processor in a chain with its bypass switch. The output
+// callback crossfades between the processed and the dry signal with mix
+// (0 bypassed, 1 processing), which only it touches. A slot leaving its
+// place fades out as if bypassed and its faded channel is closed once it is
+// silent.
+//
+// A processor that adds latency keeps running while bypassed and its dry
+// signal is delayed by the same latency in comp, so bypassing it neither
+// combs two offset copies of the audio nor changes the chain's latency.
+type insertSlot struct {
+	processor Processor
+	builtin   bool
+	bypass    atomic.Bool
+	leaving   atomic.Bool
+	faded     atomic.Pointer[chan struct{}]
+	mix       float32
+
+	// Output callback state: the ring of past dry frames with the next one
+	// to write, nil for processors without latency
+	comp      []float32
+	compWrite int
+}
+
+// newInsertSlot creates a slot for p, with room to delay the dry signal by
+// as much as p can add
+func newInsertSlot(p Processor, builtin bool, channels int, sampleRate float64) *insertSlot {
+	slot := &insertSlot{processor: p, builtin: builtin}
+	latency := p.Latency()
+	if v, ok := p.(VariableLatency); ok {
+		latency = v.MaxLatency()
+	}
+	if frames := durationFrames(latency, sampleRate); frames > 0 {
+		slot.comp = make([]float32, (frames+1)*channels)
+	}
+	return slot
+}
+
+// delayDry delays dry, the slot's input, by the processor's latency
+func (s *insertSlot) delayDry(dry []float32, channels int, sampleRate float64) {
+	size := len(s.comp) / channels
+	delay := min(durationFrames(s.processor.Latency(), sampleRate), size-1)
+	for f := 0; f < len(dry)/channels; f++ {
+		write := s.compWrite * channels
+		read := ((s.compWrite - delay + size) % size) * channels
+		frame := dry[f*channels : f*channels+channels]
+		copy(s.comp[write:write+channels], frame)
+		copy(frame, s.comp[read:read+channels])
+		s.compWrite = (s.compWrite + 1) % size
+	}
+}
+
+// crossfade moves mix one ramp step per frame towards target, blending
+// buf, the processed signal, with dry
+func (s *insertSlot) crossfade(buf, dry []float32, target, step float32, channels int) {
+	for f := 0; f < len(buf)/channels; f++ {
+		if s.mix < target {
+			s.mix = min(s.mix+step, target)
+		} else {
+			s.mix = max(s.mix-step, target)
+		}
+		for i := f * channels; i < f*channels+channels; i++ {
+			buf[i] = dry[i] + s.mix*(buf[i]-dry[i])
+		}
+	}
+}
+
+// InsertChain is an ordered list of processors run one after another over
+// the audio of an input or the master bus. The order is published as a
+// whole, copied on every change, so the output callback always runs a
+// consistent chain; bypassing a slot fades it out over InputRampTime and
+// inserting one fades it in. Removing or moving a slot fades it out before
+// the order changes, and a moved slot fades back in at its new place.
+//
+// Edits are safe while audio runs. An input's chain starts out with the
+// strip's built-in processors, which can be moved and bypassed but not
+// removed.
+type InsertChain struct {
+	channels   int
+	sampleRate float64
+	rampStep   float32 // Per-frame change of a slot's mix
+
+	mu    sync.Mutex   // Serializes edits
+	slots atomic.Value // []*insertSlot
+
+	// Closed by the output callback before it next loads the slots
+	pass atomic.Pointer[chan struct{}]
+
+	dry []float32 // Output callback scratch for bypass crossfades
+}
+
+// NewInsertChain creates an empty chain for interleaved audio of channels
+// channels at sampleRate, in buffers of usually up to bufferFrames frames;
+// longer ones are processed in pieces
+func NewInsertChain(channels int, sampleRate float64, bufferFrames int) *InsertChain {
+	c := &InsertChain{
+		channels:   channels,
+		sampleRate: sampleRate,
+		rampStep:   float32(1 / max(1, InputRampTime.Seconds()*sampleRate)),
+		dry:        make([]float32, max(bufferFrames, 1)*channels),
+	}
+	c.slots.Store([]*insertSlot(nil))
+	return c
+}
+
+// newInsertChain creates a chain from cfg. Slots whose type names one of
+// the builtin processors use that processor; missing built-in processors
+// are appended in their given order, so the default chain is an empty cfg.
+func newInsertChain(cfg []InsertConfig, builtin []Processor, channels int, sampleRate float64, bufferFrames int) (*InsertChain, error) {
+	c := NewInsertChain(channels, sampleRate, bufferFrames)
+
+	var slots []*insertSlot
+	used := make(map[Processor]bool)
+	for i, insert := range cfg {
+		var slot *insertSlot
+		for _, p := range builtin {
+			if p.Type() == insert.Type && !used[p] {
+				slot = newInsertSlot(p, true, channels, sampleRate)
+				used[p] = true
+				break
+			}
+		}
+		if slot == nil {
+			p, err := NewProcessor(insert.Type, channels, sampleRate)
+			if err != nil {
+				return nil, fmt.Errorf("insert %d: %w", i+1, err)
+			}
+			// In name order, so restoring does not depend on map order:
+			// an equalizer's band count then comes after its bands
+			names := make([]string, 0, len(insert.Params))
+			for name := range insert.Params {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if err := p.SetParameter(name, insert.Params[name]); err != nil {
+					return nil, fmt.Errorf("insert %d (%s): %w", i+1, insert.Type, err)
+				}
+			}
+			slot = newInsertSlot(p, false, channels, sampleRate)
+		}
+		slot.bypass.Store(insert.Bypass)
+		slots = append(slots, slot)
+	}
+	for _, p := range builtin {
+		if !used[p] {
+			slots = append(slots, newInsertSlot(p, true, channels, sampleRate))
+		}
+	}
+	if len(slots) > MaxInserts {
+		return nil, fmt.Errorf("too many inserts (max %d)", MaxInserts)
+	}
+
+	for _, slot := range slots {
+		if !slot.bypass.Load() {
+			slot.mix = 1
+		}
+	}
+	c.slots.Store(slots)
+	return c, nil
+}
+
+// load returns the current slots
+func (c *InsertChain) load() []*insertSlot {
+	return c.slots.Load().([]*insertSlot)
+}
+
+// Len returns the number of slots
+func (c *InsertChain) Len() int {
+	return len(c.load())
+}
+
+// Slots describes every slot in order
+func (c *InsertChain) Slots() []InsertSlot {
+	slots := c.load()
+	out := make([]InsertSlot, len(slots))
+	for i, slot := range slots {
+		out[i] = InsertSlot{Processor: slot.processor, Bypass: slot.bypass.Load(), Builtin: slot.builtin}
+	}
+	return out
+}
+
+// Processor returns the processor at position, or nil if out of range
+func (c *InsertChain) Processor(position int) Processor {
+	slots := c.load()
+	if position < 0 || position >= len(slots) {
+		return nil
+	}
+	return slots[position].processor
+}
+
+// Insert adds p at position, from 0 to Len, fading it in; p must process
+// audio of the chain's channel count and sample rate
+func (c *InsertChain) Insert(position int, p Processor) error {
+	if p.Channels() != c.channels {
+		return fmt.Errorf("%s processes %d channels, not %d", p.Type(), p.Channels(), c.channels)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slots := c.load()
+	if position < 0 || position > len(slots) {
+		return fmt.Errorf("invalid insert position %d", position+1)
+	}
+	if len(slots) >= MaxInserts {
+		return fmt.Errorf("too many inserts (max %d)", MaxInserts)
+	}
+	for _, slot := range slots {
+		if slot.processor == p {
+			return fmt.Errorf("%s is already in the chain", p.Type())
+		}
+	}
+
+	updated := make([]*insertSlot, 0, len(slots)+1)
+	updated = append(updated, slots[:position]...)
+	updated = append(updated, newInsertSlot(p, false, c.channels, c.sampleRate))
+	updated = append(updated, slots[position:]...)
+	c.slots.Store(updated)
+	return nil
+}
+
+// Remove fades the processor at position out and takes it out of the chain
+func (c *InsertChain) Remove(position int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slots := c.load()
+	if position < 0 || position >= len(slots) {
+		return fmt.Errorf("invalid insert position %d", position+1)
+	}
+	if slots[position].builtin {
+		return fmt.Errorf("%s is built in; bypass it instead", slots[position].processor.Type())
+	}
+	c.fadeOut(slots[position])
+
+	updated := make([]*insertSlot, 0, len(slots)-1)
+	updated = append(updated, slots[:position]...)
+	updated = append(updated, slots[position+1:]...)
+	c.slots.Store(updated)
+	return nil
+}
+
+// Move moves the slot at from to position to, shifting the ones between.
+// The slot fades out at its old place and back in at the new one.
+func (c *InsertChain) Move(from, to int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slots := c.load()
+	if from < 0 || from >= len(slots) || to < 0 || to >= len(slots) {
+		return fmt.Errorf("invalid move from %d to %d", from+1, to+1)
+	}
+	if from == to {
+		return nil
+	}
+	c.fadeOut(slots[from])
+
+	updated := make([]*insertSlot, 0, len(slots))
+	updated = append(updated, slots[:from]...)
+	updated = append(updated, slots[from+1:]...)
+	updated = append(updated[:to], append([]*insertSlot{slots[from]}, updated[to:]...)...)
+	c.slots.Store(updated)
+
+	// Fading in before the output callback runs the new order would fade
+	// the slot in at its old place
+	pass := make(chan struct{})
+	c.pass.Store(&pass)
+	c.wait(pass)
+	slots[from].leaving.Store(false)
+	return nil
+}
+
+// fadeOut has the output callback fade slot out and waits until it is
+// silent
+func (c *InsertChain) fadeOut(slot *insertSlot) {
+	faded := make(chan struct{})
+	slot.faded.Store(&faded)
+	slot.leaving.Store(true)
+	c.wait(faded)
+}
+
+// wait waits for the output callback to close done. The chain does not
+// know whether audio runs, so it waits no longer than a ramp would take
+// over a few late buffers.
+func (c *InsertChain) wait(done chan struct{}) {
+	buffer := framesDuration(len(c.dry)/c.channels, c.sampleRate)
+	select {
+	case <-done:
+	case <-time.After(InputRampTime + 4*buffer):
+	}
+}
+
+// SetBypass switches the bypass of the slot at position
+func (c *InsertChain) SetBypass(position int, bypass bool) error {
+	slots := c.load()
+	if position < 0 || position >= len(slots) {
+		return fmt.Errorf("invalid insert position %d", position+1)
+	}
+	slots[position].bypass.Store(bypass)
+	return nil
+}
+
+// Latency returns how far the chain delays audio. Bypassed processors that
+// add latency keep it, so it only changes with their settings.
+func (c *InsertChain) Latency() time.Duration {
+	var latency time.Duration
+	for _, slot := range c.load() {
+		if !slot.bypass.Load() || slot.comp != nil {
+			latency += slot.processor.Latency()
+		}
+	}
+	return latency
+}
+
+// Config returns the chain's order and bypass switches, with the
+// parameters of every processor that is not built in
+func (c *InsertChain) Config() []InsertConfig {
+	slots := c.load()
+	cfg := make([]InsertConfig, len(slots))
+	for i, slot := range slots {
+		cfg[i] = InsertConfig{Type: slot.processor.Type(), Bypass: slot.bypass.Load()}
+		if slot.builtin {
+			continue
+		}
+		cfg[i].Params = make(map[string]float64)
+		for _, param := range slot.processor.Parameters() {
+			if value, err := slot.processor.Parameter(param.Name); err == nil {
+				cfg[i].Params[param.Name] = value
+			}
+		}
+	}
+	return cfg
+}
+
+// Reset resets every processor; call it only while no audio is processed
+func (c *InsertChain) Reset() {
+	for _, slot := range c.load() {
+		slot.processor.Reset()
+		clear(slot.comp)
+		slot.compWrite = 0
+		slot.mix = 0
+		if !slot.bypass.Load() {
+			slot.mix = 1
+		}
+	}
+}
+
+// Process runs buf, interleaved audio of the chain's channel count,
+// through every slot in order
+func (c *InsertChain) Process(buf []float32) {
+	for len(buf) > len(c.dry) {
+		c.Process(buf[:len(c.dry)])
+		buf = buf[len(c.dry):]
+	}
+
+	if pass := c.pass.Swap(nil); pass != nil {
+		close(*pass)
+	}
+	for _, slot := range c.load() {
+		leaving := slot.leaving.Load()
+		target := float32(1)
+		if leaving || slot.bypass.Load() {
+			target = 0
+		}
+		c.processSlot(slot, buf, target)
+
+		if leaving && slot.mix == 0 {
+			if faded := slot.faded.Swap(nil); faded != nil {
+				close(*faded)
+			}
+		}
+	}
+}
+
+// processSlot runs buf through slot, crossfading towards target
+func (c *InsertChain) processSlot(slot *insertSlot, buf []float32, target float32) {
+	dry := c.dry[:len(buf)]
+
+	if slot.comp != nil {
+		copy(dry, buf)
+		slot.delayDry(dry, c.channels, c.sampleRate)
+		slot.processor.Process(buf)
+		if slot.mix != target {
+			slot.crossfade(buf, dry, target, c.rampStep, c.channels)
+		} else if target == 0 {
+			copy(buf, dry)
+		}
+		return
+	}
+
+	if slot.mix == target {
+		if target == 1 {
+			slot.processor.Process(buf)
+		}
+		return
+	}
+	copy(dry, buf)
+	slot.processor.Process(buf)
+	slot.crossfade(buf, dry, target, c.rampStep, c.channels)
+}
@@ -0,0 +1,382 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testSampleRate = 48000
+
+// testProcessor scales and offsets mono audio after delaying it by a
+// number of frames
+type testProcessor struct {
+	scale, offset float32
+	ring          []float32
+	pos           int
+}
+
+func newTestProcessor(scale, offset float32, delay int) *testProcessor {
+	return &testProcessor{scale: scale, offset: offset, ring: make([]float32, delay)}
+}
+
+func (p *testProcessor) Type() string { return "test" }
+
+func (p *testProcessor) Channels() int { return 1 }
+
+func (p *testProcessor) Process(buf []float32) {
+	for i, x := range buf {
+		if len(p.ring) > 0 {
+			x, p.ring[p.pos] = p.ring[p.pos], x
+			p.pos = (p.pos + 1) % len(p.ring)
+		}
+		buf[i] = x*p.scale + p.offset
+	}
+}
+
+func (p *testProcessor) Latency() time.Duration {
+	return framesDuration(len(p.ring), testSampleRate)
+}
+
+func (p *testProcessor) Reset() {
+	clear(p.ring)
+	p.pos = 0
+}
+
+func (p *testProcessor) Parameters() []Parameter { return nil }
+
+func (p *testProcessor) Parameter(name string) (float64, error) {
+	return 0, fmt.Errorf("unknown parameter %q", name)
+}
+
+func (p *testProcessor) SetParameter(name string, value float64) error {
+	return fmt.Errorf("unknown parameter %q", name)
+}
+
+// digit returns a processor that appends d to the decimal number it is
+// given, so the output of a chain of them spells out their order
+func digit(d float32) *testProcessor {
+	return newTestProcessor(10, d, 0)
+}
+
+// runChain processes silence through a mono chain until any ramp is over
+// and returns the last sample
+func runChain(c *InsertChain) float32 {
+	buf := make([]float32, testSampleRate/10)
+	c.Process(buf)
+	return buf[len(buf)-1]
+}
+
+func TestInsertChainOrder(t *testing.T) {
+	c := NewInsertChain(1, testSampleRate, 512)
+	for i, d := range []float32{1, 2, 3} {
+		if err := c.Insert(i, digit(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Insert(0, digit(4)); err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 4123 {
+		t.Fatalf("after inserts: %v, want 4123", got)
+	}
+
+	if err := c.Move(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 1234 {
+		t.Fatalf("after moving the first to the end: %v, want 1234", got)
+	}
+	if err := c.Move(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 3124 {
+		t.Fatalf("after moving the third to the front: %v, want 3124", got)
+	}
+
+	if err := c.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 324 {
+		t.Fatalf("after removing the second: %v, want 324", got)
+	}
+	if c.Len() != 3 {
+		t.Errorf("Len = %d, want 3", c.Len())
+	}
+
+	for _, err := range []error{
+		c.Insert(5, digit(5)),
+		c.Insert(0, c.Processor(0)),
+		c.Remove(3),
+		c.Move(0, 3),
+		c.SetBypass(-1, true),
+		NewInsertChain(2, testSampleRate, 512).Insert(0, digit(5)),
+	} {
+		if err == nil {
+			t.Error("an invalid edit succeeded")
+		}
+	}
+}
+
+func TestInsertChainBuiltin(t *testing.T) {
+	builtin := []Processor{digit(1), digit(2)}
+	c, err := newInsertChain([]InsertConfig{{Type: "test"}}, builtin, 1, testSampleRate, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 12 {
+		t.Fatalf("built-in chain: %v, want 12", got)
+	}
+	if err := c.Remove(0); err == nil {
+		t.Error("removed a built-in processor")
+	}
+	if err := c.Move(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := runChain(c); got != 21 {
+		t.Errorf("after moving: %v, want 21", got)
+	}
+}
+
+// rampFrames is how many frames a bypass crossfade lasts
+var rampFrames = int(math.Ceil(InputRampTime.Seconds() * testSampleRate))
+
+// checkRamp checks that out moves from one level to another in steps of
+// about one ramp step per frame
+func checkRamp(t *testing.T, out []float32, from, to float32) {
+	t.Helper()
+	if out[0] == from || math.Abs(float64(out[0]-from)) > 0.01 {
+		t.Errorf("ramp starts at %v, want a step from %v", out[0], from)
+	}
+	if jump := maxJump(out); jump > 1.01/float32(rampFrames) {
+		t.Errorf("largest step %v, want at most one ramp step", jump)
+	}
+	for i := rampFrames; i < len(out); i++ {
+		if out[i] != to {
+			t.Fatalf("frame %d after the ramp is %v, want %v", i, out[i], to)
+		}
+	}
+}
+
+func TestInsertChainBypassRamps(t *testing.T) {
+	c := NewInsertChain(1, testSampleRate, 512)
+	mute := newTestProcessor(0, 0, 0)
+	ones := func() []float32 {
+		buf := make([]float32, 2*rampFrames)
+		for i := range buf {
+			buf[i] = 1
+		}
+		return buf
+	}
+
+	// A processor inserted live fades in
+	if err := c.Insert(0, mute); err != nil {
+		t.Fatal(err)
+	}
+	buf := ones()
+	c.Process(buf)
+	checkRamp(t, buf, 1, 0)
+
+	// Bypassing fades it out again, and back in
+	c.SetBypass(0, true)
+	buf = ones()
+	c.Process(buf)
+	checkRamp(t, buf, 0, 1)
+	if !c.Slots()[0].Bypass {
+		t.Error("Slots does not report the bypass")
+	}
+
+	c.SetBypass(0, false)
+	buf = ones()
+	c.Process(buf)
+	checkRamp(t, buf, 1, 0)
+}
+
+// recordEdit processes ones through c in short buffers on another
+// goroutine, as the output callback would, while edit changes the chain,
+// and returns the output until the chain has settled again
+func recordEdit(t *testing.T, c *InsertChain, edit func() error) []float32 {
+	t.Helper()
+	ones := func(n int) []float32 {
+		buf := make([]float32, n)
+		for i := range buf {
+			buf[i] = 1
+		}
+		return buf
+	}
+
+	stop := make(chan struct{})
+	done := make(chan []float32)
+	go func() {
+		var out []float32
+		for {
+			select {
+			case <-stop:
+				done <- out
+				return
+			default:
+			}
+			buf := ones(64)
+			c.Process(buf)
+			out = append(out, buf...)
+		}
+	}()
+	err := edit()
+	close(stop)
+	out := <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := ones(2 * rampFrames)
+	c.Process(buf)
+	return append(out, buf...)
+}
+
+func TestInsertChainEditRamps(t *testing.T) {
+	double := newTestProcessor(2, 0, 0)
+	inc := newTestProcessor(1, 1, 0)
+	c := NewInsertChain(1, testSampleRate, 64)
+	c.Insert(0, double)
+	c.Insert(1, inc)
+	runChain(c)
+
+	// Moving the doubling after the increment fades it out, from 3 to 2,
+	// and back in after the increment, from 2 to 4
+	out := recordEdit(t, c, func() error { return c.Move(0, 1) })
+	if jump := maxJump(out); jump > 2.02/float32(rampFrames) {
+		t.Errorf("largest step while moving %v, want at most two ramp steps", jump)
+	}
+	lowest := out[0]
+	for _, sample := range out {
+		lowest = min(lowest, sample)
+	}
+	if lowest != 2 || out[len(out)-1] != 4 {
+		t.Errorf("move went down to %v and ended at %v, want 2 and 4", lowest, out[len(out)-1])
+	}
+
+	// Removing it fades it out before it goes
+	out = recordEdit(t, c, func() error { return c.Remove(1) })
+	if jump := maxJump(out); jump > 2.02/float32(rampFrames) {
+		t.Errorf("largest step while removing %v, want at most two ramp steps", jump)
+	}
+	if out[len(out)-1] != 2 || c.Len() != 1 {
+		t.Errorf("after removing: %v with %d slots, want 2 with 1", out[len(out)-1], c.Len())
+	}
+}
+
+func TestInsertChainBypassKeepsLatency(t *testing.T) {
+	const delay = 300
+	c := NewInsertChain(1, testSampleRate, 256)
+	if err := c.Insert(0, newTestProcessor(1, 0, delay)); err != nil {
+		t.Fatal(err)
+	}
+	latency := c.Latency()
+	if want := framesDuration(delay, testSampleRate); latency != want {
+		t.Fatalf("Latency = %v, want %v", latency, want)
+	}
+
+	// The output stays the input delayed while the slot is switched back
+	// and forth, in buffers longer than the chain's scratch
+	n := 0
+	for _, bypass := range []bool{false, true, false, true} {
+		c.SetBypass(0, bypass)
+		if c.Latency() != latency {
+			t.Errorf("Latency with bypass %v = %v, want %v", bypass, c.Latency(), latency)
+		}
+		buf := make([]float32, 1000)
+		for i := range buf {
+			buf[i] = float32(n + i)
+		}
+		c.Process(buf)
+		for i, sample := range buf {
+			want := float32(max(n+i-delay, 0))
+			if sample != want {
+				t.Fatalf("bypass %v: sample %d = %v, want %v", bypass, n+i, sample, want)
+			}
+		}
+		n += len(buf)
+	}
+}
+
+func TestInsertChainLatency(t *testing.T) {
+	c := NewInsertChain(1, testSampleRate, 512)
+	c.Insert(0, newTestProcessor(1, 0, 48))
+	c.Insert(1, newTestProcessor(1, 0, 96))
+	c.Insert(2, digit(1))
+	if got, want := c.Latency(), framesDuration(144, testSampleRate); got != want {
+		t.Errorf("Latency = %v, want %v", got, want)
+	}
+	c.Remove(0)
+	if got, want := c.Latency(), framesDuration(96, testSampleRate); got != want {
+		t.Errorf("Latency after removing = %v, want %v", got, want)
+	}
+
+	// The pitch shifter's latency follows its quality, bypassed or not
+	pitch, err := NewProcessor("pitch", 1, testSampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Insert(0, pitch)
+	c.SetBypass(0, true)
+	before := c.Latency()
+	pitch.SetParameter("quality", float64(PitchQualityHigh))
+	if got, want := c.Latency()-before, framesDuration(2048, testSampleRate)-framesDuration(1024, testSampleRate); got != want {
+		t.Errorf("Latency grew by %v at high quality, want %v", got, want)
+	}
+}
+
+func TestInsertChainConfig(t *testing.T) {
+	cfg := []InsertConfig{
+		{Type: "echo", Params: map[string]float64{"time": 120, "feedback": 0.3}},
+		{Type: "eq", Bypass: true},
+		{Type: "reverb", Params: map[string]float64{"size": 0.8}},
+	}
+	c, err := newInsertChain(cfg, nil, 2, testSampleRate, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := c.Config()
+	if len(saved) != len(cfg) {
+		t.Fatalf("Config has %d slots, want %d", len(saved), len(cfg))
+	}
+	for i := range cfg {
+		if saved[i].Type != cfg[i].Type || saved[i].Bypass != cfg[i].Bypass {
+			t.Errorf("slot %d: %+v, want %+v", i, saved[i], cfg[i])
+		}
+		for name, value := range cfg[i].Params {
+			if math.Abs(saved[i].Params[name]-value) > 1e-6 {
+				t.Errorf("slot %d: %s = %v, want %v", i, name, saved[i].Params[name], value)
+			}
+		}
+	}
+
+	restored, err := newInsertChain(saved, nil, 2, testSampleRate, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := restored.Config(); !reflect.DeepEqual(again, saved) {
+		t.Errorf("round trip: %+v, want %+v", again, saved)
+	}
+
+	// An equalizer keeps its band count whatever order its bands come in
+	eq := []InsertConfig{{Type: "eq", Params: map[string]float64{"bands": 2, "band2.gain": 4, "band5.gain": -3}}}
+	for i := 0; i < 20; i++ {
+		c, err := newInsertChain(eq, nil, 2, testSampleRate, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bands, _ := c.Processor(0).Parameter("bands"); bands != 2 {
+			t.Fatalf("restored equalizer has %v bands, want 2", bands)
+		}
+		if gain, _ := c.Processor(0).Parameter("band2.gain"); gain != 4 {
+			t.Fatalf("restored band 2 gain = %v, want 4", gain)
+		}
+	}
+
+	if _, err := newInsertChain([]InsertConfig{{Type: "nonexistent"}}, nil, 2, testSampleRate, 512); err == nil {
+		t.Error("an unknown processor type was accepted")
+	}
+}
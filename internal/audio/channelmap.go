@@ -0,0 +1,183 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ChannelMap routes interleaved audio from one channel layout to another.
+// Entry [d][s] is the gain with which source channel s is summed into
+// destination channel d, so a map has one row per destination channel and
+// one column per source channel.
+type ChannelMap [][]float32
+
+// NewChannelMap creates a silent map from sources to destinations channels
+func NewChannelMap(sources, destinations int) ChannelMap {
+	cm := make(ChannelMap, destinations)
+	for d := range cm {
+		cm[d] = make([]float32, sources)
+	}
+	return cm
+}
+
+// DefaultChannelMap returns the conventional conversion between two channel
+// counts: mono is copied to every destination, stereo is averaged down to
+// mono, 5.1 (L R C LFE Ls Rs) is folded down to stereo with the centre and
+// surrounds at -3 dB, and anything else maps channel n to channel n.
+func DefaultChannelMap(sources, destinations int) ChannelMap {
+	cm := NewChannelMap(sources, destinations)
+	minus3dB := float32(math.Sqrt2 / 2)
+
+	switch {
+	case sources == destinations:
+		for c := range cm {
+			cm[c][c] = 1
+		}
+	case sources == 1:
+		for d := range cm {
+			cm[d][0] = 1
+		}
+	case sources == 2 && destinations == 1:
+		cm[0][0], cm[0][1] = 0.5, 0.5
+	case sources == 6 && destinations == 2:
+		cm[0][0], cm[0][2], cm[0][4] = 1, minus3dB, minus3dB
+		cm[1][1], cm[1][2], cm[1][5] = 1, minus3dB, minus3dB
+	default:
+		for c := 0; c < min(sources, destinations); c++ {
+			cm[c][c] = 1
+		}
+	}
+	return cm
+}
+
+// CaptureChannelMap maps the given device channels (0-based) of a capture
+// stream to mixerChannels, converting their count as DefaultChannelMap does.
+// The stream must be opened with Sources() channels.
+func CaptureChannelMap(deviceChannels []int, mixerChannels int) ChannelMap {
+	sources := 0
+	for _, ch := range deviceChannels {
+		sources = max(sources, ch+1)
+	}
+
+	selected := DefaultChannelMap(len(deviceChannels), mixerChannels)
+	cm := NewChannelMap(sources, mixerChannels)
+	for d, row := range selected {
+		for s, gain := range row {
+			cm[d][deviceChannels[s]] += gain
+		}
+	}
+	return cm
+}
+
+// PlaybackChannelMap maps mixerChannels to the given device channels
+// (0-based) of a playback stream, converting their count as
+// DefaultChannelMap does. The stream must be opened with Destinations()
+// channels; device channels not listed stay silent.
+func PlaybackChannelMap(mixerChannels int, deviceChannels []int) ChannelMap {
+	destinations := 0
+	for _, ch := range deviceChannels {
+		destinations = max(destinations, ch+1)
+	}
+
+	selected := DefaultChannelMap(mixerChannels, len(deviceChannels))
+	cm := NewChannelMap(mixerChannels, destinations)
+	for d, row := range selected {
+		for s, gain := range row {
+			cm[deviceChannels[d]][s] += gain
+		}
+	}
+	return cm
+}
+
+// Sources returns the number of source channels
+func (cm ChannelMap) Sources() int {
+	if len(cm) == 0 {
+		return 0
+	}
+	return len(cm[0])
+}
+
+// Destinations returns the number of destination channels
+func (cm ChannelMap) Destinations() int {
+	return len(cm)
+}
+
+// Validate checks that the map is rectangular and fits the given channel counts
+func (cm ChannelMap) Validate(sources, destinations int) error {
+	if cm.Destinations() != destinations {
+		return fmt.Errorf("channel map has %d destination channels, need %d", cm.Destinations(), destinations)
+	}
+	if cm.Sources() < 1 || cm.Sources() > sources {
+		return fmt.Errorf("channel map needs %d source channels, have %d", cm.Sources(), sources)
+	}
+	for _, row := range cm {
+		if len(row) != cm.Sources() {
+			return fmt.Errorf("channel map rows differ in length")
+		}
+	}
+	return nil
+}
+
+// IsIdentity returns whether the map passes every channel through unchanged
+func (cm ChannelMap) IsIdentity() bool {
+	if cm.Sources() != cm.Destinations() {
+		return false
+	}
+	for d, row := range cm {
+		for s, gain := range row {
+			if (s == d && gain != 1) || (s != d && gain != 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Apply converts the interleaved frames of src into dst. dst must hold
+// len(src)/Sources() frames of Destinations() channels.
+func (cm ChannelMap) Apply(dst, src []float32) {
+	sources := cm.Sources()
+	destinations := cm.Destinations()
+	frames := len(src) / sources
+
+	for f := 0; f < frames; f++ {
+		in := src[f*sources : f*sources+sources]
+		out := dst[f*destinations : f*destinations+destinations]
+		for d, row := range cm {
+			var sum float32
+			for s, gain := range row {
+				if gain != 0 {
+					sum += in[s] * gain
+				}
+			}
+			out[d] = sum
+		}
+	}
+}
+
+// String formats the map as 1-based routes per destination channel, e.g.
+// "1<-1+0.71*3 2<-2+0.71*3"; destination channels without a source are left out
+func (cm ChannelMap) String() string {
+	var routes []string
+	for d, row := range cm {
+		var sources []string
+		for s, gain := range row {
+			switch gain {
+			case 0:
+			case 1:
+				sources = append(sources, fmt.Sprintf("%d", s+1))
+			default:
+				sources = append(sources, fmt.Sprintf("%.2f*%d", gain, s+1))
+			}
+		}
+		if len(sources) > 0 {
+			routes = append(routes, fmt.Sprintf("%d<-%s", d+1, strings.Join(sources, "+")))
+		}
+	}
+
+	if len(routes) == 0 {
+		return "silent"
+	}
+	return strings.Join(routes, " ")
+}
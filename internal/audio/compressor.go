@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// CompressorConfig configures the feed-forward compressor of an input
+type CompressorConfig struct {
+	Enabled    bool
+	Threshold  float32       // Level in dBFS above which the gain is reduced
+	Ratio      float32       // Input to output level ratio above the threshold, 1.0 or more
+	Knee       float32       // Width in dB of the soft knee around the threshold, 0 for a hard knee
+	Attack     time.Duration // Time to reach the gain reduction of a louder signal
+	Release    time.Duration // Time to recover when the signal gets quieter
+	MakeupGain float32       // Gain in dB applied after compression
+}
+
+// DefaultCompressorConfig returns moderate settings for leveling speech
+func DefaultCompressorConfig() CompressorConfig {
+	return CompressorConfig{
+		Threshold:  -18,
+		Ratio:      4,
+		Knee:       6,
+		Attack:     10 * time.Millisecond,
+		Release:    150 * time.Millisecond,
+		MakeupGain: 0,
+	}
+}
+
+// compressor is a feed-forward compressor with a linked peak detector: all
+// channels share the gain reduction computed from the loudest one, so the
+// stereo image stays put. The gain reduction is computed in dB through a
+// soft-knee gain curve and smoothed with separate attack and release times.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type compressor struct {
+	config     atomic.Value // CompressorConfig
+	sampleRate float64
+	channels   int
+
+	// Output callback state: the settings in use with their derived
+	// coefficients, and the smoothed gain reduction in dB
+	active        CompressorConfig
+	attackCoeff   float32
+	releaseCoeff  float32
+	makeup        float32
+	envelope      float32
+	gainReduction atomic.Value // float32, dB
+}
+
+// newCompressor creates a compressor for interleaved audio at sampleRate
+func newCompressor(cfg CompressorConfig, channels int, sampleRate float64) *compressor {
+	c := &compressor{sampleRate: sampleRate, channels: channels}
+	c.config.Store(cfg)
+	c.gainReduction.Store(float32(0))
+	c.configure(cfg)
+	return c
+}
+
+// set publishes new settings, applied from the next buffer on
+func (c *compressor) set(cfg CompressorConfig) {
+	c.config.Store(cfg)
+}
+
+// get returns the current settings
+func (c *compressor) get() CompressorConfig {
+	return c.config.Load().(CompressorConfig)
+}
+
+// configure derives the coefficients for cfg
+func (c *compressor) configure(cfg CompressorConfig) {
+	c.active = cfg
+	c.attackCoeff = smoothingCoeff(cfg.Attack, c.sampleRate)
+	c.releaseCoeff = smoothingCoeff(cfg.Release, c.sampleRate)
+	c.makeup = dbToGain(cfg.MakeupGain)
+}
+
+// reset releases all gain reduction
+func (c *compressor) reset() {
+	c.envelope = 0
+	c.gainReduction.Store(float32(0))
+}
+
+// process compresses buf in place
+func (c *compressor) process(buf []float32) {
+	channels := c.channels
+	if cfg := c.get(); cfg != c.active {
+		c.configure(cfg)
+	}
+	if !c.active.Enabled {
+		if c.envelope != 0 {
+			c.reset()
+		}
+		return
+	}
+
+	most := float32(0)
+	for f := 0; f < len(buf)/channels; f++ {
+		frame := buf[f*channels : f*channels+channels]
+
+		peak := float32(0)
+		for _, sample := range frame {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+
+		// Follow the target gain reduction with attack or release
+		target := c.gainCurve(gainToDB(peak))
+		if target > c.envelope {
+			c.envelope += (target - c.envelope) * c.attackCoeff
+		} else {
+			c.envelope += (target - c.envelope) * c.releaseCoeff
+		}
+		most = max(most, c.envelope)
+
+		gain := dbToGain(-c.envelope) * c.makeup
+		for i := range frame {
+			frame[i] *= gain
+		}
+	}
+	c.gainReduction.Store(most)
+}
+
+// gainCurve returns the gain reduction in dB for a level in dBFS
+func (c *compressor) gainCurve(level float32) float32 {
+	cfg := &c.active
+	ratio := max(cfg.Ratio, 1)
+	over := level - cfg.Threshold
+
+	switch {
+	case 2*over <= -cfg.Knee:
+		return 0
+	case 2*over < cfg.Knee:
+		// Quadratic transition through the knee
+		x := over + cfg.Knee/2
+		return (1 - 1/ratio) * x * x / (2 * cfg.Knee)
+	default:
+		return over * (1 - 1/ratio)
+	}
+}
+
+// smoothingCoeff returns the per-sample coefficient of a one-pole filter
+// with time constant t, 1 for an immediate response
+func smoothingCoeff(t time.Duration, sampleRate float64) float32 {
+	frames := t.Seconds() * sampleRate
+	if frames <= 1 {
+		return 1
+	}
+	return float32(1 - math.Exp(-1/frames))
+}
+
+// gainToDB converts a linear gain or level to dB, with a floor at -120 dB
+func gainToDB(gain float32) float32 {
+	if gain < 1e-6 {
+		return -120
+	}
+	return float32(20 * math.Log10(float64(gain)))
+}
+
+// dbToGain converts dB to a linear gain
+func dbToGain(db float32) float32 {
+	return float32(math.Pow(10, float64(db)/20))
+}
+
+// compressorParams are the compressor's processor parameters
+var compressorParams = params[CompressorConfig]{
+	boolParam("enabled", true, func(c *CompressorConfig) *bool { return &c.Enabled }),
+	floatParam("threshold", -60, 0, -18, "dB", func(c *CompressorConfig) *float32 { return &c.Threshold }),
+	floatParam("ratio", 1, 20, 4, ":1", func(c *CompressorConfig) *float32 { return &c.Ratio }),
+	floatParam("knee", 0, 24, 6, "dB", func(c *CompressorConfig) *float32 { return &c.Knee }),
+	msParam("attack", 0.1, 500, 10, func(c *CompressorConfig) *time.Duration { return &c.Attack }),
+	msParam("release", 1, 5000, 150, func(c *CompressorConfig) *time.Duration { return &c.Release }),
+	floatParam("makeup", 0, 24, 0, "dB", func(c *CompressorConfig) *float32 { return &c.MakeupGain }),
+}
+
+// Type implements Processor
+func (c *compressor) Type() string { return "compressor" }
+
+// Channels implements Processor
+func (c *compressor) Channels() int { return c.channels }
+
+// Process implements Processor
+func (c *compressor) Process(buf []float32) { c.process(buf) }
+
+// Latency implements Processor; the compressor adds none
+func (c *compressor) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (c *compressor) Reset() { c.reset() }
+
+// Parameters implements Processor
+func (c *compressor) Parameters() []Parameter { return compressorParams.parameters() }
+
+// Parameter implements Processor
+func (c *compressor) Parameter(name string) (float64, error) {
+	return compressorParams.value(c.get(), name)
+}
+
+// SetParameter implements Processor
+func (c *compressor) SetParameter(name string, value float64) error {
+	cfg, err := compressorParams.update(c.get(), name, value)
+	if err == nil {
+		c.set(cfg)
+	}
+	return err
+}
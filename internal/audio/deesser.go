@@ -0,0 +1,209 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Detector timing of the de-esser, fast enough to catch single sibilants
+const (
+	deEsserAttack  = time.Millisecond
+	deEsserRelease = 60 * time.Millisecond
+)
+
+// DeEsserConfig configures the split-band de-esser of an input
+type DeEsserConfig struct {
+	Enabled   bool
+	Low       float64 // Lower edge of the sibilance band in Hz
+	High      float64 // Upper edge of the sibilance band in Hz, kept below half the sample rate
+	Threshold float32 // Band level in dBFS above which the band is turned down
+	Reduction float32 // Most the band is turned down, in dB
+	Listen    bool    // Output the sibilance band alone, for tuning
+}
+
+// DefaultDeEsserConfig returns settings for the sibilance of most voices
+func DefaultDeEsserConfig() DeEsserConfig {
+	return DeEsserConfig{
+		Low:       4000,
+		High:      10000,
+		Threshold: -30,
+		Reduction: 8,
+	}
+}
+
+// deEsser is a split-band de-esser. A band-pass filter, the input minus a
+// notch filter centred between the band edges, splits off the sibilance
+// band, whose peak level, linked across the channels, drives a gain
+// reduction down to the threshold of at most the reduction amount. Only the
+// band is turned down: the output is the input minus the removed part of
+// the band, which amounts to a peaking cut by the reduction, so audio
+// outside the band passes unchanged.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type deEsser struct {
+	config     atomic.Value // DeEsserConfig
+	sampleRate float64
+	channels   int
+
+	// Output callback state: the settings in use with the band's notch
+	// filter, its memory for every channel and the smoothed gain reduction
+	// in dB
+	active        DeEsserConfig
+	notch         biquadCoeffs
+	notchState    []biquadState
+	attackCoeff   float32
+	releaseCoeff  float32
+	envelope      float32
+	band          []float32
+	gainReduction atomic.Value // float32, dB
+}
+
+// newDeEsser creates a de-esser for interleaved audio at sampleRate
+func newDeEsser(cfg DeEsserConfig, channels int, sampleRate float64) *deEsser {
+	d := &deEsser{
+		sampleRate:   sampleRate,
+		channels:     channels,
+		notchState:   make([]biquadState, channels),
+		attackCoeff:  smoothingCoeff(deEsserAttack, sampleRate),
+		releaseCoeff: smoothingCoeff(deEsserRelease, sampleRate),
+	}
+	d.config.Store(cfg)
+	d.configure(cfg)
+	d.reset()
+	return d
+}
+
+// set publishes new settings, applied from the next buffer on
+func (d *deEsser) set(cfg DeEsserConfig) {
+	d.config.Store(cfg)
+}
+
+// get returns the current settings
+func (d *deEsser) get() DeEsserConfig {
+	return d.config.Load().(DeEsserConfig)
+}
+
+// configure derives the band filter for cfg, keeping the band within the
+// sample rate; the notch is centred on the geometric mean of the edges
+// with their distance as its bandwidth
+func (d *deEsser) configure(cfg DeEsserConfig) {
+	d.active = cfg
+	high := min(cfg.High, 0.45*d.sampleRate)
+	low := min(max(cfg.Low, 20), 0.9*high)
+	centre := math.Sqrt(low * high)
+	d.notch = EQBand{Type: FilterNotch, Frequency: centre, Q: float32(centre / (high - low))}.coefficients(d.sampleRate)
+}
+
+// reset clears the filter memory and releases all gain reduction
+func (d *deEsser) reset() {
+	clear(d.notchState)
+	d.envelope = 0
+	d.gainReduction.Store(float32(0))
+}
+
+// process de-esses buf in place
+func (d *deEsser) process(buf []float32) {
+	ch := d.channels
+	if cfg := d.get(); cfg != d.active {
+		d.configure(cfg)
+	}
+	if !d.active.Enabled {
+		if d.envelope != 0 {
+			d.reset()
+		}
+		return
+	}
+
+	if len(buf) > len(d.band) {
+		d.band = make([]float32, len(buf))
+	}
+	band := d.band[:len(buf)]
+	for c := 0; c < ch; c++ {
+		for i := c; i < len(buf); i += ch {
+			x := float64(buf[i])
+			band[i] = float32(x - d.notch.process(&d.notchState[c], x))
+		}
+	}
+
+	most := float32(0)
+	for f := 0; f < len(buf)/ch; f++ {
+		frame := buf[f*ch : f*ch+ch]
+		sibilance := band[f*ch : f*ch+ch]
+
+		peak := float32(0)
+		for _, sample := range sibilance {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+
+		// Follow the reduction down to the threshold with attack or release
+		target := min(max(gainToDB(peak)-d.active.Threshold, 0), max(d.active.Reduction, 0))
+		if target > d.envelope {
+			d.envelope += (target - d.envelope) * d.attackCoeff
+		} else {
+			d.envelope += (target - d.envelope) * d.releaseCoeff
+		}
+		most = max(most, d.envelope)
+
+		if d.active.Listen {
+			copy(frame, sibilance)
+			continue
+		}
+		removed := 1 - dbToGain(-d.envelope)
+		for i := range frame {
+			frame[i] -= removed * sibilance[i]
+		}
+	}
+	d.gainReduction.Store(most)
+}
+
+// deEsserParams are the de-esser's processor parameters
+var deEsserParams = params[DeEsserConfig]{
+	boolParam("enabled", true, func(c *DeEsserConfig) *bool { return &c.Enabled }),
+	{
+		Parameter: Parameter{Name: "low", Min: 1000, Max: 16000, Default: 4000, Unit: "Hz"},
+		get:       func(c *DeEsserConfig) float64 { return c.Low },
+		set:       func(c *DeEsserConfig, value float64) { c.Low = value },
+	},
+	{
+		Parameter: Parameter{Name: "high", Min: 2000, Max: 20000, Default: 10000, Unit: "Hz"},
+		get:       func(c *DeEsserConfig) float64 { return c.High },
+		set:       func(c *DeEsserConfig, value float64) { c.High = value },
+	},
+	floatParam("threshold", -60, 0, -30, "dB", func(c *DeEsserConfig) *float32 { return &c.Threshold }),
+	floatParam("reduction", 0, 24, 8, "dB", func(c *DeEsserConfig) *float32 { return &c.Reduction }),
+	boolParam("listen", false, func(c *DeEsserConfig) *bool { return &c.Listen }),
+}
+
+// Type implements Processor
+func (d *deEsser) Type() string { return "deesser" }
+
+// Channels implements Processor
+func (d *deEsser) Channels() int { return d.channels }
+
+// Process implements Processor
+func (d *deEsser) Process(buf []float32) { d.process(buf) }
+
+// Latency implements Processor; the de-esser adds none
+func (d *deEsser) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (d *deEsser) Reset() { d.reset() }
+
+// Parameters implements Processor
+func (d *deEsser) Parameters() []Parameter { return deEsserParams.parameters() }
+
+// Parameter implements Processor
+func (d *deEsser) Parameter(name string) (float64, error) {
+	return deEsserParams.value(d.get(), name)
+}
+
+// SetParameter implements Processor
+func (d *deEsser) SetParameter(name string, value float64) error {
+	cfg, err := deEsserParams.update(d.get(), name, value)
+	if err == nil {
+		d.set(cfg)
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MaxInputDelay is the longest delay an input can be given
+const MaxInputDelay = time.Second
+
+// delayLine holds an input back by a number of frames to line it up with
+// the others. It keeps the last MaxInputDelay of audio in a ring, so the
+// delay can change at any time; a change crossfades from the old to the
+// new delay over InputRampTime instead of jumping.
+//
+// The delay is published through target and picked up by process, which is
+// only called from the output callback.
+type delayLine struct {
+	channels  int
+	maxFrames int
+	fadeStep  float32 // Per-frame change of mix
+
+	target atomic.Int64 // Frames
+
+	// Output callback state: the ring of past frames with the next one to
+	// write, the delay in use, the one faded out of and the crossfade
+	// between them (0 previous, 1 current)
+	ring     []float32
+	write    int
+	current  int
+	previous int
+	mix      float32
+}
+
+// newDelayLine creates a delay line for interleaved audio at sampleRate,
+// delaying by frames, with room for buffers of up to bufferFrames
+func newDelayLine(channels int, sampleRate float64, bufferFrames, frames int) *delayLine {
+	d := &delayLine{
+		channels:  channels,
+		maxFrames: int(MaxInputDelay.Seconds() * sampleRate),
+		fadeStep:  float32(1 / max(1, InputRampTime.Seconds()*sampleRate)),
+		mix:       1,
+	}
+	d.ring = make([]float32, (d.maxFrames+bufferFrames)*channels)
+	d.set(frames)
+	d.current = d.get()
+	return d
+}
+
+// set publishes a new delay in frames, clamped to MaxInputDelay
+func (d *delayLine) set(frames int) {
+	d.target.Store(int64(min(max(frames, 0), d.maxFrames)))
+}
+
+// get returns the current delay in frames
+func (d *delayLine) get() int {
+	return int(d.target.Load())
+}
+
+// reset clears the ring and jumps to the current delay
+func (d *delayLine) reset() {
+	clear(d.ring)
+	d.write = 0
+	d.current = d.get()
+	d.mix = 1
+}
+
+// process delays buf in place
+func (d *delayLine) process(buf []float32) {
+	ch := d.channels
+	frames := len(buf) / ch
+	if need := (d.maxFrames + frames) * ch; need > len(d.ring) {
+		// Keep the ring's contents in order from its oldest frame
+		ring := make([]float32, need)
+		n := copy(ring, d.ring[d.write*ch:])
+		copy(ring[n:], d.ring[:d.write*ch])
+		d.write = len(d.ring) / ch
+		d.ring = ring
+	}
+	size := len(d.ring) / ch
+
+	// Start a crossfade to a new delay once the last one is done
+	if target := d.get(); target != d.current && d.mix >= 1 {
+		d.previous, d.current, d.mix = d.current, target, 0
+	}
+
+	start := d.write
+	for f := 0; f < frames; f++ {
+		pos := (start + f) % size
+		copy(d.ring[pos*ch:pos*ch+ch], buf[f*ch:f*ch+ch])
+	}
+	d.write = (start + frames) % size
+
+	for f := 0; f < frames; f++ {
+		cur := ((start+f-d.current)%size + size) % size
+		if d.mix >= 1 {
+			copy(buf[f*ch:f*ch+ch], d.ring[cur*ch:cur*ch+ch])
+			continue
+		}
+		d.mix = min(d.mix+d.fadeStep, 1)
+		prev := ((start+f-d.previous)%size + size) % size
+		for c := 0; c < ch; c++ {
+			old := d.ring[prev*ch+c]
+			buf[f*ch+c] = old + d.mix*(d.ring[cur*ch+c]-old)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DenoiserMaxReduction is how far full strength turns noise down, in dB
+	DenoiserMaxReduction = 30
+
+	// DenoiserLearnTime is how long a requested noise profile is averaged
+	DenoiserLearnTime = 1 * time.Second
+
+	// denoiserFrameTime is the shortest analysis frame; frames are the next
+	// power of two in samples, 512 at 44.1 and 48 kHz
+	denoiserFrameTime = 10 * time.Millisecond
+
+	// Noise tracking: the smoothing of the power spectrum per frame, how
+	// fast the noise floor may rise in dB per second and how far the mean
+	// noise lies above the tracked minimum
+	denoiserSmoothing = 0.8
+	denoiserRise      = 5.0
+	denoiserBias      = 1.5
+
+	// denoiserPriorSmoothing weights the previous frame in the
+	// decision-directed a priori SNR, which keeps musical noise down
+	denoiserPriorSmoothing = 0.98
+)
+
+// DenoiserConfig configures the noise suppression of an input
+type DenoiserConfig struct {
+	Enabled  bool
+	Bypass   bool    // Pass audio through unchanged, keeping the latency and the noise profile
+	Strength float32 // 0 to 1, how far noise is turned down up to DenoiserMaxReduction
+	Adaptive bool    // Track the noise profile continuously instead of only learning it on request
+}
+
+// DefaultDenoiserConfig returns settings for steady fan and hiss noise
+func DefaultDenoiserConfig() DenoiserConfig {
+	return DenoiserConfig{
+		Strength: 0.7,
+		Adaptive: true,
+	}
+}
+
+// denoiser suppresses stationary noise with a Wiener filter in the
+// short-time Fourier domain. Frames overlap by half under a square root
+// Hann window on analysis and synthesis, so with every gain at one the
+// output is the input delayed by one frame: the newest half of a frame
+// waits for the next frame, which completes its overlap-add. The noise
+// power spectrum is either averaged over DenoiserLearnTime on request or,
+// when adaptive, tracked continuously by following the minimum of the
+// smoothed power. One gain per frequency, from the power of all channels
+// together, keeps the stereo image in place.
+//
+// Settings and learn requests are published atomically and picked up by
+// process, which is only called from the output callback.
+type denoiser struct {
+	config     atomic.Value // DenoiserConfig
+	learnReq   atomic.Bool  // A noise profile was requested
+	learning   atomic.Bool  // A noise profile is being learned
+	sampleRate float64
+	channels   int
+
+	// Frame layout and transform
+	size   int // Frame length, a power of two, which is also the delay in frames
+	hop    int // New samples per frame, half the frame
+	window []float64
+	fft    *fft
+
+	// Output callback state: the settings in use, the position in the
+	// current frame, from hop to size as new samples arrive, per channel
+	// input and output FIFOs and overlap-add accumulators of size samples
+	// ([channel*size+i]), the spectrum being worked on and per frequency bin
+	// state for the bins up to size/2
+	active     DenoiserConfig
+	rover      int
+	inFIFO     []float32
+	outFIFO    []float32
+	accum      []float64
+	spectra    []complex128 // Every channel's spectrum, [channel*size+bin]
+	power      []float64    // Power of the current frame
+	smoothed   []float64    // Smoothed power, adaptive tracking only
+	noise      []float64    // Noise power profile
+	learned    []float64    // Sum of power over the frames learned so far
+	learnLeft  int          // Frames left to learn
+	learnTotal int
+	gain       []float64
+	prior      []float64 // Previous frame's gain² times posterior SNR
+	floor      float64
+	riseStep   float64
+	hasProfile bool
+}
+
+// newDenoiser creates a denoiser for interleaved audio at sampleRate
+func newDenoiser(cfg DenoiserConfig, channels int, sampleRate float64) *denoiser {
+	size := 1
+	for float64(size) < denoiserFrameTime.Seconds()*sampleRate {
+		size *= 2
+	}
+	hop := size / 2
+	bins := size/2 + 1
+
+	d := &denoiser{
+		sampleRate: sampleRate,
+		channels:   channels,
+		size:       size,
+		hop:        hop,
+		window:     make([]float64, size),
+		fft:        newFFT(size),
+		inFIFO:     make([]float32, channels*size),
+		outFIFO:    make([]float32, channels*size),
+		accum:      make([]float64, channels*size),
+		spectra:    make([]complex128, channels*size),
+		power:      make([]float64, bins),
+		smoothed:   make([]float64, bins),
+		noise:      make([]float64, bins),
+		learned:    make([]float64, bins),
+		gain:       make([]float64, bins),
+		prior:      make([]float64, bins),
+		learnTotal: max(1, int(DenoiserLearnTime.Seconds()*sampleRate)/hop),
+		riseStep:   math.Pow(10, denoiserRise/10*float64(hop)/sampleRate),
+	}
+	for i := range d.window {
+		d.window[i] = math.Sqrt(0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(size))))
+	}
+	d.config.Store(cfg)
+	d.configure(cfg)
+	d.reset()
+	return d
+}
+
+// set publishes new settings, applied from the next buffer on
+func (d *denoiser) set(cfg DenoiserConfig) {
+	d.config.Store(cfg)
+}
+
+// get returns the current settings
+func (d *denoiser) get() DenoiserConfig {
+	return d.config.Load().(DenoiserConfig)
+}
+
+// learn requests a new noise profile from the next DenoiserLearnTime of audio
+func (d *denoiser) learn() {
+	d.learnReq.Store(true)
+	d.learning.Store(true)
+}
+
+// delay returns the latency the denoiser adds, zero while it is disabled
+func (d *denoiser) delay() time.Duration {
+	if !d.get().Enabled {
+		return 0
+	}
+	return framesDuration(d.size, d.sampleRate)
+}
+
+// configure derives the gain floor for cfg, starting over from silence
+// when the denoiser is switched on
+func (d *denoiser) configure(cfg DenoiserConfig) {
+	if cfg.Enabled && !d.active.Enabled {
+		d.clearFrames()
+	}
+	if cfg.Adaptive && !d.active.Adaptive && !d.hasProfile {
+		d.startTracking()
+	}
+	d.active = cfg
+	d.floor = float64(dbToGain(-DenoiserMaxReduction * min(max(cfg.Strength, 0), 1)))
+}
+
+// reset clears the audio in flight and forgets the noise profile
+func (d *denoiser) reset() {
+	d.clearFrames()
+	d.startTracking()
+	d.hasProfile = false
+	d.learnLeft = 0
+	d.learning.Store(d.learnReq.Load())
+}
+
+// clearFrames drops the audio in flight and restarts the first frame
+func (d *denoiser) clearFrames() {
+	clear(d.inFIFO)
+	clear(d.outFIFO)
+	clear(d.accum)
+	clear(d.prior)
+	for k := range d.gain {
+		d.gain[k] = 1
+	}
+	d.rover = d.hop
+}
+
+// startTracking starts adaptive noise tracking over, following the first
+// frame down from there
+func (d *denoiser) startTracking() {
+	for k := range d.noise {
+		d.smoothed[k] = 0
+		d.noise[k] = math.Inf(1)
+	}
+}
+
+// process denoises buf, interleaved with channels channels, in place
+func (d *denoiser) process(buf []float32) {
+	if cfg := d.get(); cfg != d.active {
+		d.configure(cfg)
+	}
+	if !d.active.Enabled {
+		return
+	}
+	if d.learnReq.Swap(false) {
+		clear(d.learned)
+		d.learnLeft = d.learnTotal
+	}
+
+	ch := d.channels
+	for f := 0; f < len(buf)/ch; f++ {
+		for c := 0; c < ch; c++ {
+			d.inFIFO[c*d.size+d.rover] = buf[f*ch+c]
+			buf[f*ch+c] = d.outFIFO[c*d.size+d.rover-d.hop]
+		}
+		d.rover++
+		if d.rover == d.size {
+			d.processFrame()
+			d.rover = d.hop
+		}
+	}
+}
+
+// processFrame filters the frame in the input FIFOs, adds it to the
+// accumulators and moves the next hop of output to the output FIFOs
+func (d *denoiser) processFrame() {
+	bins := len(d.power)
+	clear(d.power)
+	for c := 0; c < d.channels; c++ {
+		in := d.inFIFO[c*d.size : (c+1)*d.size]
+		spectrum := d.spectra[c*d.size : (c+1)*d.size]
+		for i, sample := range in {
+			spectrum[i] = complex(float64(sample)*d.window[i], 0)
+		}
+		d.fft.forward(spectrum)
+		for k := 0; k < bins; k++ {
+			re, im := real(spectrum[k]), imag(spectrum[k])
+			d.power[k] += (re*re + im*im) / float64(d.channels)
+		}
+	}
+
+	d.estimateNoise()
+	d.updateGains()
+
+	for c := 0; c < d.channels; c++ {
+		spectrum := d.spectra[c*d.size : (c+1)*d.size]
+		for k := 0; k < bins; k++ {
+			spectrum[k] *= complex(d.gain[k], 0)
+			if k > 0 && k < d.size-k {
+				spectrum[d.size-k] *= complex(d.gain[k], 0)
+			}
+		}
+		d.fft.inverse(spectrum)
+
+		accum := d.accum[c*d.size : (c+1)*d.size]
+		for i := range accum {
+			accum[i] += real(spectrum[i]) * d.window[i] / float64(d.size)
+		}
+		out := d.outFIFO[c*d.size : (c+1)*d.size]
+		for i := 0; i < d.hop; i++ {
+			out[i] = float32(accum[i])
+		}
+		copy(accum, accum[d.hop:])
+		clear(accum[d.size-d.hop:])
+
+		in := d.inFIFO[c*d.size : (c+1)*d.size]
+		copy(in, in[d.hop:])
+	}
+}
+
+// estimateNoise updates the noise profile from the current frame's power,
+// learning it on request and tracking it when adaptive
+func (d *denoiser) estimateNoise() {
+	if d.learnLeft > 0 {
+		for k, p := range d.power {
+			d.learned[k] += p
+		}
+		d.learnLeft--
+		if d.learnLeft == 0 {
+			for k, sum := range d.learned {
+				d.noise[k] = sum / float64(d.learnTotal)
+				d.smoothed[k] = d.noise[k]
+			}
+			d.hasProfile = true
+			d.learning.Store(false)
+		}
+		return
+	}
+	if !d.active.Adaptive {
+		return
+	}
+
+	for k, p := range d.power {
+		if math.IsInf(d.noise[k], 1) {
+			d.smoothed[k] = p
+		}
+		d.smoothed[k] = denoiserSmoothing*d.smoothed[k] + (1-denoiserSmoothing)*p
+		if d.smoothed[k] < d.noise[k] {
+			d.noise[k] = d.smoothed[k]
+		} else {
+			d.noise[k] *= d.riseStep
+		}
+	}
+	d.hasProfile = true
+}
+
+// updateGains works out the Wiener gain of every bin from its a priori SNR,
+// estimated decision-directed, keeping it above the strength's floor
+func (d *denoiser) updateGains() {
+	if d.active.Bypass || !d.hasProfile {
+		for k := range d.gain {
+			d.gain[k] = 1
+		}
+		return
+	}
+
+	// A tracked minimum lies below the mean noise, a learned profile does not
+	bias := 1.0
+	if d.active.Adaptive {
+		bias = denoiserBias
+	}
+	for k, p := range d.power {
+		posterior := p / (d.noise[k]*bias + 1e-20)
+		prior := denoiserPriorSmoothing*d.prior[k] + (1-denoiserPriorSmoothing)*max(posterior-1, 0)
+		gain := max(prior/(1+prior), d.floor)
+		d.gain[k] = gain
+		d.prior[k] = gain * gain * posterior
+	}
+}
+
+// denoiserParams are the denoiser's processor parameters
+var denoiserParams = params[DenoiserConfig]{
+	boolParam("enabled", true, func(c *DenoiserConfig) *bool { return &c.Enabled }),
+	boolParam("bypass", false, func(c *DenoiserConfig) *bool { return &c.Bypass }),
+	floatParam("strength", 0, 1, 0.7, "", func(c *DenoiserConfig) *float32 { return &c.Strength }),
+	boolParam("adaptive", true, func(c *DenoiserConfig) *bool { return &c.Adaptive }),
+}
+
+// Type implements Processor
+func (d *denoiser) Type() string { return "denoiser" }
+
+// Channels implements Processor
+func (d *denoiser) Channels() int { return d.channels }
+
+// Process implements Processor
+func (d *denoiser) Process(buf []float32) { d.process(buf) }
+
+// Latency implements Processor
+func (d *denoiser) Latency() time.Duration { return d.delay() }
+
+// MaxLatency implements VariableLatency
+func (d *denoiser) MaxLatency() time.Duration { return framesDuration(d.size, d.sampleRate) }
+
+// Reset implements Processor
+func (d *denoiser) Reset() { d.reset() }
+
+// Parameters implements Processor
+func (d *denoiser) Parameters() []Parameter { return denoiserParams.parameters() }
+
+// Parameter implements Processor
+func (d *denoiser) Parameter(name string) (float64, error) {
+	return denoiserParams.value(d.get(), name)
+}
+
+// SetParameter implements Processor
+func (d *denoiser) SetParameter(name string, value float64) error {
+	cfg, err := denoiserParams.update(d.get(), name, value)
+	if err == nil {
+		d.set(cfg)
+	}
+	return err
+}
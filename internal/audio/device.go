@@ -77,29 +77,7 @@ func (dm *DeviceManager) ListDevices() ([]*DeviceInfo, error) {
 
 	var deviceList []*DeviceInfo
 	for i, dev := range devices {
-		hostAPIName := "Unknown"
-		if dev.HostApi != nil {
-			hostAPIName = dev.HostApi.Name
-		}
-
-		// Ensure device name is valid UTF-8
-		deviceName := dev.Name
-		if !isValidUTF8(deviceName) {
-			// If not valid UTF-8, try to sanitize it
-			deviceName = sanitizeString(deviceName)
-		}
-
-		info := &DeviceInfo{
-			Index:             i,
-			Name:              deviceName,
-			MaxInputChannels:  dev.MaxInputChannels,
-			MaxOutputChannels: dev.MaxOutputChannels,
-			DefaultSampleRate: dev.DefaultSampleRate,
-			IsDefaultInput:    defaultInput != nil && dev == defaultInput,
-			IsDefaultOutput:   defaultOutput != nil && dev == defaultOutput,
-			HostAPI:           hostAPIName,
-		}
-		deviceList = append(deviceList, info)
+		deviceList = append(deviceList, newDeviceInfo(i, dev, defaultInput, defaultOutput))
 	}
 
 	return deviceList, nil
@@ -140,14 +118,10 @@ func (dm *DeviceManager) GetOutputDevices() ([]*DeviceInfo, error) {
 }
 
 // GetDeviceByIndex returns a specific device by index
-func (dm *DeviceManager) GetDeviceByIndex(index int) (*portaudio.DeviceInfo, error) {
-	if !dm.initialized {
-		return nil, fmt.Errorf("device manager not initialized")
-	}
-
-	devices, err := portaudio.Devices()
+func (dm *DeviceManager) GetDeviceByIndex(index int) (*DeviceInfo, error) {
+	devices, err := dm.ListDevices()
 	if err != nil {
-		return nil, fmt.Errorf("failed to enumerate devices: %w", err)
+		return nil, err
 	}
 
 	if index < 0 || index >= len(devices) {
@@ -158,31 +132,61 @@ func (dm *DeviceManager) GetDeviceByIndex(index int) (*portaudio.DeviceInfo, err
 }
 
 // GetDefaultInputDevice returns the default input device
-func (dm *DeviceManager) GetDefaultInputDevice() (*portaudio.DeviceInfo, error) {
-	if !dm.initialized {
-		return nil, fmt.Errorf("device manager not initialized")
+func (dm *DeviceManager) GetDefaultInputDevice() (*DeviceInfo, error) {
+	devices, err := dm.ListDevices()
+	if err != nil {
+		return nil, err
 	}
 
-	dev, err := portaudio.DefaultInputDevice()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get default input device: %w", err)
+	for _, dev := range devices {
+		if dev.IsDefaultInput {
+			return dev, nil
+		}
 	}
 
-	return dev, nil
+	return nil, fmt.Errorf("failed to get default input device: no default input device")
 }
 
 // GetDefaultOutputDevice returns the default output device
-func (dm *DeviceManager) GetDefaultOutputDevice() (*portaudio.DeviceInfo, error) {
-	if !dm.initialized {
-		return nil, fmt.Errorf("device manager not initialized")
+func (dm *DeviceManager) GetDefaultOutputDevice() (*DeviceInfo, error) {
+	devices, err := dm.ListDevices()
+	if err != nil {
+		return nil, err
 	}
 
-	dev, err := portaudio.DefaultOutputDevice()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get default output device: %w", err)
+	for _, dev := range devices {
+		if dev.IsDefaultOutput {
+			return dev, nil
+		}
 	}
 
-	return dev, nil
+	return nil, fmt.Errorf("failed to get default output device: no default output device")
+}
+
+// newDeviceInfo converts a PortAudio device at the given index to DeviceInfo
+func newDeviceInfo(index int, dev, defaultInput, defaultOutput *portaudio.DeviceInfo) *DeviceInfo {
+	hostAPIName := "Unknown"
+	if dev.HostApi != nil {
+		hostAPIName = dev.HostApi.Name
+	}
+
+	// Ensure device name is valid UTF-8
+	deviceName := dev.Name
+	if !isValidUTF8(deviceName) {
+		// If not valid UTF-8, try to sanitize it
+		deviceName = sanitizeString(deviceName)
+	}
+
+	return &DeviceInfo{
+		Index:             index,
+		Name:              deviceName,
+		MaxInputChannels:  dev.MaxInputChannels,
+		MaxOutputChannels: dev.MaxOutputChannels,
+		DefaultSampleRate: dev.DefaultSampleRate,
+		IsDefaultInput:    defaultInput != nil && dev == defaultInput,
+		IsDefaultOutput:   defaultOutput != nil && dev == defaultOutput,
+		HostAPI:           hostAPIName,
+	}
 }
 
 // isValidUTF8 checks if a string is valid UTF-8
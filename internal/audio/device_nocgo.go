@@ -41,23 +41,71 @@ func (dm *DeviceManager) Terminate() error {
 	return nil
 }
 
-// ListDevices returns all available audio devices (stub)
+// ListDevices returns all available audio devices
 func (dm *DeviceManager) ListDevices() ([]*DeviceInfo, error) {
 	if !dm.initialized {
 		return nil, fmt.Errorf("device manager not initialized")
 	}
 
-	// Return empty list when CGO is disabled
+	// Without CGO only the virtual devices of the null backend exist
 	// On Windows, WASAPI will be used instead
-	return []*DeviceInfo{}, nil
+	return nullDevices(), nil
 }
 
-// GetInputDevices returns only input-capable devices (stub)
+// GetInputDevices returns only input-capable devices
 func (dm *DeviceManager) GetInputDevices() ([]*DeviceInfo, error) {
-	return dm.ListDevices()
+	allDevices, err := dm.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var inputDevices []*DeviceInfo
+	for _, dev := range allDevices {
+		if dev.MaxInputChannels > 0 {
+			inputDevices = append(inputDevices, dev)
+		}
+	}
+
+	return inputDevices, nil
 }
 
-// GetOutputDevices returns only output-capable devices (stub)
+// GetOutputDevices returns only output-capable devices
 func (dm *DeviceManager) GetOutputDevices() ([]*DeviceInfo, error) {
-	return dm.ListDevices()
+	allDevices, err := dm.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var outputDevices []*DeviceInfo
+	for _, dev := range allDevices {
+		if dev.MaxOutputChannels > 0 {
+			outputDevices = append(outputDevices, dev)
+		}
+	}
+
+	return outputDevices, nil
+}
+
+// GetDeviceByIndex returns a specific device by index
+func (dm *DeviceManager) GetDeviceByIndex(index int) (*DeviceInfo, error) {
+	devices, err := dm.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(devices) {
+		return nil, fmt.Errorf("device index %d out of range", index)
+	}
+
+	return devices[index], nil
+}
+
+// GetDefaultInputDevice returns the default input device
+func (dm *DeviceManager) GetDefaultInputDevice() (*DeviceInfo, error) {
+	return dm.GetDeviceByIndex(NullInputDeviceIndex)
+}
+
+// GetDefaultOutputDevice returns the default output device
+func (dm *DeviceManager) GetDefaultOutputDevice() (*DeviceInfo, error) {
+	return dm.GetDeviceByIndex(NullOutputDeviceIndex)
 }
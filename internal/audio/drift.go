@@ -0,0 +1,122 @@
+package audio
+
+const (
+	// MaxDriftRatio bounds how far the resampling ratio may move away from 1.0
+	// (0.5% covers any sane pair of free-running sound card clocks)
+	MaxDriftRatio = 0.005
+
+	// Controller tuning, per output buffer. With the fill error measured in
+	// buffers the loop is a damped second-order system with a natural
+	// frequency of sqrt(driftIntegral) rad/buffer and a damping ratio of
+	// driftProportional / (2*sqrt(driftIntegral)) = 0.8, slow enough to stay
+	// well below the corner of the fill smoothing filter.
+	driftFillSmoothing = 0.01   // One-pole low-pass applied to the measured fill level
+	driftProportional  = 0.0008 // Ratio change per buffer of fill error
+	driftIntegral      = 2.5e-7 // Ratio change per buffer of accumulated fill error
+)
+
+// driftCompensator keeps an input's ring buffer at a target fill level by
+// consuming slightly more or fewer input frames than the output asks for.
+// Input and output devices run on independent clocks; without this the
+// buffer slowly drains or overflows and the input glitches.
+//
+// It is only used from the output callback and is not safe for concurrent use.
+type driftCompensator struct {
+	channels   int
+	bufferSize int     // Output buffer size in frames
+	targetFill float64 // Target fill level in frames
+
+	ratio        float64 // Input frames consumed per output frame
+	filteredFill float64
+	integral     float64
+	primed       bool
+
+	// window holds interleaved input frames not yet fully consumed;
+	// pos is the fractional read position into it, in frames
+	window []float32
+	pos    float64
+}
+
+// newDriftCompensator creates a compensator for buffers of up to bufferSize frames
+func newDriftCompensator(channels, bufferSize int, targetFill float64) *driftCompensator {
+	maxFrames := int(float64(bufferSize)*(1+MaxDriftRatio)) + 4
+	return &driftCompensator{
+		channels:   channels,
+		bufferSize: bufferSize,
+		targetFill: targetFill,
+		ratio:      1,
+		window:     make([]float32, 0, maxFrames*channels),
+	}
+}
+
+// reset forgets all controller and interpolation state
+func (d *driftCompensator) reset() {
+	d.ratio = 1
+	d.filteredFill = 0
+	d.integral = 0
+	d.primed = false
+	d.window = d.window[:0]
+	d.pos = 0
+}
+
+// update feeds the current buffer fill level (in frames) to the controller
+// and returns the new resampling ratio
+func (d *driftCompensator) update(fill float64) float64 {
+	if !d.primed {
+		d.filteredFill = fill
+		d.primed = true
+	}
+	d.filteredFill += driftFillSmoothing * (fill - d.filteredFill)
+
+	// Error in buffers: positive when the buffer is fuller than wanted,
+	// which means the input clock runs fast and we must consume faster
+	err := (d.filteredFill - d.targetFill) / float64(d.bufferSize)
+
+	d.integral += err
+	maxIntegral := MaxDriftRatio / driftIntegral
+	d.integral = max(-maxIntegral, min(d.integral, maxIntegral))
+
+	ratio := 1 + driftProportional*err + driftIntegral*d.integral
+	d.ratio = max(1-MaxDriftRatio, min(ratio, 1+MaxDriftRatio))
+	return d.ratio
+}
+
+// process fills out with resampled frames read from buffer at the current ratio
+func (d *driftCompensator) process(buffer *AudioBuffer, out []float32) {
+	ch := d.channels
+	frames := len(out) / ch
+	if frames == 0 {
+		return
+	}
+
+	// Pull enough input frames for the last output frame and its right neighbour
+	lastPos := d.pos + float64(frames-1)*d.ratio
+	needed := int(lastPos) + 2
+	if have := len(d.window) / ch; needed > have {
+		start := len(d.window)
+		d.window = d.window[:needed*ch]
+		buffer.Read(d.window[start:])
+	}
+
+	// Linear interpolation between neighbouring frames
+	pos := d.pos
+	for f := 0; f < frames; f++ {
+		i := int(pos)
+		t := float32(pos - float64(i))
+		a := d.window[i*ch : i*ch+ch]
+		b := d.window[(i+1)*ch : (i+1)*ch+ch]
+		for c := 0; c < ch; c++ {
+			out[f*ch+c] = a[c] + (b[c]-a[c])*t
+		}
+		pos += d.ratio
+	}
+
+	// Drop fully consumed frames, keep the rest for the next buffer
+	consumed := int(pos)
+	if consumed > len(d.window)/ch {
+		consumed = len(d.window) / ch
+	}
+	remaining := copy(d.window, d.window[consumed*ch:])
+	d.window = d.window[:remaining]
+	d.pos = pos - float64(consumed)
+}
@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// DuckingConfig configures sidechain ducking: while the trigger input is
+// louder than the threshold, every other input is turned down by depth
+type DuckingConfig struct {
+	Enabled   bool
+	Trigger   int           // Mixer index of the trigger input, usually the microphone; -1 for none
+	Threshold float32       // RMS level in dBFS of the trigger above which ducking starts
+	Depth     float32       // How far the other inputs are turned down, in dB
+	Attack    time.Duration // Time to duck fully
+	Hold      time.Duration // Time ducking lasts after the trigger gets quiet
+	Release   time.Duration // Time to recover fully
+}
+
+// DefaultDuckingConfig returns settings that keep speech on the first input
+// intelligible over application audio
+func DefaultDuckingConfig() DuckingConfig {
+	return DuckingConfig{
+		Threshold: -40,
+		Depth:     12,
+		Attack:    20 * time.Millisecond,
+		Hold:      300 * time.Millisecond,
+		Release:   500 * time.Millisecond,
+	}
+}
+
+// ducker turns inputs down while a trigger input is active. The trigger is
+// detected once per buffer from its RMS level; the gain then moves
+// dB-linearly between unity and the depth, taking the attack time to duck
+// and the release time to recover.
+//
+// Settings are published through config and picked up by update, which,
+// like apply, is only called from the output callback.
+type ducker struct {
+	config     atomic.Value // DuckingConfig
+	sampleRate float64
+
+	// Output callback state: the settings in use with their derived
+	// coefficients, the hold countdown and the per-frame gains of the
+	// current buffer
+	active      DuckingConfig
+	threshold   float32
+	floor       float32
+	attackStep  float32
+	releaseStep float32
+	holdFrames  int
+	holdLeft    int
+	gain        float32
+	gains       []float32
+
+	gainReduction atomic.Value // float32, dB
+}
+
+// newDucker creates a ducker for audio at sampleRate in buffers of up to
+// maxFrames frames
+func newDucker(cfg DuckingConfig, sampleRate float64, maxFrames int) *ducker {
+	d := &ducker{
+		sampleRate: sampleRate,
+		gains:      make([]float32, maxFrames),
+	}
+	d.config.Store(cfg)
+	d.configure(cfg)
+	d.reset()
+	return d
+}
+
+// set publishes new settings, applied from the next buffer on
+func (d *ducker) set(cfg DuckingConfig) {
+	d.config.Store(cfg)
+}
+
+// get returns the current settings
+func (d *ducker) get() DuckingConfig {
+	return d.config.Load().(DuckingConfig)
+}
+
+// configure derives the levels and coefficients for cfg
+func (d *ducker) configure(cfg DuckingConfig) {
+	d.active = cfg
+	d.threshold = dbToGain(cfg.Threshold)
+	d.floor = dbToGain(-max(cfg.Depth, 0))
+	d.attackStep = float32(math.Pow(float64(d.floor), 1/max(1, cfg.Attack.Seconds()*d.sampleRate)))
+	d.releaseStep = float32(math.Pow(float64(1/d.floor), 1/max(1, cfg.Release.Seconds()*d.sampleRate)))
+	d.holdFrames = int(cfg.Hold.Seconds() * d.sampleRate)
+}
+
+// reset stops ducking
+func (d *ducker) reset() {
+	d.holdLeft = 0
+	d.gain = 1
+	d.gainReduction.Store(float32(0))
+}
+
+// trigger returns the strip that triggers ducking, or nil while ducking is
+// disabled or the trigger is not one of inputs
+func (d *ducker) trigger(inputs []*inputStrip) *inputStrip {
+	if cfg := d.get(); cfg != d.active {
+		d.configure(cfg)
+	}
+	if !d.active.Enabled || d.active.Trigger < 0 || d.active.Trigger >= len(inputs) {
+		if d.gain != 1 {
+			d.reset()
+		}
+		return nil
+	}
+	return inputs[d.active.Trigger]
+}
+
+// update works out the ducking gains for the next frames, at most the
+// maxFrames the ducker was created for, from the trigger's RMS level over
+// them
+func (d *ducker) update(level float32, frames int) {
+	if level > d.threshold {
+		d.holdLeft = d.holdFrames
+	} else {
+		d.holdLeft = max(d.holdLeft-frames, 0)
+	}
+	ducking := level > d.threshold || d.holdLeft > 0
+
+	for f := 0; f < frames; f++ {
+		if ducking {
+			d.gain = max(d.gain*d.attackStep, d.floor)
+		} else {
+			d.gain = min(d.gain*d.releaseStep, 1)
+		}
+		d.gains[f] = d.gain
+	}
+	d.gainReduction.Store(max(0, -gainToDB(d.gain)))
+}
+
+// apply ducks buf, interleaved with channels channels, by the gains of
+// the last update
+func (d *ducker) apply(buf []float32, channels int) {
+	for f := 0; f < len(buf)/channels; f++ {
+		gain := d.gains[f]
+		for i := f * channels; i < f*channels+channels; i++ {
+			buf[i] *= gain
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	echoMaxTime     = 2 * time.Second
+	echoMaxFeedback = 0.95
+	echoTimeGlide   = 50 * time.Millisecond // How fast a changed time slides to the new one
+)
+
+// echoConfig configures an echo
+type echoConfig struct {
+	Enabled  bool
+	Time     time.Duration // Time between repeats, free of any tempo
+	Feedback float32       // Level of each repeat relative to the one before, 0 to echoMaxFeedback
+	Tone     float64       // Cutoff in Hz of the lowpass each repeat goes through
+	Mix      float32       // Echo in the output, 0 (dry) to 1 (echo only, for aux buses)
+}
+
+// defaultEchoConfig returns a few darkening repeats, all echo for an aux
+// bus
+func defaultEchoConfig() echoConfig {
+	return echoConfig{
+		Enabled:  true,
+		Time:     350 * time.Millisecond,
+		Feedback: 0.4,
+		Tone:     4000,
+		Mix:      1,
+	}
+}
+
+// echo is a feedback delay. Each channel's output is read from a ring at
+// the echo time, lowpassed and fed back into the ring, so every repeat is
+// quieter and darker than the last. The read position is fractional and
+// glides to a changed time, bending the pitch of the repeats like a tape
+// echo rather than clicking.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type echo struct {
+	config     atomic.Value // echoConfig
+	sampleRate float64
+	channels   int
+	glideCoeff float32
+
+	// Output callback state: the ring of past frames with the next one to
+	// write, the gliding delay in frames, the lowpass in use and its memory
+	// for every channel
+	active  echoConfig
+	ring    []float32
+	write   int
+	delay   float32
+	toneA   float32
+	lowpass []float32
+}
+
+// newEcho creates an echo for interleaved audio at sampleRate
+func newEcho(cfg echoConfig, channels int, sampleRate float64) *echo {
+	e := &echo{
+		sampleRate: sampleRate,
+		channels:   channels,
+		glideCoeff: smoothingCoeff(echoTimeGlide, sampleRate),
+		ring:       make([]float32, (int(echoMaxTime.Seconds()*sampleRate)+2)*channels),
+		lowpass:    make([]float32, channels),
+	}
+	e.config.Store(cfg)
+	e.reset()
+	return e
+}
+
+// set publishes new settings, applied from the next buffer on
+func (e *echo) set(cfg echoConfig) {
+	e.config.Store(cfg)
+}
+
+// get returns the current settings
+func (e *echo) get() echoConfig {
+	return e.config.Load().(echoConfig)
+}
+
+// configure derives the feedback lowpass for cfg
+func (e *echo) configure(cfg echoConfig) {
+	e.active = cfg
+	cutoff := min(max(cfg.Tone, 20), 0.45*e.sampleRate)
+	e.toneA = float32(1 - math.Exp(-2*math.Pi*cutoff/e.sampleRate))
+}
+
+// targetDelay returns the echo time in frames, within the ring
+func (e *echo) targetDelay() float32 {
+	frames := e.active.Time.Seconds() * e.sampleRate
+	return float32(min(max(frames, 1), float64(len(e.ring)/e.channels-2)))
+}
+
+// reset silences the echo and jumps to the current time
+func (e *echo) reset() {
+	e.configure(e.get())
+	clear(e.ring)
+	clear(e.lowpass)
+	e.write = 0
+	e.delay = e.targetDelay()
+}
+
+// process adds echo to buf in place
+func (e *echo) process(buf []float32) {
+	ch := e.channels
+	if cfg := e.get(); cfg != e.active {
+		e.configure(cfg)
+	}
+	if !e.active.Enabled {
+		return
+	}
+
+	size := len(e.ring) / ch
+	target := e.targetDelay()
+	feedback := min(max(e.active.Feedback, 0), echoMaxFeedback)
+	wet := e.active.Mix
+	dry := 1 - wet
+
+	for f := 0; f < len(buf)/ch; f++ {
+		e.delay += (target - e.delay) * e.glideCoeff
+
+		// Read between the two frames around the delay
+		whole := int(e.delay)
+		frac := e.delay - float32(whole)
+		a := ((e.write-whole)%size + size) % size
+		b := (a - 1 + size) % size
+
+		frame := buf[f*ch : f*ch+ch]
+		for c, x := range frame {
+			y := e.ring[a*ch+c] + frac*(e.ring[b*ch+c]-e.ring[a*ch+c])
+			e.lowpass[c] += (y - e.lowpass[c]) * e.toneA
+			e.ring[e.write*ch+c] = x + e.lowpass[c]*feedback
+			frame[c] = x*dry + y*wet
+		}
+		e.write = (e.write + 1) % size
+	}
+}
+
+// echoParams are the echo's processor parameters
+var echoParams = params[echoConfig]{
+	boolParam("enabled", true, func(c *echoConfig) *bool { return &c.Enabled }),
+	msParam("time", 1, float64(echoMaxTime/time.Millisecond), 350, func(c *echoConfig) *time.Duration { return &c.Time }),
+	floatParam("feedback", 0, echoMaxFeedback, 0.4, "", func(c *echoConfig) *float32 { return &c.Feedback }),
+	{
+		Parameter: Parameter{Name: "tone", Min: 500, Max: 20000, Default: 4000, Unit: "Hz"},
+		get:       func(c *echoConfig) float64 { return c.Tone },
+		set:       func(c *echoConfig, value float64) { c.Tone = value },
+	},
+	floatParam("mix", 0, 1, 1, "", func(c *echoConfig) *float32 { return &c.Mix }),
+}
+
+// Type implements Processor
+func (e *echo) Type() string { return "echo" }
+
+// Channels implements Processor
+func (e *echo) Channels() int { return e.channels }
+
+// Process implements Processor
+func (e *echo) Process(buf []float32) { e.process(buf) }
+
+// Latency implements Processor; the echo time is an effect, not latency
+func (e *echo) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (e *echo) Reset() { e.reset() }
+
+// Parameters implements Processor
+func (e *echo) Parameters() []Parameter { return echoParams.parameters() }
+
+// Parameter implements Processor
+func (e *echo) Parameter(name string) (float64, error) { return echoParams.value(e.get(), name) }
+
+// SetParameter implements Processor
+func (e *echo) SetParameter(name string, value float64) error {
+	cfg, err := echoParams.update(e.get(), name, value)
+	if err == nil {
+		e.set(cfg)
+	}
+	return err
+}
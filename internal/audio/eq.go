@@ -0,0 +1,309 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// MaxEQBands is the maximum number of bands of an equalizer
+const MaxEQBands = 8
+
+// FilterType selects the response of an equalizer band
+type FilterType int
+
+const (
+	FilterPeaking   FilterType = iota // Bell boosting or cutting around the frequency
+	FilterLowShelf                    // Boosts or cuts everything below the frequency
+	FilterHighShelf                   // Boosts or cuts everything above the frequency
+	FilterLowPass                     // Removes everything above the frequency
+	FilterHighPass                    // Removes everything below the frequency
+	FilterNotch                       // Removes a narrow band around the frequency
+)
+
+// filterTypeNames are the names used by String and ParseFilterType
+var filterTypeNames = map[FilterType]string{
+	FilterPeaking:   "peaking",
+	FilterLowShelf:  "low_shelf",
+	FilterHighShelf: "high_shelf",
+	FilterLowPass:   "low_pass",
+	FilterHighPass:  "high_pass",
+	FilterNotch:     "notch",
+}
+
+// String returns the filter type name
+func (t FilterType) String() string {
+	if name, ok := filterTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseFilterType returns the filter type with the given name
+func ParseFilterType(name string) (FilterType, error) {
+	for t, typeName := range filterTypeNames {
+		if typeName == name {
+			return t, nil
+		}
+	}
+	return FilterPeaking, fmt.Errorf("unknown filter type %q", name)
+}
+
+// EQBand is one band of a parametric equalizer
+type EQBand struct {
+	Type      FilterType
+	Frequency float64 // Centre, corner or shelf midpoint frequency in Hz
+	Gain      float32 // Boost or cut in dB, peaking and shelf bands only
+	Q         float32 // Bandwidth; 0.707 gives a flat pass band for low and high pass
+}
+
+// EQConfig configures a parametric equalizer of up to MaxEQBands bands
+type EQConfig struct {
+	Enabled bool
+	Bands   []EQBand
+}
+
+// biquadCoeffs are the normalised coefficients of a second-order filter
+// H(z) = (b0 + b1 z^-1 + b2 z^-2) / (1 + a1 z^-1 + a2 z^-2)
+type biquadCoeffs struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// biquadState is the memory of a biquad in transposed direct form II
+type biquadState struct {
+	z1, z2 float64
+}
+
+// coefficients computes the band's filter at sampleRate after the Audio EQ
+// Cookbook by Robert Bristow-Johnson
+func (b EQBand) coefficients(sampleRate float64) biquadCoeffs {
+	freq := math.Max(1, math.Min(b.Frequency, 0.499*sampleRate))
+	q := math.Max(0.01, float64(b.Q))
+	w0 := 2 * math.Pi * freq / sampleRate
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * q)
+	A := math.Pow(10, float64(b.Gain)/40)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch b.Type {
+	case FilterLowShelf:
+		sq := 2 * math.Sqrt(A) * alpha
+		b0 = A * ((A + 1) - (A-1)*cos + sq)
+		b1 = 2 * A * ((A - 1) - (A+1)*cos)
+		b2 = A * ((A + 1) - (A-1)*cos - sq)
+		a0 = (A + 1) + (A-1)*cos + sq
+		a1 = -2 * ((A - 1) + (A+1)*cos)
+		a2 = (A + 1) + (A-1)*cos - sq
+	case FilterHighShelf:
+		sq := 2 * math.Sqrt(A) * alpha
+		b0 = A * ((A + 1) + (A-1)*cos + sq)
+		b1 = -2 * A * ((A - 1) + (A+1)*cos)
+		b2 = A * ((A + 1) + (A-1)*cos - sq)
+		a0 = (A + 1) - (A-1)*cos + sq
+		a1 = 2 * ((A - 1) - (A+1)*cos)
+		a2 = (A + 1) - (A-1)*cos - sq
+	case FilterLowPass:
+		b0 = (1 - cos) / 2
+		b1 = 1 - cos
+		b2 = (1 - cos) / 2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterHighPass:
+		b0 = (1 + cos) / 2
+		b1 = -(1 + cos)
+		b2 = (1 + cos) / 2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FilterNotch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	default: // FilterPeaking
+		b0, b1, b2 = 1+alpha*A, -2*cos, 1-alpha*A
+		a0, a1, a2 = 1+alpha/A, -2*cos, 1-alpha/A
+	}
+
+	return biquadCoeffs{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// process filters one sample
+func (c *biquadCoeffs) process(s *biquadState, x float64) float64 {
+	y := c.b0*x + s.z1
+	s.z1 = c.b1*x - c.a1*y + s.z2
+	s.z2 = c.b2*x - c.a2*y
+	return y
+}
+
+// eqSettings is an equalizer configuration with its precomputed filters
+type eqSettings struct {
+	config EQConfig
+	coeffs []biquadCoeffs
+}
+
+// equalizer is a parametric equalizer of cascaded biquads. Filter
+// coefficients are computed by set, outside the audio thread, and
+// published as one immutable eqSettings; process picks them up at the next
+// buffer and keeps the filter memory of every band whose type is unchanged,
+// so bands can be tuned while audio runs.
+type equalizer struct {
+	settings   atomic.Value // *eqSettings
+	sampleRate float64
+	channels   int
+
+	// Output callback state: the settings in use and the memory of every
+	// band for every channel, [band*channels+channel]
+	active *eqSettings
+	state  []biquadState
+}
+
+// newEqualizer creates an equalizer for interleaved audio at sampleRate
+func newEqualizer(cfg EQConfig, channels int, sampleRate float64) *equalizer {
+	e := &equalizer{
+		sampleRate: sampleRate,
+		channels:   channels,
+		state:      make([]biquadState, MaxEQBands*channels),
+	}
+	e.set(cfg)
+	e.active = e.settings.Load().(*eqSettings)
+	return e
+}
+
+// set computes the filters for cfg and publishes them; bands beyond
+// MaxEQBands are ignored
+func (e *equalizer) set(cfg EQConfig) {
+	bands := cfg.Bands[:min(len(cfg.Bands), MaxEQBands)]
+	settings := &eqSettings{
+		config: EQConfig{Enabled: cfg.Enabled, Bands: append([]EQBand(nil), bands...)},
+		coeffs: make([]biquadCoeffs, len(bands)),
+	}
+	for i, band := range bands {
+		settings.coeffs[i] = band.coefficients(e.sampleRate)
+	}
+	e.settings.Store(settings)
+}
+
+// get returns the current settings
+func (e *equalizer) get() EQConfig {
+	cfg := e.settings.Load().(*eqSettings).config
+	cfg.Bands = append([]EQBand(nil), cfg.Bands...)
+	return cfg
+}
+
+// reset clears the filter memory
+func (e *equalizer) reset() {
+	for i := range e.state {
+		e.state[i] = biquadState{}
+	}
+}
+
+// process equalizes buf in place
+func (e *equalizer) process(buf []float32) {
+	if settings := e.settings.Load().(*eqSettings); settings != e.active {
+		e.switchTo(settings)
+	}
+	if !e.active.config.Enabled {
+		return
+	}
+
+	ch := e.channels
+	for b := range e.active.coeffs {
+		coeffs := &e.active.coeffs[b]
+		state := e.state[b*ch : b*ch+ch]
+		for i, sample := range buf {
+			buf[i] = float32(coeffs.process(&state[i%ch], float64(sample)))
+		}
+	}
+}
+
+// switchTo starts using new settings, clearing the memory of bands that
+// changed type or were not running before
+func (e *equalizer) switchTo(settings *eqSettings) {
+	previous := e.active.config
+	for b, band := range settings.config.Bands {
+		if !previous.Enabled || b >= len(previous.Bands) || previous.Bands[b].Type != band.Type {
+			for c := 0; c < e.channels; c++ {
+				e.state[b*e.channels+c] = biquadState{}
+			}
+		}
+	}
+	e.active = settings
+}
+
+// eqParams are the equalizer's processor parameters: the number of bands
+// and the type, frequency, gain and Q of each, named band1.type and so on.
+// Setting a band beyond the number of bands adds bands up to it.
+var eqParams = func() params[EQConfig] {
+	p := params[EQConfig]{
+		boolParam("enabled", true, func(c *EQConfig) *bool { return &c.Enabled }),
+		{
+			Parameter: Parameter{Name: "bands", Max: MaxEQBands, Step: 1},
+			get:       func(c *EQConfig) float64 { return float64(len(c.Bands)) },
+			set:       func(c *EQConfig, value float64) { resizeEQ(c, int(math.Round(value))) },
+		},
+	}
+	for b := 0; b < MaxEQBands; b++ {
+		b := b
+		band := func(c *EQConfig) *EQBand {
+			resizeEQ(c, max(len(c.Bands), b+1))
+			return &c.Bands[b]
+		}
+		prefix := fmt.Sprintf("band%d.", b+1)
+		p = append(p,
+			param[EQConfig]{
+				Parameter: Parameter{Name: prefix + "type", Max: float64(FilterNotch), Step: 1},
+				get:       func(c *EQConfig) float64 { return float64(band(c).Type) },
+				set:       func(c *EQConfig, value float64) { band(c).Type = FilterType(math.Round(value)) },
+			},
+			param[EQConfig]{
+				Parameter: Parameter{Name: prefix + "frequency", Min: 10, Max: 20000, Default: 1000, Unit: "Hz"},
+				get:       func(c *EQConfig) float64 { return band(c).Frequency },
+				set:       func(c *EQConfig, value float64) { band(c).Frequency = value },
+			},
+			floatParam(prefix+"gain", -24, 24, 0, "dB", func(c *EQConfig) *float32 { return &band(c).Gain }),
+			floatParam(prefix+"q", 0.1, 18, 0.707, "", func(c *EQConfig) *float32 { return &band(c).Q }),
+		)
+	}
+	return p
+}()
+
+// resizeEQ grows or shrinks the bands of c to n, adding flat peaking bands
+func resizeEQ(c *EQConfig, n int) {
+	if n <= len(c.Bands) {
+		c.Bands = c.Bands[:n]
+		return
+	}
+	bands := make([]EQBand, n)
+	copy(bands, c.Bands)
+	for b := len(c.Bands); b < n; b++ {
+		bands[b] = EQBand{Type: FilterPeaking, Frequency: 1000, Q: 0.707}
+	}
+	c.Bands = bands
+}
+
+// Type implements Processor
+func (e *equalizer) Type() string { return "eq" }
+
+// Channels implements Processor
+func (e *equalizer) Channels() int { return e.channels }
+
+// Process implements Processor
+func (e *equalizer) Process(buf []float32) { e.process(buf) }
+
+// Latency implements Processor; the equalizer adds none
+func (e *equalizer) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (e *equalizer) Reset() { e.reset() }
+
+// Parameters implements Processor
+func (e *equalizer) Parameters() []Parameter { return eqParams.parameters() }
+
+// Parameter implements Processor
+func (e *equalizer) Parameter(name string) (float64, error) { return eqParams.value(e.get(), name) }
+
+// SetParameter implements Processor
+func (e *equalizer) SetParameter(name string, value float64) error {
+	cfg, err := eqParams.update(e.get(), name, value)
+	if err == nil {
+		e.set(cfg)
+	}
+	return err
+}
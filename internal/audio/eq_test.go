@@ -0,0 +1,150 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// magnitude returns |H(e^jw)| of c at freq Hz
+func (c biquadCoeffs) magnitude(freq, sampleRate float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/sampleRate)) // z^-1
+	num := complex(c.b0, 0) + complex(c.b1, 0)*z + complex(c.b2, 0)*z*z
+	den := 1 + complex(c.a1, 0)*z + complex(c.a2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+// gainDB converts a magnitude to dB
+func gainDB(magnitude float64) float64 {
+	return 20 * math.Log10(magnitude)
+}
+
+func TestEQBandResponse(t *testing.T) {
+	const (
+		sampleRate = 48000.0
+		freq       = 1000.0
+		nyquist    = sampleRate / 2
+	)
+
+	// RBJ filters hit these gains exactly: peaking and shelf bands reach
+	// their gain at the centre and in the shelf and half of it at the shelf
+	// midpoint, low and high pass bands are Q at the corner, and the notch
+	// is silent at its centre. Points are {frequency, expected magnitude}.
+	for _, tc := range []struct {
+		name   string
+		band   EQBand
+		points [][2]float64
+	}{
+		{"peaking boost", EQBand{Type: FilterPeaking, Frequency: freq, Gain: 9, Q: 1.4},
+			[][2]float64{{freq, dbMagnitude(9)}, {0, 1}, {nyquist, 1}}},
+		{"peaking cut", EQBand{Type: FilterPeaking, Frequency: freq, Gain: -12, Q: 4},
+			[][2]float64{{freq, dbMagnitude(-12)}, {0, 1}, {nyquist, 1}}},
+		{"low shelf", EQBand{Type: FilterLowShelf, Frequency: freq, Gain: 6, Q: 0.707},
+			[][2]float64{{0, dbMagnitude(6)}, {freq, dbMagnitude(3)}, {nyquist, 1}}},
+		{"high shelf", EQBand{Type: FilterHighShelf, Frequency: freq, Gain: -8, Q: 0.707},
+			[][2]float64{{0, 1}, {freq, dbMagnitude(-4)}, {nyquist, dbMagnitude(-8)}}},
+		{"low pass", EQBand{Type: FilterLowPass, Frequency: freq, Q: 0.707},
+			[][2]float64{{0, 1}, {freq, 0.707}, {nyquist, 0}}},
+		{"resonant low pass", EQBand{Type: FilterLowPass, Frequency: freq, Q: 3},
+			[][2]float64{{0, 1}, {freq, 3}, {nyquist, 0}}},
+		{"high pass", EQBand{Type: FilterHighPass, Frequency: freq, Q: 0.707},
+			[][2]float64{{0, 0}, {freq, 0.707}, {nyquist, 1}}},
+		{"notch", EQBand{Type: FilterNotch, Frequency: freq, Q: 2},
+			[][2]float64{{0, 1}, {freq, 0}, {nyquist, 1}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.band.coefficients(sampleRate)
+			for _, point := range tc.points {
+				got := c.magnitude(point[0], sampleRate)
+				if math.Abs(got-point[1]) > 1e-6 {
+					t.Errorf("|H| at %v Hz = %v, want %v", point[0], got, point[1])
+				}
+			}
+		})
+	}
+}
+
+func TestEQBandPassband(t *testing.T) {
+	const sampleRate = 48000.0
+
+	// Butterworth low and high pass bands follow the analog response at the
+	// frequency the bilinear transform warps w to, 1/sqrt(1+W^4) and
+	// W^2/sqrt(1+W^4) with W = tan(w/2)/tan(w0/2), through pass and stop band
+	lowPass := EQBand{Type: FilterLowPass, Frequency: 1000, Q: math.Sqrt2 / 2}.coefficients(sampleRate)
+	highPass := EQBand{Type: FilterHighPass, Frequency: 1000, Q: math.Sqrt2 / 2}.coefficients(sampleRate)
+	for _, freq := range []float64{20, 100, 500, 2000, 10000, 20000} {
+		warped := math.Tan(math.Pi*freq/sampleRate) / math.Tan(math.Pi*1000/sampleRate)
+		w4 := math.Pow(warped, 4)
+		if got, want := lowPass.magnitude(freq, sampleRate), 1/math.Sqrt(1+w4); math.Abs(got-want) > 1e-6 {
+			t.Errorf("low pass: |H| at %v Hz = %v, want %v", freq, got, want)
+		}
+		if got, want := highPass.magnitude(freq, sampleRate), warped*warped/math.Sqrt(1+w4); math.Abs(got-want) > 1e-6 {
+			t.Errorf("high pass: |H| at %v Hz = %v, want %v", freq, got, want)
+		}
+	}
+
+	// A narrow notch leaves an octave away untouched
+	notch := EQBand{Type: FilterNotch, Frequency: 1000, Q: 10}.coefficients(sampleRate)
+	for _, freq := range []float64{500, 2000} {
+		if got := gainDB(notch.magnitude(freq, sampleRate)); math.Abs(got) > 0.05 {
+			t.Errorf("notch: %v dB at %v Hz, want 0", got, freq)
+		}
+	}
+}
+
+func TestEqualizerProcess(t *testing.T) {
+	const sampleRate = 48000.0
+	band := EQBand{Type: FilterPeaking, Frequency: 1000, Gain: 6, Q: 1}
+	e := newEqualizer(EQConfig{Enabled: true, Bands: []EQBand{band}}, 1, sampleRate)
+
+	// A sine at the centre comes out at the band's gain once settled
+	buf := make([]float32, int(sampleRate/2))
+	for i := range buf {
+		buf[i] = float32(0.1 * math.Sin(2*math.Pi*1000*float64(i)/sampleRate))
+	}
+	e.process(buf)
+
+	var peak float32
+	for _, sample := range buf[len(buf)/2:] {
+		peak = max(peak, float32(math.Abs(float64(sample))))
+	}
+	if got := gainDB(float64(peak) / 0.1); math.Abs(got-6) > 0.05 {
+		t.Errorf("gain at the centre = %v dB, want 6", got)
+	}
+}
+
+// dbMagnitude converts dB to a magnitude
+func dbMagnitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func TestEqualizerBandParameters(t *testing.T) {
+	e := newEqualizer(EQConfig{Enabled: true}, 2, 48000)
+	for b := 1; b <= MaxEQBands; b++ {
+		prefix := fmt.Sprintf("band%d.", b)
+		values := map[string]float64{
+			"type":      float64(FilterHighShelf),
+			"frequency": float64(100 * b),
+			"gain":      float64(b) - 12,
+			"q":         float64(b) / 4,
+		}
+		for name, value := range values {
+			if err := e.SetParameter(prefix+name, value); err != nil {
+				t.Fatalf("SetParameter(%s): %v", prefix+name, err)
+			}
+			if got, err := e.Parameter(prefix + name); err != nil || got != value {
+				t.Errorf("Parameter(%s) = %v, %v; want %v", prefix+name, got, err, value)
+			}
+		}
+
+		cfg := e.get()
+		if len(cfg.Bands) != b {
+			t.Fatalf("after setting band %d: %d bands, want %d", b, len(cfg.Bands), b)
+		}
+		want := EQBand{Type: FilterHighShelf, Frequency: float64(100 * b), Gain: float32(b) - 12, Q: float32(b) / 4}
+		if got := cfg.Bands[b-1]; got != want {
+			t.Errorf("Bands[%d] = %+v, want %+v", b-1, got, want)
+		}
+	}
+}
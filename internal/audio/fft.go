@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fft is an in-place radix-2 fast Fourier transform of a fixed power of
+// two size, with its twiddle factors and bit-reversal permutation
+// precomputed so transforming allocates nothing
+type fft struct {
+	size     int
+	twiddles []complex128 // exp(-2πik/size) for k below size/2
+	reversed []int        // Bit-reversed index of every index
+}
+
+// newFFT creates a transform of size points; size must be a power of two
+func newFFT(size int) *fft {
+	f := &fft{
+		size:     size,
+		twiddles: make([]complex128, size/2),
+		reversed: make([]int, size),
+	}
+	for k := range f.twiddles {
+		f.twiddles[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(size))
+	}
+	shift := bits.UintSize - bits.Len(uint(size-1))
+	for i := range f.reversed {
+		f.reversed[i] = int(bits.Reverse(uint(i)) >> shift)
+	}
+	return f
+}
+
+// forward replaces x by its discrete Fourier transform
+func (f *fft) forward(x []complex128) {
+	f.transform(x, false)
+}
+
+// inverse replaces x by its inverse discrete Fourier transform, scaled by
+// size so forward followed by inverse multiplies by size
+func (f *fft) inverse(x []complex128) {
+	f.transform(x, true)
+}
+
+// transform runs the Cooley-Tukey butterflies over x
+func (f *fft) transform(x []complex128, inverse bool) {
+	for i, j := range f.reversed {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for span := 1; span < f.size; span *= 2 {
+		stride := f.size / (2 * span)
+		for start := 0; start < f.size; start += 2 * span {
+			for k := 0; k < span; k++ {
+				w := f.twiddles[k*stride]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				a, b := x[start+k], w*x[start+k+span]
+				x[start+k], x[start+k+span] = a+b, a-b
+			}
+		}
+	}
+}
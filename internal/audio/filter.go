@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// DCBlockerCutoff is the corner frequency of the DC blocker in Hz, low
+// enough to leave every audible frequency alone
+const DCBlockerCutoff = 5.0
+
+// InputFilterConfig configures the filters that clean up an input's
+// captured samples before they are buffered
+type InputFilterConfig struct {
+	DCBlocker      bool    // Remove DC offset
+	HighPass       bool    // Remove rumble below HighPassCutoff
+	HighPassCutoff float64 // Corner frequency of the high-pass filter in Hz
+}
+
+// DefaultInputFilterConfig returns the DC blocker on and the high-pass
+// filter off, set to cut below speech
+func DefaultInputFilterConfig() InputFilterConfig {
+	return InputFilterConfig{
+		DCBlocker:      true,
+		HighPassCutoff: 80,
+	}
+}
+
+// dcBlockerState is the memory of a DC blocker for one channel
+type dcBlockerState struct {
+	x1, y1 float64
+}
+
+// inputFilter removes DC offset with a one-pole DC blocker and rumble with
+// a second-order Butterworth high-pass filter. It runs in the input
+// callback at the device rate, so levels are metered and headroom is
+// spent without either.
+//
+// Settings are published through config and picked up by process; prepare
+// sets it up for a stream before the stream starts.
+type inputFilter struct {
+	config atomic.Value // InputFilterConfig
+
+	// Input callback state: the stream layout, the settings in use with
+	// their derived coefficients and the memory of each channel
+	channels   int
+	sampleRate float64
+	active     InputFilterConfig
+	dcCoeff    float64
+	dcState    []dcBlockerState
+	hpCoeffs   biquadCoeffs
+	hpState    []biquadState
+}
+
+// newInputFilter creates an input filter; it runs once prepared
+func newInputFilter(cfg InputFilterConfig) *inputFilter {
+	f := &inputFilter{}
+	f.config.Store(cfg)
+	f.active = cfg
+	return f
+}
+
+// set publishes new settings, applied from the next buffer on
+func (f *inputFilter) set(cfg InputFilterConfig) {
+	f.config.Store(cfg)
+}
+
+// get returns the current settings
+func (f *inputFilter) get() InputFilterConfig {
+	return f.config.Load().(InputFilterConfig)
+}
+
+// prepare sets the filter up for a stream of channels channels at
+// sampleRate, clearing its memory
+func (f *inputFilter) prepare(channels int, sampleRate float64) {
+	f.channels = channels
+	f.sampleRate = sampleRate
+	f.dcState = make([]dcBlockerState, channels)
+	f.hpState = make([]biquadState, channels)
+	f.configure(f.get())
+}
+
+// configure derives the coefficients for cfg, clearing the memory of a
+// filter being switched on
+func (f *inputFilter) configure(cfg InputFilterConfig) {
+	if cfg.DCBlocker && !f.active.DCBlocker {
+		clear(f.dcState)
+	}
+	if cfg.HighPass && !f.active.HighPass {
+		clear(f.hpState)
+	}
+	f.active = cfg
+	if f.sampleRate == 0 {
+		return
+	}
+
+	f.dcCoeff = math.Exp(-2 * math.Pi * DCBlockerCutoff / f.sampleRate)
+	highPass := EQBand{Type: FilterHighPass, Frequency: cfg.HighPassCutoff, Q: math.Sqrt2 / 2}
+	f.hpCoeffs = highPass.coefficients(f.sampleRate)
+}
+
+// enabled picks up new settings and reports whether process has work to do
+func (f *inputFilter) enabled() bool {
+	if cfg := f.get(); cfg != f.active {
+		f.configure(cfg)
+	}
+	return f.channels > 0 && (f.active.DCBlocker || f.active.HighPass)
+}
+
+// process filters src, interleaved with the prepared channel count, into
+// dst, which may be src itself; call it only after enabled returned true
+func (f *inputFilter) process(dst, src []float32) {
+	ch := f.channels
+	for i, sample := range src {
+		x := float64(sample)
+		if f.active.DCBlocker {
+			s := &f.dcState[i%ch]
+			y := x - s.x1 + f.dcCoeff*s.y1
+			s.x1, s.y1 = x, y
+			x = y
+		}
+		if f.active.HighPass {
+			x = f.hpCoeffs.process(&f.hpState[i%ch], x)
+		}
+		dst[i] = float32(x)
+	}
+}
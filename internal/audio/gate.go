@@ -0,0 +1,192 @@
+package audio
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// gateDetectorRelease is how fast the gate's level detector falls after a
+// peak, bridging the zero crossings of low notes
+const gateDetectorRelease = 10 * time.Millisecond
+
+// GateConfig configures the noise gate of an input
+type GateConfig struct {
+	Enabled    bool
+	Threshold  float32       // Level in dBFS above which the gate opens
+	Hysteresis float32       // The gate closes this many dB below the threshold
+	Hold       time.Duration // Time the gate stays open after the level drops
+	Attack     time.Duration // Time to open fully
+	Release    time.Duration // Time to close fully
+	Range      float32       // Attenuation in dB while closed, e.g. -80 to gate or -10 to expand
+}
+
+// DefaultGateConfig returns settings that keep room noise out between sentences
+func DefaultGateConfig() GateConfig {
+	return GateConfig{
+		Threshold:  -45,
+		Hysteresis: 6,
+		Hold:       100 * time.Millisecond,
+		Attack:     1 * time.Millisecond,
+		Release:    150 * time.Millisecond,
+		Range:      -80,
+	}
+}
+
+// gate is a noise gate with hysteresis and hold. A peak detector shared by
+// all channels opens it above the threshold; it closes once the level has
+// stayed below the threshold minus the hysteresis for the hold time. The
+// gain moves dB-linearly between unity and the range, taking the attack
+// time to open and the release time to close.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type gate struct {
+	config     atomic.Value // GateConfig
+	sampleRate float64
+	channels   int
+
+	// Output callback state: the settings in use with their derived
+	// coefficients, the detector level, the hold countdown and the gain
+	active       GateConfig
+	openLevel    float32
+	closeLevel   float32
+	holdFrames   int
+	floor        float32
+	attackStep   float32 // Per-frame gain multiplier while opening
+	releaseStep  float32 // Per-frame gain multiplier while closing
+	detectorFall float32
+	detector     float32
+	holdLeft     int
+	gain         float32
+
+	open atomic.Bool // Published gate state
+}
+
+// newGate creates a gate for interleaved audio at sampleRate
+func newGate(cfg GateConfig, channels int, sampleRate float64) *gate {
+	g := &gate{sampleRate: sampleRate, channels: channels}
+	g.config.Store(cfg)
+	g.configure(cfg)
+	g.reset()
+	return g
+}
+
+// set publishes new settings, applied from the next buffer on
+func (g *gate) set(cfg GateConfig) {
+	g.config.Store(cfg)
+}
+
+// get returns the current settings
+func (g *gate) get() GateConfig {
+	return g.config.Load().(GateConfig)
+}
+
+// configure derives the levels and coefficients for cfg
+func (g *gate) configure(cfg GateConfig) {
+	g.active = cfg
+	g.openLevel = dbToGain(cfg.Threshold)
+	g.closeLevel = dbToGain(cfg.Threshold - max(cfg.Hysteresis, 0))
+	g.holdFrames = int(cfg.Hold.Seconds() * g.sampleRate)
+	g.floor = dbToGain(min(cfg.Range, 0))
+	g.attackStep = float32(math.Pow(float64(1/g.floor), 1/max(1, cfg.Attack.Seconds()*g.sampleRate)))
+	g.releaseStep = float32(math.Pow(float64(g.floor), 1/max(1, cfg.Release.Seconds()*g.sampleRate)))
+	g.detectorFall = float32(math.Exp(-1 / (gateDetectorRelease.Seconds() * g.sampleRate)))
+}
+
+// reset opens the gate fully
+func (g *gate) reset() {
+	g.detector = 0
+	g.holdLeft = 0
+	g.gain = 1
+	g.open.Store(true)
+}
+
+// process gates buf in place
+func (g *gate) process(buf []float32) {
+	channels := g.channels
+	if cfg := g.get(); cfg != g.active {
+		g.configure(cfg)
+	}
+	if !g.active.Enabled {
+		if g.gain != 1 {
+			g.reset()
+		}
+		return
+	}
+
+	open := g.open.Load()
+	for f := 0; f < len(buf)/channels; f++ {
+		frame := buf[f*channels : f*channels+channels]
+
+		peak := float32(0)
+		for _, sample := range frame {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+		g.detector = max(peak, g.detector*g.detectorFall)
+
+		switch {
+		case g.detector >= g.openLevel:
+			open = true
+			g.holdLeft = g.holdFrames
+		case !open:
+		case g.detector >= g.closeLevel:
+			g.holdLeft = g.holdFrames
+		case g.holdLeft > 0:
+			g.holdLeft--
+		default:
+			open = false
+		}
+
+		if open {
+			g.gain = min(g.gain*g.attackStep, 1)
+		} else {
+			g.gain = max(g.gain*g.releaseStep, g.floor)
+		}
+		for i := range frame {
+			frame[i] *= g.gain
+		}
+	}
+	g.open.Store(open)
+}
+
+// gateParams are the gate's processor parameters
+var gateParams = params[GateConfig]{
+	boolParam("enabled", true, func(c *GateConfig) *bool { return &c.Enabled }),
+	floatParam("threshold", -90, 0, -45, "dB", func(c *GateConfig) *float32 { return &c.Threshold }),
+	floatParam("hysteresis", 0, 20, 6, "dB", func(c *GateConfig) *float32 { return &c.Hysteresis }),
+	msParam("hold", 0, 2000, 100, func(c *GateConfig) *time.Duration { return &c.Hold }),
+	msParam("attack", 0.1, 500, 1, func(c *GateConfig) *time.Duration { return &c.Attack }),
+	msParam("release", 1, 5000, 150, func(c *GateConfig) *time.Duration { return &c.Release }),
+	floatParam("range", -90, 0, -80, "dB", func(c *GateConfig) *float32 { return &c.Range }),
+}
+
+// Type implements Processor
+func (g *gate) Type() string { return "gate" }
+
+// Channels implements Processor
+func (g *gate) Channels() int { return g.channels }
+
+// Process implements Processor
+func (g *gate) Process(buf []float32) { g.process(buf) }
+
+// Latency implements Processor; the gate adds none
+func (g *gate) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (g *gate) Reset() { g.reset() }
+
+// Parameters implements Processor
+func (g *gate) Parameters() []Parameter { return gateParams.parameters() }
+
+// Parameter implements Processor
+func (g *gate) Parameter(name string) (float64, error) { return gateParams.value(g.get(), name) }
+
+// SetParameter implements Processor
+func (g *gate) SetParameter(name string, value float64) error {
+	cfg, err := gateParams.update(g.get(), name, value)
+	if err == nil {
+		g.set(cfg)
+	}
+	return err
+}
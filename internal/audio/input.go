@@ -0,0 +1,417 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// InputFadeTime is how long an input takes to fade in or out when it is
+	// attached to or detached from a running mixer
+	InputFadeTime = 50 * time.Millisecond
+
+	// InputRampTime is how long muting, soloing or inverting an input takes
+	// to ramp in, avoiding clicks
+	InputRampTime = 10 * time.Millisecond
+)
+
+// InputConfig describes one input strip of the mixer
+type InputConfig struct {
+	Name   string      // Display name, e.g. "Microphone" or "Game"
+	Device *DeviceInfo // Capture device (microphone, line input, loopback device)
+	Gain   float32     // 0.0 to 2.0 (0% to 200%)
+	Pan    float32     // -1.0 (left) to 1.0 (right), stereo mixes only
+
+	Mute           bool // Silence the input
+	Solo           bool // While any input is soloed, only soloed inputs are heard
+	PolarityInvert bool // Flip the input's polarity
+
+	Filter     InputFilterConfig // DC blocker and rumble filter, run as samples are captured
+	Denoiser   DenoiserConfig    // Noise suppression, the first processing step after buffering
+	Gate       GateConfig        // Noise gate after noise suppression
+	EQ         EQConfig          // Tone shaping after the gate
+	AGC        AGCConfig         // Slow leveling towards a target after the EQ
+	Compressor CompressorConfig  // Fast leveling after the AGC
+	DeEsser    DeEsserConfig     // Sibilance control after the compressor, before gain and pan
+
+	// Processing order after buffering. Built-in processors are named by
+	// type ("denoiser", "gate", "eq", "agc", "compressor", "deesser");
+	// those left out follow the listed slots in that order, so nil keeps
+	// the default.
+	Inserts []InsertConfig
+
+	// Send levels to the mixer's aux buses in order, 0.0 to 2.0; missing
+	// ones are 0
+	Sends []float32
+
+	// How long to hold the input back after processing, up to
+	// MaxInputDelay, to line it up with the others
+	Delay time.Duration
+
+	// Device channels to mixer channels; nil captures the first mixer
+	// channel count of device channels through DefaultChannelMap
+	ChannelMap ChannelMap
+}
+
+// inputStrip is a single mixer input: its stream, ring buffer, gain and level
+type inputStrip struct {
+	name      string
+	device    *DeviceInfo
+	configMap ChannelMap // Requested channel map, nil for the default
+	stream    Stream
+	buffer    *AudioBuffer // Holds frames of mixer channels at the mixer rate
+
+	gain  atomic.Value // float32
+	pan   atomic.Value // float32
+	level atomic.Value // float32
+
+	// Per-channel gain ramps towards gain and pan, only touched by the output callback
+	smoothers []gainSmoother
+
+	// Send levels (float32) to every aux bus, with their ramps, only
+	// touched by the output callback
+	sends         []atomic.Value
+	sendSmoothers []gainSmoother
+
+	// Cleanup of captured samples, run by the input callback
+	filter   *inputFilter
+	filtered []float32 // Filter output when the samples need no channel mapping
+
+	// Processing before gain and pan, run by the output callback through
+	// chain, which holds the built-in processors below and added ones
+	chain      *InsertChain
+	denoiser   *denoiser
+	gate       *gate
+	eq         *equalizer
+	agc        *agc
+	compressor *compressor
+	deEsser    *deEsser
+	delay      *delayLine // Alignment delay after the chain
+
+	// Mute, solo and polarity switches; the output callback ramps towards
+	// them with mute (0 to 1) and polarity (-1 to 1), which only it touches
+	muted    atomic.Bool
+	soloed   atomic.Bool
+	inverted atomic.Bool
+	mute     float32
+	polarity float32
+
+	// Conversion from the device stream to the mixer layout, set up when
+	// the stream is opened and only touched by the input callback: channels
+	// of the device stream, channelMap to the mixer channels (mapped is nil
+	// for an identity map) and resampler to the mixer rate (nil when the
+	// device runs at the mixer rate). streamInfo publishes the setup.
+	channels   int
+	channelMap ChannelMap
+	mapped     []float32
+	resampler  *resampler
+	resampled  []float32
+	streamInfo atomic.Value // StreamInfo
+
+	// Clock drift compensation (nil when disabled); drift is only touched
+	// by the output callback, driftRatio publishes its current ratio
+	drift      *driftCompensator
+	driftRatio atomic.Value // float64
+
+	// Fade state: active is the fade target, fade is only touched by the
+	// output callback, faded is closed once a detached input is silent
+	active    atomic.Bool
+	fade      float32
+	faded     chan struct{}
+	fadedOnce sync.Once
+}
+
+// newInputStrip creates an input strip with a ring buffer sized for the mixer
+func newInputStrip(cfg InputConfig, mixerConfig *MixerConfig) (*inputStrip, error) {
+	bufferSize := mixerConfig.BufferSize
+	channels := mixerConfig.Channels
+
+	strip := &inputStrip{
+		name:       cfg.Name,
+		device:     cfg.Device,
+		configMap:  cfg.ChannelMap,
+		buffer:     NewAudioBuffer(bufferSize * channels * 10),
+		smoothers:  make([]gainSmoother, channels),
+		filter:     newInputFilter(cfg.Filter),
+		denoiser:   newDenoiser(cfg.Denoiser, channels, mixerConfig.SampleRate),
+		gate:       newGate(cfg.Gate, channels, mixerConfig.SampleRate),
+		eq:         newEqualizer(cfg.EQ, channels, mixerConfig.SampleRate),
+		agc:        newAGC(cfg.AGC, channels, mixerConfig.SampleRate),
+		compressor: newCompressor(cfg.Compressor, channels, mixerConfig.SampleRate),
+		deEsser:    newDeEsser(cfg.DeEsser, channels, mixerConfig.SampleRate),
+		delay:      newDelayLine(channels, mixerConfig.SampleRate, bufferSize, durationFrames(cfg.Delay, mixerConfig.SampleRate)),
+		fade:       1,
+		faded:      make(chan struct{}),
+	}
+	builtin := []Processor{strip.denoiser, strip.gate, strip.eq, strip.agc, strip.compressor, strip.deEsser}
+	chain, err := newInsertChain(cfg.Inserts, builtin, channels, mixerConfig.SampleRate, bufferSize)
+	if err != nil {
+		return nil, err
+	}
+	strip.chain = chain
+
+	strip.muted.Store(cfg.Mute)
+	strip.soloed.Store(cfg.Solo)
+	strip.inverted.Store(cfg.PolarityInvert)
+	strip.mute, strip.polarity = strip.switchTargets(false)
+	strip.buffer.SetUnderrunFill(mixerConfig.UnderrunFill, channels)
+	if mixerConfig.DriftCompensation {
+		targetFill := mixerConfig.TargetLatency.Seconds() * mixerConfig.SampleRate
+		if targetFill <= 0 {
+			targetFill = float64(2 * bufferSize)
+		}
+		strip.drift = newDriftCompensator(channels, bufferSize, targetFill)
+	}
+
+	strip.active.Store(true)
+	strip.gain.Store(clampGain(cfg.Gain))
+	strip.pan.Store(clampPan(cfg.Pan))
+	strip.sends = make([]atomic.Value, len(mixerConfig.AuxBuses))
+	strip.sendSmoothers = make([]gainSmoother, len(mixerConfig.AuxBuses))
+	for b := range strip.sends {
+		level := float32(0)
+		if b < len(cfg.Sends) {
+			level = cfg.Sends[b]
+		}
+		strip.sends[b].Store(clampGain(level))
+	}
+	strip.level.Store(float32(0))
+	strip.driftRatio.Store(float64(1))
+	strip.streamInfo.Store(StreamInfo{Device: cfg.Device, MixerRate: mixerConfig.SampleRate})
+	return strip, nil
+}
+
+// read fills out with the strip's next samples, compensating clock drift
+// against the output device when enabled
+func (s *inputStrip) read(out []float32) {
+	if s.drift == nil {
+		s.buffer.Read(out)
+		return
+	}
+
+	fill := float64(s.buffer.Available()) / float64(s.drift.channels)
+	s.driftRatio.Store(s.drift.update(fill))
+	s.drift.process(s.buffer, out)
+}
+
+// condition maps captured samples to the mixer channels and runs the
+// input filter over them, still at the device rate
+func (s *inputStrip) condition(in []float32) []float32 {
+	if s.mapped != nil {
+		frames := len(in) / s.channels
+		if need := frames * s.channelMap.Destinations(); need > len(s.mapped) {
+			s.mapped = make([]float32, need)
+		}
+		out := s.mapped[:frames*s.channelMap.Destinations()]
+		s.channelMap.Apply(out, in)
+		in = out
+	}
+
+	if !s.filter.enabled() {
+		return in
+	}
+	out := in
+	if s.mapped == nil {
+		// Leave the device's buffer alone
+		if len(in) > len(s.filtered) {
+			s.filtered = make([]float32, len(in))
+		}
+		out = s.filtered[:len(in)]
+	}
+	s.filter.process(out, in)
+	return out
+}
+
+// write stores conditioned samples in the ring buffer, converting them to
+// the mixer rate first when needed
+func (s *inputStrip) write(in []float32) {
+	if s.resampler == nil {
+		s.buffer.Write(in)
+		return
+	}
+
+	s.resampler.push(in)
+	mixerChannels := s.channelMap.Destinations()
+	for {
+		frames := s.resampler.pull(s.resampled)
+		if frames == 0 {
+			return
+		}
+		s.buffer.Write(s.resampled[:frames*mixerChannels])
+	}
+}
+
+// resolveChannelMap returns the channel map to open the strip's device with
+func (s *inputStrip) resolveChannelMap(mixerChannels int) (ChannelMap, error) {
+	if s.configMap == nil {
+		channels := min(mixerChannels, s.device.MaxInputChannels)
+		if channels < 1 {
+			return nil, fmt.Errorf("device %s has no input channels", s.device.Name)
+		}
+		return DefaultChannelMap(channels, mixerChannels), nil
+	}
+
+	if err := s.configMap.Validate(s.device.MaxInputChannels, mixerChannels); err != nil {
+		return nil, err
+	}
+	return s.configMap, nil
+}
+
+// prepareStream sets up channel mapping and sample rate conversion for a
+// stream opened with params; call it before the stream is started
+func (s *inputStrip) prepareStream(params StreamParams, channelMap ChannelMap, mixerRate float64) {
+	mixerChannels := channelMap.Destinations()
+
+	s.channels = params.Channels
+	s.channelMap = channelMap
+	s.mapped = nil
+	if !channelMap.IsIdentity() {
+		s.mapped = make([]float32, params.FramesPerBuffer*mixerChannels)
+	}
+
+	s.filtered = make([]float32, params.FramesPerBuffer*mixerChannels)
+	s.filter.prepare(mixerChannels, params.SampleRate)
+
+	s.resampler = nil
+	s.resampled = nil
+	if params.SampleRate != mixerRate {
+		s.resampler = newResampler(mixerChannels, params.SampleRate, mixerRate, params.FramesPerBuffer)
+		s.resampled = make([]float32, s.resampler.maxOutput(params.FramesPerBuffer)*mixerChannels)
+	}
+
+	s.streamInfo.Store(StreamInfo{
+		Device:     s.device,
+		SampleRate: params.SampleRate,
+		MixerRate:  mixerRate,
+		ChannelMap: channelMap,
+	})
+}
+
+// closeStream closes the strip's stream, if open
+func (s *inputStrip) closeStream() error {
+	if s.stream == nil {
+		return nil
+	}
+
+	err := s.stream.Close()
+	s.stream = nil
+	info := s.streamInfo.Load().(StreamInfo)
+	s.streamInfo.Store(StreamInfo{Device: s.device, MixerRate: info.MixerRate})
+	return err
+}
+
+// reset clears buffered audio and meters before the mixer starts again
+func (s *inputStrip) reset() {
+	s.buffer.Reset()
+	s.level.Store(float32(0))
+	s.fade = 1
+	s.mute, s.polarity = s.switchTargets(false)
+	for c := range s.smoothers {
+		s.smoothers[c].reset()
+	}
+	for b := range s.sendSmoothers {
+		s.sendSmoothers[b].reset()
+	}
+	s.chain.Reset()
+	s.delay.reset()
+	if s.drift != nil {
+		s.drift.reset()
+	}
+	s.driftRatio.Store(float64(1))
+}
+
+// channelGains fills gains with the strip's gain for every mixer channel,
+// panned between left and right in a stereo mix
+func (s *inputStrip) channelGains(gains []float32, law PanLaw) {
+	gain := s.gain.Load().(float32)
+	for c := range gains {
+		gains[c] = gain
+	}
+
+	if len(gains) == 2 {
+		left, right := law.Gains(s.pan.Load().(float32))
+		gains[0] *= left
+		gains[1] *= right
+	}
+}
+
+// switchTargets returns the mute and polarity ramp targets for the strip's
+// switches; soloActive reports whether any input of the mixer is soloed
+func (s *inputStrip) switchTargets(soloActive bool) (mute, polarity float32) {
+	mute, polarity = 1, 1
+	if s.muted.Load() || (soloActive && !s.soloed.Load()) {
+		mute = 0
+	}
+	if s.inverted.Load() {
+		polarity = -1
+	}
+	return mute, polarity
+}
+
+// mixInto adds the strip's samples to mix, ramping each channel's gain to
+// its target in gains over rampFrames frames, while moving the
+// attach/detach fade by fadeStep per sample and the mute and polarity ramps
+// by rampStep per sample towards their targets
+func (s *inputStrip) mixInto(mix, in, gains []float32, rampFrames int, fadeStep, rampStep float32, soloActive bool) {
+	fadeTarget := float32(0)
+	if s.active.Load() {
+		fadeTarget = 1
+	}
+	muteTarget, polarityTarget := s.switchTargets(soloActive)
+	ch := len(gains)
+
+	settled := true
+	for c, gain := range gains {
+		s.smoothers[c].setTarget(gain, rampFrames)
+		settled = settled && s.smoothers[c].settled()
+	}
+
+	fade, mute, polarity := s.fade, s.mute, s.polarity
+	if fade == 0 && fadeTarget == 0 {
+		s.fadedOnce.Do(func() { close(s.faded) })
+		return
+	}
+
+	if settled && fade == fadeTarget && mute == muteTarget && polarity == polarityTarget {
+		gain := mute * polarity
+		if gain == 0 {
+			return
+		}
+		for i := range mix {
+			mix[i] += in[i] * gains[i%ch] * gain
+		}
+		return
+	}
+
+	for f := 0; f < len(mix)/ch; f++ {
+		for c := 0; c < ch; c++ {
+			i := f*ch + c
+			fade = approach(fade, fadeTarget, fadeStep)
+			mute = approach(mute, muteTarget, rampStep)
+			polarity = approach(polarity, polarityTarget, 2*rampStep) // Passes through zero
+			mix[i] += in[i] * s.smoothers[c].next() * fade * mute * polarity
+		}
+	}
+	s.fade, s.mute, s.polarity = fade, mute, polarity
+}
+
+// approach moves value towards target by at most step
+func approach(value, target, step float32) float32 {
+	if value < target {
+		return min(value+step, target)
+	}
+	return max(value-step, target)
+}
+
+// clampGain limits a gain value to the supported 0.0 to 2.0 range
+func clampGain(gain float32) float32 {
+	if gain < 0 {
+		return 0
+	}
+	if gain > 2.0 {
+		return 2.0
+	}
+	return gain
+}
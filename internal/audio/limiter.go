@@ -0,0 +1,186 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// LimiterConfig configures the look-ahead peak limiter on the master bus
+type LimiterConfig struct {
+	Enabled   bool
+	Ceiling   float32       // Highest output peak in dBFS, e.g. -1.0
+	Release   time.Duration // Time to recover from gain reduction
+	LookAhead time.Duration // How early peaks are seen; delays the output by as much
+}
+
+// DefaultLimiterConfig returns the default limiter settings
+func DefaultLimiterConfig() LimiterConfig {
+	return LimiterConfig{
+		Enabled:   true,
+		Ceiling:   -1.0,
+		Release:   100 * time.Millisecond,
+		LookAhead: 5 * time.Millisecond,
+	}
+}
+
+// limiter is a brickwall look-ahead peak limiter. For every frame it works
+// out the gain that keeps the frame's peak under the ceiling, holds the
+// minimum of that over the look-ahead window and smooths it with a moving
+// average of the same length. Delaying the audio by the window length
+// minus one frame then lines every peak up with a gain that is already low
+// enough, so the output never exceeds the ceiling while gain changes stay
+// smooth. Recovery follows a one-pole release.
+//
+// It is only used from the output callback and is not safe for concurrent use.
+type limiter struct {
+	channels     int
+	length       int     // Look-ahead window in frames
+	ceilingDB    float32 // ceiling in dBFS, to notice changes
+	ceiling      float32
+	releaseCoeff float32
+
+	// Audio delay line of length-1 frames
+	delay    []float32
+	delayPos int
+
+	// Sliding window minimum of the required gain (monotonic deque)
+	minValues []float32
+	minFrames []int
+	minHead   int
+	minCount  int
+	frame     int
+
+	// Moving average of the windowed minimum
+	average    []float32
+	averagePos int
+	averageSum float64
+
+	gain float32
+}
+
+// newLimiter creates a limiter for interleaved audio at sampleRate
+func newLimiter(cfg LimiterConfig, channels int, sampleRate float64) *limiter {
+	length := max(1, int(cfg.LookAhead.Seconds()*sampleRate))
+
+	l := &limiter{
+		channels:     channels,
+		length:       length,
+		releaseCoeff: smoothingCoeff(cfg.Release, sampleRate),
+		delay:        make([]float32, (length-1)*channels),
+		minValues:    make([]float32, length),
+		minFrames:    make([]int, length),
+		average:      make([]float32, length),
+	}
+	l.setCeiling(cfg.Ceiling)
+	l.reset()
+	return l
+}
+
+// setCeiling changes the highest output peak in dBFS
+func (l *limiter) setCeiling(ceiling float32) {
+	if ceiling != l.ceilingDB || l.ceiling == 0 {
+		l.ceilingDB = ceiling
+		l.ceiling = dbToGain(ceiling)
+	}
+}
+
+// reset clears the delay line and releases all gain reduction
+func (l *limiter) reset() {
+	for i := range l.delay {
+		l.delay[i] = 0
+	}
+	for i := range l.average {
+		l.average[i] = 1
+	}
+	l.delayPos = 0
+	l.minHead, l.minCount, l.frame = 0, 0, 0
+	l.averagePos = 0
+	l.averageSum = float64(l.length)
+	l.gain = 1
+}
+
+// process limits buf in place and returns the lowest gain applied
+func (l *limiter) process(buf []float32) float32 {
+	ch := l.channels
+	lowest := float32(1)
+
+	for f := 0; f < len(buf)/ch; f++ {
+		frame := buf[f*ch : f*ch+ch]
+
+		// Gain this frame needs to stay under the ceiling
+		peak := float32(0)
+		for _, sample := range frame {
+			peak = max(peak, float32(math.Abs(float64(sample))))
+		}
+		required := float32(1)
+		if peak > l.ceiling {
+			required = l.ceiling / peak
+		}
+
+		// Smooth attack: windowed minimum, then moving average
+		target := float32(l.averageMin(required))
+
+		// Instant attack (already smoothed), one-pole release
+		if target < l.gain {
+			l.gain = target
+		} else {
+			l.gain += (target - l.gain) * l.releaseCoeff
+		}
+		lowest = min(lowest, l.gain)
+
+		// Delay the audio to line up with its gain and apply it
+		if len(l.delay) > 0 {
+			delayed := l.delay[l.delayPos : l.delayPos+ch]
+			for c := range frame {
+				frame[c], delayed[c] = delayed[c], frame[c]
+			}
+			l.delayPos = (l.delayPos + ch) % len(l.delay)
+		}
+		for c := range frame {
+			frame[c] = clampSample(frame[c] * l.gain)
+		}
+	}
+	return lowest
+}
+
+// averageMin pushes a required gain and returns the moving average of the
+// windowed minimum
+func (l *limiter) averageMin(required float32) float64 {
+	n := len(l.minValues)
+
+	// Drop larger values from the back, then expired ones from the front
+	for l.minCount > 0 {
+		back := (l.minHead + l.minCount - 1) % n
+		if l.minValues[back] < required {
+			break
+		}
+		l.minCount--
+	}
+	back := (l.minHead + l.minCount) % n
+	l.minValues[back] = required
+	l.minFrames[back] = l.frame
+	l.minCount++
+	for l.minFrames[l.minHead] <= l.frame-l.length {
+		l.minHead = (l.minHead + 1) % n
+		l.minCount--
+	}
+	l.frame++
+
+	held := l.minValues[l.minHead]
+	l.averageSum += float64(held) - float64(l.average[l.averagePos])
+	l.average[l.averagePos] = held
+	l.averagePos = (l.averagePos + 1) % n
+	if l.averagePos == 0 {
+		// Recompute once per window so rounding errors cannot build up
+		l.averageSum = 0
+		for _, v := range l.average {
+			l.averageSum += float64(v)
+		}
+	}
+	return l.averageSum / float64(n)
+}
+
+// clampSample hard-limits a sample to the -1.0 to 1.0 range as a last resort
+func clampSample(sample float32) float32 {
+	return max(-1, min(sample, 1))
+}
@@ -1,19 +1,14 @@
-//go:build cgo
-// +build cgo
-
 package audio
 
 import (
 	"fmt"
 	"runtime"
-
-	"github.com/gordonklaus/portaudio"
 )
 
 // LoopbackDevice represents a virtual loopback audio device
 type LoopbackDevice struct {
 	Name   string
-	Device *portaudio.DeviceInfo
+	Device *DeviceInfo
 }
 
 // FindLoopbackDevice finds a suitable loopback/virtual device for output
@@ -25,9 +20,9 @@ func FindLoopbackDevice(dm *DeviceManager) (*LoopbackDevice, error) {
 		return nil, fmt.Errorf("device manager not initialized")
 	}
 
-	devices, err := portaudio.Devices()
+	devices, err := dm.ListDevices()
 	if err != nil {
-		return nil, fmt.Errorf("failed to enumerate devices: %w", err)
+		return nil, err
 	}
 
 	// Platform-specific device names to search for
@@ -81,9 +76,9 @@ func GetLoopbackDeviceByName(dm *DeviceManager, name string) (*LoopbackDevice, e
 		return nil, fmt.Errorf("device manager not initialized")
 	}
 
-	devices, err := portaudio.Devices()
+	devices, err := dm.ListDevices()
 	if err != nil {
-		return nil, fmt.Errorf("failed to enumerate devices: %w", err)
+		return nil, err
 	}
 
 	for _, dev := range devices {
@@ -104,9 +99,9 @@ func ListLoopbackDevices(dm *DeviceManager) ([]*LoopbackDevice, error) {
 		return nil, fmt.Errorf("device manager not initialized")
 	}
 
-	devices, err := portaudio.Devices()
+	devices, err := dm.ListDevices()
 	if err != nil {
-		return nil, fmt.Errorf("failed to enumerate devices: %w", err)
+		return nil, err
 	}
 
 	var loopbackDevices []*LoopbackDevice
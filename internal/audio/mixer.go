@@ -1,6 +1,3 @@
-//go:build cgo
-// +build cgo
-
 package audio
 
 import (
@@ -9,8 +6,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/gordonklaus/portaudio"
 )
 
 const (
@@ -18,7 +13,8 @@ const (
 	DefaultSampleRate = 48000
 	DefaultBufferSize = 512
 	DefaultChannels   = 2
-	MaxChannels       = 2
+	MaxChannels       = 32
+	MaxInputs         = 8
 	MinLatencyMs      = 10
 	MaxLatencyMs      = 100
 )
@@ -28,55 +24,116 @@ type MixerConfig struct {
 	SampleRate       float64
 	BufferSize       int
 	Channels         int
-	Input1Device     *portaudio.DeviceInfo // Microphone/Line input
-	Input2Device     *portaudio.DeviceInfo // System audio (from loopback device)
-	OutputDevice     *portaudio.DeviceInfo // Virtual output device (BlackHole/VB-Cable)
-	UseVirtualOutput bool                  // If true, output goes to virtual device instead of speakers
-	Input1Gain       float32               // 0.0 to 2.0 (0% to 200%)
-	Input2Gain       float32
-	MasterGain       float32
+	Inputs           []InputConfig // Microphones, line inputs, loopback devices (up to MaxInputs)
+	OutputDevice     *DeviceInfo   // Virtual output device (BlackHole/VB-Cable)
+	UseVirtualOutput bool          // If true, output goes to virtual device instead of speakers
+	MasterGain       float32       // 0.0 to 2.0 (0% to 200%)
+	Backend          Backend       // Stream backend, nil selects DefaultBackend()
+	OutputChannelMap ChannelMap    // Mixer channels to output device channels, nil selects DefaultChannelMap
+	UnderrunFill     UnderrunFill  // What an input plays when its buffer runs dry
+	PanLaw           PanLaw        // How input pan positions split between left and right
+	GainRampTime     time.Duration // How long gain and pan changes take, 0 spreads them over one buffer
+	Limiter          LimiterConfig // Look-ahead peak limiter on the master bus
+	Ducking          DuckingConfig // Turns inputs down while another one is active
+	MasterEQ         EQConfig      // Tone shaping on the master bus, before the limiter
+
+	// Master bus processing order before the limiter, which always comes
+	// last; "eq" names the master EQ, which follows the listed slots when
+	// left out
+	MasterInserts []InsertConfig
+
+	// Effect buses the inputs send to, returning to the master bus before
+	// master gain (up to MaxAuxBuses)
+	AuxBuses []AuxBusConfig
+
+	// Clock drift compensation between each input and the output device
+	DriftCompensation bool
+	TargetLatency     time.Duration // Buffered input audio to hold, 0 selects two buffers
 }
 
 // DefaultMixerConfig returns a default mixer configuration
 func DefaultMixerConfig() *MixerConfig {
 	return &MixerConfig{
-		SampleRate:       DefaultSampleRate,
-		BufferSize:       DefaultBufferSize,
-		Channels:         DefaultChannels,
-		UseVirtualOutput: true, // Default to virtual output
-		Input1Gain:       1.0,
-		Input2Gain:       1.0,
-		MasterGain:       1.0,
+		SampleRate:        DefaultSampleRate,
+		BufferSize:        DefaultBufferSize,
+		Channels:          DefaultChannels,
+		UseVirtualOutput:  true, // Default to virtual output
+		MasterGain:        1.0,
+		UnderrunFill:      UnderrunFadeOut,
+		GainRampTime:      DefaultGainRampTime,
+		Limiter:           DefaultLimiterConfig(),
+		DriftCompensation: true,
 	}
 }
 
 // Mixer handles real-time audio mixing
 type Mixer struct {
 	config       *MixerConfig
-	input1Stream *portaudio.Stream
-	input2Stream *portaudio.Stream
-	outputStream *portaudio.Stream
-
-	input1Buffer *AudioBuffer
-	input2Buffer *AudioBuffer
-
-	bufferPool *BufferPool
-
-	// Atomic gains for thread-safe volume control
-	input1Gain atomic.Value // float32
-	input2Gain atomic.Value // float32
-	masterGain atomic.Value // float32
+	backend      Backend
+	outputStream Stream
+
+	// Conversion from the mixer layout to the output device, set up when
+	// the stream is opened and only touched by the output callback:
+	// outputMap from the mixer channels to the outputChannels of the device
+	// (outputMixed is nil for an identity map) and outputResampler to the
+	// device rate (nil when the device runs at the mixer rate), fed with
+	// outputBlock, one mixed buffer at the mixer rate.
+	outputChannels  int
+	outputMap       ChannelMap
+	outputMixed     []float32
+	outputResampler *resampler
+	outputBlock     []float32
+	outputInfo      atomic.Value // StreamInfo
+
+	// Input strips as an immutable []*inputStrip snapshot. Writers hold mu
+	// and publish a new slice so the output callback never takes a lock.
+	inputs atomic.Value
+
+	bufferPool   *BufferPool
+	fadeStep     float32   // Per-sample fade increment for attaching/detaching inputs
+	rampStep     float32   // Per-sample ramp increment for mute, solo and polarity
+	rampFrames   int       // Length of gain and pan ramps in frames
+	channelGains []float32 // Per-channel input gains, only touched by the output callback
+
+	// Atomic gain and pan law for thread-safe control; masterSmoother
+	// ramps towards masterGain and is only touched by the output callback
+	masterGain     atomic.Value // float32
+	masterSmoother gainSmoother
+	panLaw         atomic.Int32 // PanLaw
+
+	// Master bus limiter, created by Start (nil when disabled) and only
+	// touched by the output callback, which applies limiterCeiling to it
+	limiter        *limiter
+	limiterCeiling atomic.Value // float32, dBFS
+
+	// Sidechain ducking, master EQ and the master insert chain holding it,
+	// settable at any time
+	ducker      *ducker
+	masterEQ    *equalizer
+	masterChain *InsertChain
+
+	// Aux buses, fixed for the mixer's lifetime
+	auxBuses []*auxBus
+
+	// The running alignment measurement, if any, recorded by the output
+	// callback; alignMu serializes measurements
+	alignment atomic.Pointer[alignCapture]
+	alignMu   sync.Mutex
 
 	// Metrics
-	latency     atomic.Value // time.Duration
-	input1Level atomic.Value // float32
-	input2Level atomic.Value // float32
-	outputLevel atomic.Value // float32
-
-	running atomic.Bool
-	mu      sync.RWMutex
-	stopCh  chan struct{}
-	wg      sync.WaitGroup
+	latency       atomic.Value // time.Duration
+	outputLevel   atomic.Value // float32
+	gainReduction atomic.Value // float32, dB
+
+	// Lifecycle
+	state       atomic.Int32 // MixerState
+	listenersMu sync.Mutex
+	listeners   []func(StateChange)
+	lastErr     error
+
+	mu     sync.RWMutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
 }
 
 // NewMixer creates a new audio mixer
@@ -95,190 +152,304 @@ func NewMixer(config *MixerConfig) (*Mixer, error) {
 	if config.Channels <= 0 || config.Channels > MaxChannels {
 		config.Channels = DefaultChannels
 	}
+	if len(config.Inputs) > MaxInputs {
+		return nil, fmt.Errorf("too many inputs: %d (max %d)", len(config.Inputs), MaxInputs)
+	}
+	if len(config.AuxBuses) > MaxAuxBuses {
+		return nil, fmt.Errorf("too many aux buses: %d (max %d)", len(config.AuxBuses), MaxAuxBuses)
+	}
+
+	backend := config.Backend
+	if backend == nil {
+		backend = DefaultBackend()
+	}
 
 	mixer := &Mixer{
 		config:       config,
-		input1Buffer: NewAudioBuffer(config.BufferSize * config.Channels * 10),
-		input2Buffer: NewAudioBuffer(config.BufferSize * config.Channels * 10),
+		backend:      backend,
 		bufferPool:   NewBufferPool(config.BufferSize * config.Channels),
-		stopCh:       make(chan struct{}),
+		fadeStep:     float32(1 / (InputFadeTime.Seconds() * config.SampleRate * float64(config.Channels))),
+		rampStep:     float32(1 / (InputRampTime.Seconds() * config.SampleRate * float64(config.Channels))),
+		rampFrames:   config.BufferSize,
+		channelGains: make([]float32, config.Channels),
+		ducker:       newDucker(config.Ducking, config.SampleRate, config.BufferSize),
+		masterEQ:     newEqualizer(config.MasterEQ, config.Channels, config.SampleRate),
 	}
 
+	if config.GainRampTime > 0 {
+		mixer.rampFrames = int(config.GainRampTime.Seconds() * config.SampleRate)
+	}
+
+	masterChain, err := newInsertChain(config.MasterInserts, []Processor{mixer.masterEQ}, config.Channels, config.SampleRate, config.BufferSize)
+	if err != nil {
+		return nil, fmt.Errorf("master: %w", err)
+	}
+	mixer.masterChain = masterChain
+
+	for i, cfg := range config.AuxBuses {
+		bus, err := newAuxBus(cfg, config.BufferSize*config.Channels, config.Channels, config.SampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("aux bus %d (%s): %w", i+1, cfg.Name, err)
+		}
+		mixer.auxBuses = append(mixer.auxBuses, bus)
+	}
+
+	var inputs []*inputStrip
+	for i, input := range config.Inputs {
+		if input.Device == nil {
+			return nil, fmt.Errorf("input %d (%s): no device specified", i+1, input.Name)
+		}
+		strip, err := newInputStrip(input, config)
+		if err != nil {
+			return nil, fmt.Errorf("input %d (%s): %w", i+1, input.Name, err)
+		}
+		inputs = append(inputs, strip)
+	}
+	mixer.inputs.Store(inputs)
+
 	// Initialize atomic values
-	mixer.input1Gain.Store(config.Input1Gain)
-	mixer.input2Gain.Store(config.Input2Gain)
-	mixer.masterGain.Store(config.MasterGain)
+	mixer.masterGain.Store(clampGain(config.MasterGain))
+	mixer.panLaw.Store(int32(config.PanLaw))
+	mixer.limiterCeiling.Store(config.Limiter.Ceiling)
 	mixer.latency.Store(time.Duration(0))
-	mixer.input1Level.Store(float32(0))
-	mixer.input2Level.Store(float32(0))
 	mixer.outputLevel.Store(float32(0))
+	mixer.gainReduction.Store(float32(0))
+	mixer.outputInfo.Store(StreamInfo{MixerRate: config.SampleRate})
 
 	return mixer, nil
 }
 
-// Start begins audio processing
+// Start begins audio processing. A mixer that was stopped, or whose
+// previous Start failed, can be started again.
 func (m *Mixer) Start() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.running.Load() {
+	if state := m.State(); state != StateIdle && state != StateFailed {
 		return fmt.Errorf("mixer already running")
 	}
 
-	// Open input stream 1 (microphone)
-	if m.config.Input1Device != nil {
-		// Use the minimum of configured channels and device's max input channels
-		input1Channels := m.config.Channels
-		if m.config.Input1Device.MaxInputChannels < input1Channels {
-			input1Channels = m.config.Input1Device.MaxInputChannels
-		}
-		if input1Channels > MaxChannels {
-			input1Channels = MaxChannels
-		}
-
-		input1Params := portaudio.StreamParameters{
-			Input: portaudio.StreamDeviceParameters{
-				Device:   m.config.Input1Device,
-				Channels: input1Channels,
-				Latency:  m.config.Input1Device.DefaultLowInputLatency,
-			},
-			SampleRate:      m.config.SampleRate,
-			FramesPerBuffer: m.config.BufferSize,
-		}
+	m.setState(StateStarting, nil)
 
-		stream, err := portaudio.OpenStream(input1Params, m.input1Callback)
-		if err != nil {
-			return fmt.Errorf("failed to open input1 stream: %w", err)
-		}
-		m.input1Stream = stream
+	// Drop anything left over from a previous run
+	m.stopCh = make(chan struct{})
+	for _, strip := range m.loadInputs() {
+		strip.reset()
+	}
+	m.masterSmoother.reset()
+	m.ducker.reset()
+	m.masterChain.Reset()
+	for _, bus := range m.auxBuses {
+		bus.reset()
+	}
+	m.latency.Store(time.Duration(0))
+	m.outputLevel.Store(float32(0))
+	m.gainReduction.Store(float32(0))
 
-		if err := m.input1Stream.Start(); err != nil {
-			m.input1Stream.Close()
-			return fmt.Errorf("failed to start input1 stream: %w", err)
-		}
+	m.limiter = nil
+	if m.config.Limiter.Enabled {
+		m.limiter = newLimiter(m.config.Limiter, m.config.Channels, m.config.SampleRate)
 	}
 
-	// Open input stream 2 (application audio)
-	if m.config.Input2Device != nil {
-		// Use the minimum of configured channels and device's max input channels
-		input2Channels := m.config.Channels
-		if m.config.Input2Device.MaxInputChannels < input2Channels {
-			input2Channels = m.config.Input2Device.MaxInputChannels
-		}
-		if input2Channels > MaxChannels {
-			input2Channels = MaxChannels
-		}
+	if err := m.openStreams(); err != nil {
+		m.closeStreams()
+		m.setState(StateFailed, err)
+		return err
+	}
 
-		input2Params := portaudio.StreamParameters{
-			Input: portaudio.StreamDeviceParameters{
-				Device:   m.config.Input2Device,
-				Channels: input2Channels,
-				Latency:  m.config.Input2Device.DefaultLowInputLatency,
-			},
-			SampleRate:      m.config.SampleRate,
-			FramesPerBuffer: m.config.BufferSize,
-		}
+	m.setState(StateRunning, nil)
+	return nil
+}
 
-		stream, err := portaudio.OpenStream(input2Params, m.input2Callback)
-		if err != nil {
-			if m.input1Stream != nil {
-				m.input1Stream.Close()
-			}
-			return fmt.Errorf("failed to open input2 stream: %w", err)
-		}
-		m.input2Stream = stream
-
-		if err := m.input2Stream.Start(); err != nil {
-			if m.input1Stream != nil {
-				m.input1Stream.Close()
-			}
-			m.input2Stream.Close()
-			return fmt.Errorf("failed to start input2 stream: %w", err)
+// openStreams opens and starts every input stream and the output stream
+func (m *Mixer) openStreams() error {
+	// Open input streams
+	for i, strip := range m.loadInputs() {
+		if err := m.openInputStream(strip); err != nil {
+			return fmt.Errorf("input %d (%s): %w", i+1, strip.name, err)
 		}
 	}
 
 	// Open output stream
 	if m.config.OutputDevice != nil {
-		// Use the minimum of configured channels and device's max output channels
-		outputChannels := m.config.Channels
-		if m.config.OutputDevice.MaxOutputChannels < outputChannels {
-			outputChannels = m.config.OutputDevice.MaxOutputChannels
-		}
-		if outputChannels > MaxChannels {
-			outputChannels = MaxChannels
+		outputMap, err := m.resolveOutputChannelMap()
+		if err != nil {
+			return err
 		}
 
-		outputParams := portaudio.StreamParameters{
-			Output: portaudio.StreamDeviceParameters{
-				Device:   m.config.OutputDevice,
-				Channels: outputChannels,
-				Latency:  m.config.OutputDevice.DefaultLowOutputLatency,
-			},
+		outputParams := StreamParams{
+			Device:          m.config.OutputDevice,
+			Channels:        outputMap.Destinations(),
 			SampleRate:      m.config.SampleRate,
 			FramesPerBuffer: m.config.BufferSize,
 		}
 
-		stream, err := portaudio.OpenStream(outputParams, m.outputCallback)
+		stream, params, err := m.openStreamAtSupportedRate(outputParams, func(params StreamParams) (Stream, error) {
+			return m.backend.OpenOutputStream(params, m.outputCallback)
+		})
 		if err != nil {
-			if m.input1Stream != nil {
-				m.input1Stream.Close()
-			}
-			if m.input2Stream != nil {
-				m.input2Stream.Close()
-			}
 			return fmt.Errorf("failed to open output stream: %w", err)
 		}
 		m.outputStream = stream
 
+		m.prepareOutputStream(params, outputMap)
+
 		if err := m.outputStream.Start(); err != nil {
-			if m.input1Stream != nil {
-				m.input1Stream.Close()
-			}
-			if m.input2Stream != nil {
-				m.input2Stream.Close()
-			}
-			m.outputStream.Close()
 			return fmt.Errorf("failed to start output stream: %w", err)
 		}
 	}
 
-	m.running.Store(true)
 	return nil
 }
 
-// Stop stops audio processing
+// resolveOutputChannelMap returns the channel map to open the output device with
+func (m *Mixer) resolveOutputChannelMap() (ChannelMap, error) {
+	device := m.config.OutputDevice
+	if m.config.OutputChannelMap == nil {
+		channels := min(m.config.Channels, device.MaxOutputChannels)
+		if channels < 1 {
+			return nil, fmt.Errorf("device %s has no output channels", device.Name)
+		}
+		return DefaultChannelMap(m.config.Channels, channels), nil
+	}
+
+	outputMap := m.config.OutputChannelMap
+	if outputMap.Sources() != m.config.Channels {
+		return nil, fmt.Errorf("output channel map has %d source channels, need %d", outputMap.Sources(), m.config.Channels)
+	}
+	if err := outputMap.Validate(m.config.Channels, outputMap.Destinations()); err != nil {
+		return nil, err
+	}
+	if outputMap.Destinations() > device.MaxOutputChannels {
+		return nil, fmt.Errorf("output channel map needs %d device channels, have %d", outputMap.Destinations(), device.MaxOutputChannels)
+	}
+	return outputMap, nil
+}
+
+// prepareOutputStream sets up channel mapping and sample rate conversion
+// for an output stream opened with params; call it before the stream is started
+func (m *Mixer) prepareOutputStream(params StreamParams, outputMap ChannelMap) {
+	channels := m.config.Channels
+
+	m.outputChannels = params.Channels
+	m.outputMap = outputMap
+	m.outputMixed = nil
+	if !outputMap.IsIdentity() {
+		m.outputMixed = make([]float32, params.FramesPerBuffer*channels)
+	}
+
+	m.outputResampler = nil
+	m.outputBlock = nil
+	if params.SampleRate != m.config.SampleRate {
+		m.outputResampler = newResampler(channels, m.config.SampleRate, params.SampleRate, m.config.BufferSize)
+		m.outputBlock = make([]float32, m.config.BufferSize*channels)
+	}
+
+	m.outputInfo.Store(StreamInfo{
+		Device:     params.Device,
+		SampleRate: params.SampleRate,
+		MixerRate:  m.config.SampleRate,
+		ChannelMap: outputMap,
+	})
+}
+
+// openInputStream opens and starts the capture stream of an input strip
+func (m *Mixer) openInputStream(strip *inputStrip) error {
+	channelMap, err := strip.resolveChannelMap(m.config.Channels)
+	if err != nil {
+		return err
+	}
+
+	params := StreamParams{
+		Device:          strip.device,
+		Channels:        channelMap.Sources(),
+		SampleRate:      m.config.SampleRate,
+		FramesPerBuffer: m.config.BufferSize,
+	}
+
+	stream, params, err := m.openStreamAtSupportedRate(params, func(params StreamParams) (Stream, error) {
+		return m.backend.OpenInputStream(params, func(in []float32) {
+			m.inputCallback(strip, in)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open input stream: %w", err)
+	}
+	strip.stream = stream
+	strip.prepareStream(params, channelMap, m.config.SampleRate)
+
+	if err := strip.stream.Start(); err != nil {
+		return fmt.Errorf("failed to start input stream: %w", err)
+	}
+	return nil
+}
+
+// openStreamAtSupportedRate opens a stream at the mixer rate. If the device
+// rejects it, the stream is opened at the device's default sample rate
+// instead, with the buffer size scaled to keep the same period. It returns
+// the parameters the stream was actually opened with.
+func (m *Mixer) openStreamAtSupportedRate(params StreamParams, open func(StreamParams) (Stream, error)) (Stream, StreamParams, error) {
+	stream, err := open(params)
+	if err == nil {
+		return stream, params, nil
+	}
+
+	deviceRate := params.Device.DefaultSampleRate
+	if deviceRate <= 0 || deviceRate == params.SampleRate {
+		return nil, params, err
+	}
+
+	fallback := params
+	fallback.SampleRate = deviceRate
+	fallback.FramesPerBuffer = max(1, int(math.Round(float64(params.FramesPerBuffer)*deviceRate/params.SampleRate)))
+
+	stream, fallbackErr := open(fallback)
+	if fallbackErr != nil {
+		return nil, params, fmt.Errorf("%w (at %.0f Hz: %v)", err, deviceRate, fallbackErr)
+	}
+	return stream, fallback, nil
+}
+
+// closeStreams closes every stream opened so far after a failed start
+func (m *Mixer) closeStreams() {
+	for _, strip := range m.loadInputs() {
+		strip.closeStream()
+	}
+	if m.outputStream != nil {
+		m.outputStream.Close()
+		m.outputStream = nil
+		m.outputInfo.Store(StreamInfo{MixerRate: m.config.SampleRate})
+	}
+}
+
+// Stop stops audio processing and returns the mixer to StateIdle
 func (m *Mixer) Stop() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if !m.running.Load() {
+	if m.State() == StateIdle {
 		return nil
 	}
 
-	m.running.Store(false)
-	close(m.stopCh)
+	m.setState(StateStopping, nil)
+	if m.stopCh != nil {
+		close(m.stopCh)
+		m.stopCh = nil
+	}
 
 	// Stop and close all streams
 	var errs []error
 
-	if m.input1Stream != nil {
-		if err := m.input1Stream.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("input1 stream stop error: %w", err))
-		}
-		if err := m.input1Stream.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("input1 stream close error: %w", err))
+	for i, strip := range m.loadInputs() {
+		if strip.stream == nil {
+			continue
 		}
-		m.input1Stream = nil
-	}
-
-	if m.input2Stream != nil {
-		if err := m.input2Stream.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("input2 stream stop error: %w", err))
+		if err := strip.stream.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("input %d stream stop error: %w", i+1, err))
 		}
-		if err := m.input2Stream.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("input2 stream close error: %w", err))
+		if err := strip.closeStream(); err != nil {
+			errs = append(errs, fmt.Errorf("input %d stream close error: %w", i+1, err))
 		}
-		m.input2Stream = nil
 	}
 
 	if m.outputStream != nil {
@@ -289,83 +460,289 @@ func (m *Mixer) Stop() error {
 			errs = append(errs, fmt.Errorf("output stream close error: %w", err))
 		}
 		m.outputStream = nil
+		m.outputInfo.Store(StreamInfo{MixerRate: m.config.SampleRate})
 	}
 
 	m.wg.Wait()
 
 	if len(errs) > 0 {
-		return fmt.Errorf("errors during stop: %v", errs)
+		err := fmt.Errorf("errors during stop: %v", errs)
+		m.setState(StateFailed, err)
+		return err
 	}
 
+	m.setState(StateIdle, nil)
 	return nil
 }
 
-// input1Callback handles input from first device (microphone)
-func (m *Mixer) input1Callback(in []float32) {
-	if !m.running.Load() {
-		return
+// AddInput appends a new input strip and returns its index. On a running
+// mixer the input's stream is opened right away and the input fades in
+// without interrupting the output or the other inputs.
+func (m *Mixer) AddInput(input InputConfig) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if input.Device == nil {
+		return -1, fmt.Errorf("no device specified")
 	}
 
-	// Calculate and store audio level
-	level := calculateRMS(in)
-	m.input1Level.Store(level)
+	inputs := m.loadInputs()
+	if len(inputs) >= MaxInputs {
+		return -1, fmt.Errorf("too many inputs (max %d)", MaxInputs)
+	}
 
-	// Write to buffer
-	m.input1Buffer.Write(in)
+	strip, err := newInputStrip(input, m.config)
+	if err != nil {
+		return -1, fmt.Errorf("input %s: %w", input.Name, err)
+	}
+	if m.State() == StateRunning {
+		if err := m.openInputStream(strip); err != nil {
+			strip.closeStream()
+			return -1, fmt.Errorf("input %s: %w", input.Name, err)
+		}
+		strip.fade = 0
+	}
+
+	updated := make([]*inputStrip, len(inputs), len(inputs)+1)
+	copy(updated, inputs)
+	m.inputs.Store(append(updated, strip))
+
+	return len(updated), nil
 }
 
-// input2Callback handles input from second device (application audio)
-func (m *Mixer) input2Callback(in []float32) {
-	if !m.running.Load() {
-		return
+// RemoveInput removes the input strip at index; later inputs shift down by one.
+// On a running mixer the input fades out before its stream is closed.
+func (m *Mixer) RemoveInput(index int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inputs := m.loadInputs()
+	if index < 0 || index >= len(inputs) {
+		return fmt.Errorf("input index %d out of range", index)
+	}
+	strip := inputs[index]
+
+	if m.State() == StateRunning && m.outputStream != nil {
+		// Let the output callback ramp the input down to silence first
+		strip.active.Store(false)
+		select {
+		case <-strip.faded:
+		case <-time.After(InputFadeTime + 100*time.Millisecond):
+		}
 	}
 
-	// Calculate and store audio level
-	level := calculateRMS(in)
-	m.input2Level.Store(level)
+	updated := make([]*inputStrip, 0, len(inputs)-1)
+	updated = append(updated, inputs[:index]...)
+	updated = append(updated, inputs[index+1:]...)
+	m.inputs.Store(updated)
+
+	// Keep ducking on the same trigger; ducking stops if it was removed
+	if ducking := m.ducker.get(); ducking.Trigger >= index {
+		if ducking.Trigger == index {
+			ducking.Trigger = -1
+		} else {
+			ducking.Trigger--
+		}
+		m.ducker.set(ducking)
+	}
 
-	// Write to buffer
-	m.input2Buffer.Write(in)
+	if strip.stream != nil {
+		strip.stream.Stop()
+		if err := strip.closeStream(); err != nil {
+			return fmt.Errorf("input %d stream close error: %w", index+1, err)
+		}
+	}
+	return nil
+}
+
+// loadInputs returns the current input strip snapshot
+func (m *Mixer) loadInputs() []*inputStrip {
+	return m.inputs.Load().([]*inputStrip)
+}
+
+// inputCallback handles captured samples of one input strip
+func (m *Mixer) inputCallback(strip *inputStrip, in []float32) {
+	if m.State() != StateRunning {
+		return
+	}
+
+	// Map and filter the samples, then meter and buffer them
+	in = strip.condition(in)
+	strip.level.Store(calculateRMS(in))
+	strip.write(in)
 }
 
 // outputCallback handles output mixing
 func (m *Mixer) outputCallback(out []float32) {
-	if !m.running.Load() {
+	if m.State() != StateRunning {
 		return
 	}
 
 	startTime := time.Now()
 
+	// Mix in the mixer's channel layout, then map to the device channels
+	mixed := out
+	if m.outputMixed != nil {
+		frames := len(out) / m.outputChannels
+		if need := frames * m.config.Channels; need > len(m.outputMixed) {
+			m.outputMixed = make([]float32, need)
+		}
+		mixed = m.outputMixed[:frames*m.config.Channels]
+	}
+
+	if m.outputResampler == nil {
+		// Mix in buffers of at most the size the mixer's scratch buffers
+		// have room for
+		block := m.config.BufferSize * m.config.Channels
+		for start := 0; start < len(mixed); start += block {
+			m.render(mixed[start:min(start+block, len(mixed))])
+		}
+	} else {
+		// Mix whole buffers at the mixer rate until the device buffer is full
+		channels := m.config.Channels
+		filled := m.outputResampler.pull(mixed) * channels
+		for len(mixed)-filled >= channels {
+			m.render(m.outputBlock)
+			m.outputResampler.push(m.outputBlock)
+			filled += m.outputResampler.pull(mixed[filled:]) * channels
+		}
+	}
+
+	if m.outputMixed != nil {
+		m.outputMap.Apply(out, mixed)
+	}
+
+	// Update latency metric, including the delay processing adds
+	latency := time.Since(startTime) + m.processingDelay()
+	m.latency.Store(latency)
+}
+
+// processingDelay returns how far processing holds audio back: the longest
+// delay of any input chain and alignment delay plus the slowest aux bus
+// chain, the master chain and the limiter's look-ahead
+func (m *Mixer) processingDelay() time.Duration {
+	var delay, aux time.Duration
+	for _, strip := range m.loadInputs() {
+		delay = max(delay, strip.chain.Latency()+framesDuration(strip.delay.get(), m.config.SampleRate))
+	}
+	for _, bus := range m.auxBuses {
+		aux = max(aux, bus.chain.Latency())
+	}
+	delay += aux + m.masterChain.Latency()
+	if m.limiter != nil {
+		delay += framesDuration(m.limiter.length-1, m.config.SampleRate)
+	}
+	return delay
+}
+
+// render mixes the next buffer of every input into out at the mixer rate;
+// out holds at most BufferSize frames
+func (m *Mixer) render(out []float32) {
 	// Get buffers from pool
-	input1Buf := m.bufferPool.Get()
-	input2Buf := m.bufferPool.Get()
+	mixBuf := m.bufferPool.Get()
+	inputBuf := m.bufferPool.Get()
+	postBuf := m.bufferPool.Get()
 	defer func() {
-		m.bufferPool.Put(input1Buf)
-		m.bufferPool.Put(input2Buf)
+		m.bufferPool.Put(mixBuf)
+		m.bufferPool.Put(inputBuf)
+		m.bufferPool.Put(postBuf)
 	}()
 
-	// Read from input buffers
-	m.input1Buffer.Read(input1Buf[:len(out)])
-	m.input2Buffer.Read(input2Buf[:len(out)])
+	inputs := m.loadInputs()
+	soloActive := false
+	for _, strip := range inputs {
+		if strip.soloed.Load() {
+			soloActive = true
+			break
+		}
+	}
+
+	// Sum every input at its own gain and pan position, and its sends
+	// into the aux buses. The ducking trigger goes first so its level can
+	// turn the others down.
+	ch := m.config.Channels
+	in := inputBuf[:len(out)]
+	post := postBuf[:len(out)]
+	for _, bus := range m.auxBuses {
+		clear(bus.buf[:len(out)])
+	}
+	trigger := m.ducker.trigger(inputs)
+	if trigger != nil {
+		m.mixInput(trigger, mixBuf[:len(out)], in, post, soloActive, false)
 
-	// Get current gains
-	input1Gain := m.input1Gain.Load().(float32)
-	input2Gain := m.input2Gain.Load().(float32)
-	masterGain := m.masterGain.Load().(float32)
+		level := calculateRMS(in)
+		if trigger.muted.Load() {
+			level = 0
+		}
+		m.ducker.update(level, len(out)/ch)
+	}
+	for _, strip := range inputs {
+		if strip == trigger {
+			continue
+		}
+		m.mixInput(strip, mixBuf[:len(out)], in, post, soloActive, trigger != nil)
+	}
+	for _, bus := range m.auxBuses {
+		bus.mixReturn(mixBuf[:len(out)], ch, m.rampFrames)
+	}
+	if capture := m.alignment.Load(); capture != nil && capture.full() {
+		m.alignment.Store(nil)
+		close(capture.done)
+	}
 
-	// Mix audio with soft clipping
-	for i := range out {
-		mixed := (input1Buf[i]*input1Gain + input2Buf[i]*input2Gain) * masterGain
-		out[i] = softClip(mixed)
+	// Apply master gain and EQ, then limit the peaks, or soft clip them
+	// without a limiter
+	m.masterSmoother.setTarget(m.masterGain.Load().(float32), m.rampFrames)
+	for f := 0; f < len(out)/ch; f++ {
+		gain := m.masterSmoother.next()
+		for i := f * ch; i < f*ch+ch; i++ {
+			out[i] = mixBuf[i] * gain
+		}
+	}
+
+	m.masterChain.Process(out)
+
+	if m.limiter != nil {
+		m.limiter.setCeiling(m.limiterCeiling.Load().(float32))
+		lowest := m.limiter.process(out)
+		m.gainReduction.Store(max(0, -gainToDB(lowest)))
+	} else {
+		for i := range out {
+			out[i] = softClip(out[i])
+		}
 	}
 
 	// Calculate and store output level
 	level := calculateRMS(out)
 	m.outputLevel.Store(level)
+}
 
-	// Update latency metric
-	latency := time.Since(startTime)
-	m.latency.Store(latency)
+// mixInput reads the strip's next samples into in, runs its processing
+// and alignment delay, ducks it if duck is set and adds it to mix and, by
+// way of post, to the aux buses it sends to
+func (m *Mixer) mixInput(strip *inputStrip, mix, in, post []float32, soloActive, duck bool) {
+	strip.read(in)
+	strip.chain.Process(in)
+	if capture := m.alignment.Load(); capture != nil {
+		capture.add(strip, in, m.config.Channels)
+	}
+	strip.delay.process(in)
+	if duck {
+		m.ducker.apply(in, m.config.Channels)
+	}
+
+	strip.channelGains(m.channelGains, PanLaw(m.panLaw.Load()))
+	if !strip.sending() {
+		strip.mixInto(mix, in, m.channelGains, m.rampFrames, m.fadeStep, m.rampStep, soloActive)
+		return
+	}
+
+	clear(post)
+	strip.mixInto(post, in, m.channelGains, m.rampFrames, m.fadeStep, m.rampStep, soloActive)
+	for i := range mix {
+		mix[i] += post[i]
+	}
+	strip.send(m.auxBuses, post, m.config.Channels, m.rampFrames)
 }
 
 // softClip implements soft clipping to prevent harsh distortion
@@ -386,6 +763,16 @@ func softClip(sample float32) float32 {
 	return sample
 }
 
+// framesDuration returns how long frames last at sampleRate
+func framesDuration(frames int, sampleRate float64) time.Duration {
+	return time.Duration(float64(frames) / sampleRate * float64(time.Second))
+}
+
+// durationFrames returns how many frames at sampleRate last d, rounded
+func durationFrames(d time.Duration, sampleRate float64) int {
+	return int(math.Round(d.Seconds() * sampleRate))
+}
+
 // calculateRMS calculates the RMS (root mean square) level of audio samples
 func calculateRMS(samples []float32) float32 {
 	if len(samples) == 0 {
@@ -401,47 +788,375 @@ func calculateRMS(samples []float32) float32 {
 	return float32(rms)
 }
 
-// SetInput1Gain sets the gain for input 1 (0.0 to 2.0)
-func (m *Mixer) SetInput1Gain(gain float32) {
-	if gain < 0 {
-		gain = 0
+// input returns the input strip at index, or nil if out of range
+func (m *Mixer) input(index int) *inputStrip {
+	inputs := m.loadInputs()
+	if index < 0 || index >= len(inputs) {
+		return nil
+	}
+	return inputs[index]
+}
+
+// NumInputs returns the number of input strips
+func (m *Mixer) NumInputs() int {
+	return len(m.loadInputs())
+}
+
+// GetInputName returns the display name of an input
+func (m *Mixer) GetInputName(index int) string {
+	if strip := m.input(index); strip != nil {
+		return strip.name
+	}
+	return ""
+}
+
+// SetInputGain sets the gain for an input (0.0 to 2.0)
+func (m *Mixer) SetInputGain(index int, gain float32) {
+	if strip := m.input(index); strip != nil {
+		strip.gain.Store(clampGain(gain))
+	}
+}
+
+// SetInputPan sets the pan position of an input, from -1.0 (left) to 1.0 (right).
+// In a stereo mix it pans mono sources and balances stereo ones.
+func (m *Mixer) SetInputPan(index int, pan float32) {
+	if strip := m.input(index); strip != nil {
+		strip.pan.Store(clampPan(pan))
+	}
+}
+
+// GetInputPan returns the pan position of an input
+func (m *Mixer) GetInputPan(index int) float32 {
+	if strip := m.input(index); strip != nil {
+		return strip.pan.Load().(float32)
+	}
+	return 0
+}
+
+// SetDucking changes the sidechain ducking settings
+func (m *Mixer) SetDucking(cfg DuckingConfig) {
+	m.ducker.set(cfg)
+}
+
+// GetDucking returns the sidechain ducking settings
+func (m *Mixer) GetDucking() DuckingConfig {
+	return m.ducker.get()
+}
+
+// GetDuckingGainReduction returns how far ducking turned the other inputs
+// down at the end of the last buffer, in dB
+func (m *Mixer) GetDuckingGainReduction() float32 {
+	return m.ducker.gainReduction.Load().(float32)
+}
+
+// SetMasterEQ changes the master bus equalizer settings
+func (m *Mixer) SetMasterEQ(cfg EQConfig) {
+	m.masterEQ.set(cfg)
+}
+
+// GetMasterEQ returns the master bus equalizer settings
+func (m *Mixer) GetMasterEQ() EQConfig {
+	return m.masterEQ.get()
+}
+
+// MasterChain returns the master bus insert chain, which runs before the
+// limiter and may be edited while audio runs
+func (m *Mixer) MasterChain() *InsertChain {
+	return m.masterChain
+}
+
+// InputChain returns the insert chain of an input, or nil if there is no
+// such input; it may be edited while audio runs
+func (m *Mixer) InputChain(index int) *InsertChain {
+	if strip := m.input(index); strip != nil {
+		return strip.chain
+	}
+	return nil
+}
+
+// SetInputEQ changes the equalizer settings of an input
+func (m *Mixer) SetInputEQ(index int, cfg EQConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.eq.set(cfg)
+	}
+}
+
+// GetInputEQ returns the equalizer settings of an input
+func (m *Mixer) GetInputEQ(index int) EQConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.eq.get()
+	}
+	return EQConfig{}
+}
+
+// SetInputFilter changes the DC blocker and high-pass filter of an input
+func (m *Mixer) SetInputFilter(index int, cfg InputFilterConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.filter.set(cfg)
+	}
+}
+
+// GetInputFilter returns the DC blocker and high-pass filter settings of an input
+func (m *Mixer) GetInputFilter(index int) InputFilterConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.filter.get()
+	}
+	return InputFilterConfig{}
+}
+
+// SetInputDenoiser changes the noise suppression settings of an input
+func (m *Mixer) SetInputDenoiser(index int, cfg DenoiserConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.denoiser.set(cfg)
 	}
-	if gain > 2.0 {
-		gain = 2.0
+}
+
+// GetInputDenoiser returns the noise suppression settings of an input
+func (m *Mixer) GetInputDenoiser(index int) DenoiserConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.denoiser.get()
 	}
-	m.input1Gain.Store(gain)
+	return DenoiserConfig{}
 }
 
-// SetInput2Gain sets the gain for input 2 (0.0 to 2.0)
-func (m *Mixer) SetInput2Gain(gain float32) {
-	if gain < 0 {
-		gain = 0
+// LearnInputNoise has an input's noise suppression learn the noise profile
+// from the next DenoiserLearnTime of audio; keep the input quiet meanwhile
+func (m *Mixer) LearnInputNoise(index int) {
+	if strip := m.input(index); strip != nil {
+		strip.denoiser.learn()
 	}
-	if gain > 2.0 {
-		gain = 2.0
+}
+
+// IsInputLearningNoise returns whether an input's noise suppression is
+// still learning the noise profile
+func (m *Mixer) IsInputLearningNoise(index int) bool {
+	if strip := m.input(index); strip != nil {
+		return strip.denoiser.learning.Load()
 	}
-	m.input2Gain.Store(gain)
+	return false
+}
+
+// GetInputDenoiserLatency returns the delay an input's noise suppression
+// adds, zero while it is disabled
+func (m *Mixer) GetInputDenoiserLatency(index int) time.Duration {
+	if strip := m.input(index); strip != nil {
+		return strip.denoiser.delay()
+	}
+	return 0
+}
+
+// SetInputGate changes the noise gate settings of an input
+func (m *Mixer) SetInputGate(index int, cfg GateConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.gate.set(cfg)
+	}
+}
+
+// GetInputGate returns the noise gate settings of an input
+func (m *Mixer) GetInputGate(index int) GateConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.gate.get()
+	}
+	return GateConfig{}
+}
+
+// IsInputGateOpen returns whether an input's noise gate lets audio through;
+// a disabled gate is always open
+func (m *Mixer) IsInputGateOpen(index int) bool {
+	if strip := m.input(index); strip != nil {
+		return strip.gate.open.Load()
+	}
+	return false
+}
+
+// SetInputAGC changes the automatic gain control settings of an input
+func (m *Mixer) SetInputAGC(index int, cfg AGCConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.agc.set(cfg)
+	}
+}
+
+// GetInputAGC returns the automatic gain control settings of an input
+func (m *Mixer) GetInputAGC(index int) AGCConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.agc.get()
+	}
+	return AGCConfig{}
+}
+
+// GetInputAGCGain returns the gain in dB an input's automatic gain control
+// currently applies, zero while it is disabled
+func (m *Mixer) GetInputAGCGain(index int) float32 {
+	if strip := m.input(index); strip != nil {
+		return strip.agc.applied.Load().(float32)
+	}
+	return 0
+}
+
+// SetInputCompressor changes the compressor settings of an input
+func (m *Mixer) SetInputCompressor(index int, cfg CompressorConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.compressor.set(cfg)
+	}
+}
+
+// GetInputCompressor returns the compressor settings of an input
+func (m *Mixer) GetInputCompressor(index int) CompressorConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.compressor.get()
+	}
+	return CompressorConfig{}
+}
+
+// GetInputCompressorGainReduction returns how far an input's compressor
+// turned it down during the last buffer, in dB
+func (m *Mixer) GetInputCompressorGainReduction(index int) float32 {
+	if strip := m.input(index); strip != nil {
+		return strip.compressor.gainReduction.Load().(float32)
+	}
+	return 0
+}
+
+// SetInputDeEsser changes the de-esser settings of an input
+func (m *Mixer) SetInputDeEsser(index int, cfg DeEsserConfig) {
+	if strip := m.input(index); strip != nil {
+		strip.deEsser.set(cfg)
+	}
+}
+
+// GetInputDeEsser returns the de-esser settings of an input
+func (m *Mixer) GetInputDeEsser(index int) DeEsserConfig {
+	if strip := m.input(index); strip != nil {
+		return strip.deEsser.get()
+	}
+	return DeEsserConfig{}
+}
+
+// GetInputDeEsserGainReduction returns how far an input's de-esser turned
+// its sibilance band down during the last buffer, in dB
+func (m *Mixer) GetInputDeEsserGainReduction(index int) float32 {
+	if strip := m.input(index); strip != nil {
+		return strip.deEsser.gainReduction.Load().(float32)
+	}
+	return 0
+}
+
+// SetInputDelay changes how long an input is held back to line it up with
+// the others, up to MaxInputDelay
+func (m *Mixer) SetInputDelay(index int, delay time.Duration) {
+	if strip := m.input(index); strip != nil {
+		strip.delay.set(durationFrames(delay, m.config.SampleRate))
+	}
+}
+
+// GetInputDelay returns how long an input is held back
+func (m *Mixer) GetInputDelay(index int) time.Duration {
+	if strip := m.input(index); strip != nil {
+		return framesDuration(strip.delay.get(), m.config.SampleRate)
+	}
+	return 0
+}
+
+// SetInputMute mutes or unmutes an input
+func (m *Mixer) SetInputMute(index int, mute bool) {
+	if strip := m.input(index); strip != nil {
+		strip.muted.Store(mute)
+	}
+}
+
+// IsInputMuted returns whether an input is muted
+func (m *Mixer) IsInputMuted(index int) bool {
+	if strip := m.input(index); strip != nil {
+		return strip.muted.Load()
+	}
+	return false
+}
+
+// SetInputSolo solos or unsolos an input. While any input is soloed, only
+// soloed inputs are heard.
+func (m *Mixer) SetInputSolo(index int, solo bool) {
+	if strip := m.input(index); strip != nil {
+		strip.soloed.Store(solo)
+	}
+}
+
+// IsInputSoloed returns whether an input is soloed
+func (m *Mixer) IsInputSoloed(index int) bool {
+	if strip := m.input(index); strip != nil {
+		return strip.soloed.Load()
+	}
+	return false
+}
+
+// SetInputPolarityInvert flips the polarity of an input
+func (m *Mixer) SetInputPolarityInvert(index int, invert bool) {
+	if strip := m.input(index); strip != nil {
+		strip.inverted.Store(invert)
+	}
+}
+
+// IsInputPolarityInverted returns whether an input's polarity is flipped
+func (m *Mixer) IsInputPolarityInverted(index int) bool {
+	if strip := m.input(index); strip != nil {
+		return strip.inverted.Load()
+	}
+	return false
+}
+
+// SetPanLaw selects the pan law applied to every input
+func (m *Mixer) SetPanLaw(law PanLaw) {
+	m.panLaw.Store(int32(law))
+}
+
+// GetPanLaw returns the pan law applied to every input
+func (m *Mixer) GetPanLaw() PanLaw {
+	return PanLaw(m.panLaw.Load())
 }
 
 // SetMasterGain sets the master output gain (0.0 to 2.0)
 func (m *Mixer) SetMasterGain(gain float32) {
-	if gain < 0 {
-		gain = 0
+	m.masterGain.Store(clampGain(gain))
+}
+
+// GetInputLevel returns the current RMS level of an input
+func (m *Mixer) GetInputLevel(index int) float32 {
+	if strip := m.input(index); strip != nil {
+		return strip.level.Load().(float32)
 	}
-	if gain > 2.0 {
-		gain = 2.0
+	return 0
+}
+
+// GetInputXruns returns how often an input's buffer ran dry (underrun) or
+// overflowed (overrun) since the mixer was started
+func (m *Mixer) GetInputXruns(index int) XrunStats {
+	if strip := m.input(index); strip != nil {
+		return strip.buffer.Stats()
 	}
-	m.masterGain.Store(gain)
+	return XrunStats{}
 }
 
-// GetInput1Level returns the current RMS level of input 1
-func (m *Mixer) GetInput1Level() float32 {
-	return m.input1Level.Load().(float32)
+// GetInputDriftRatio returns the resampling ratio currently applied to an
+// input to follow the output clock (1.0 means no drift)
+func (m *Mixer) GetInputDriftRatio(index int) float64 {
+	if strip := m.input(index); strip != nil {
+		return strip.driftRatio.Load().(float64)
+	}
+	return 1
 }
 
-// GetInput2Level returns the current RMS level of input 2
-func (m *Mixer) GetInput2Level() float32 {
-	return m.input2Level.Load().(float32)
+// GetInputStreamInfo reports the device and sample rate an input's stream
+// was opened with
+func (m *Mixer) GetInputStreamInfo(index int) StreamInfo {
+	if strip := m.input(index); strip != nil {
+		return strip.streamInfo.Load().(StreamInfo)
+	}
+	return StreamInfo{MixerRate: m.config.SampleRate}
+}
+
+// GetOutputStreamInfo reports the device and sample rate the output stream
+// was opened with
+func (m *Mixer) GetOutputStreamInfo() StreamInfo {
+	return m.outputInfo.Load().(StreamInfo)
 }
 
 // GetOutputLevel returns the current RMS level of output
@@ -449,12 +1164,56 @@ func (m *Mixer) GetOutputLevel() float32 {
 	return m.outputLevel.Load().(float32)
 }
 
-// GetLatency returns the current processing latency
+// GetLimiterGainReduction returns how far the limiter turned the master
+// bus down during the last buffer, in dB (0 when it is idle or disabled)
+func (m *Mixer) GetLimiterGainReduction() float32 {
+	return m.gainReduction.Load().(float32)
+}
+
+// SetLimiter changes the master bus limiter settings used by the next Start
+func (m *Mixer) SetLimiter(limiter LimiterConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.State() == StateRunning {
+		return fmt.Errorf("cannot change limiter while mixer is running")
+	}
+
+	m.config.Limiter = limiter
+	m.limiterCeiling.Store(limiter.Ceiling)
+	return nil
+}
+
+// SetLimiterCeiling changes the limiter ceiling in dBFS, also while running
+func (m *Mixer) SetLimiterCeiling(ceiling float32) {
+	m.limiterCeiling.Store(ceiling)
+}
+
+// GetLatency returns the current processing latency: the time the last
+// output callback took plus the delay the limiter and input processing add
 func (m *Mixer) GetLatency() time.Duration {
 	return m.latency.Load().(time.Duration)
 }
 
+// BackendName returns the name of the backend the mixer opens streams through
+func (m *Mixer) BackendName() string {
+	return m.backend.Name()
+}
+
+// SetOutputDevice changes the output device used by the next Start
+func (m *Mixer) SetOutputDevice(dev *DeviceInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.State() == StateRunning {
+		return fmt.Errorf("cannot change output device while mixer is running")
+	}
+
+	m.config.OutputDevice = dev
+	return nil
+}
+
 // IsRunning returns whether the mixer is currently running
 func (m *Mixer) IsRunning() bool {
-	return m.running.Load()
+	return m.State() == StateRunning
 }
@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// PanLaw selects how an input's pan position splits it between the left and
+// right channel of a stereo mix
+type PanLaw int32
+
+const (
+	PanLawLinear        PanLaw = iota // Balance: 0 dB at centre, the opposite side fades out linearly
+	PanLawConstantPower               // sin/cos taper, -3 dB at centre, constant total power
+	PanLawMinus6dB                    // Straight-line taper, -6 dB at centre, constant total amplitude
+)
+
+// panLawAliases are earlier names ParsePanLaw still accepts
+var panLawAliases = map[string]PanLaw{
+	"-3dB": PanLawConstantPower,
+}
+
+// panLawNames are the names used by String and ParsePanLaw
+var panLawNames = map[PanLaw]string{
+	PanLawLinear:        "linear",
+	PanLawConstantPower: "constant_power",
+	PanLawMinus6dB:      "-6dB",
+}
+
+// String returns the pan law name
+func (l PanLaw) String() string {
+	if name, ok := panLawNames[l]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParsePanLaw returns the pan law with the given name; an empty name
+// selects PanLawLinear, which leaves centred inputs at unity gain
+func ParsePanLaw(name string) (PanLaw, error) {
+	if name == "" {
+		return PanLawLinear, nil
+	}
+	if law, ok := panLawAliases[name]; ok {
+		return law, nil
+	}
+	for law, lawName := range panLawNames {
+		if lawName == name {
+			return law, nil
+		}
+	}
+	return PanLawLinear, fmt.Errorf("unknown pan law %q", name)
+}
+
+// Gains returns the left and right gain for a pan position from -1.0 (hard
+// left) through 0.0 (centre) to 1.0 (hard right)
+func (l PanLaw) Gains(pan float32) (left, right float32) {
+	pan = clampPan(pan)
+	x := float64(pan+1) / 2 // 0 = left, 1 = right
+
+	switch l {
+	case PanLawConstantPower:
+		return float32(math.Cos(x * math.Pi / 2)), float32(math.Sin(x * math.Pi / 2))
+	case PanLawMinus6dB:
+		return float32(1 - x), float32(x)
+	default:
+		return min(1, 1-pan), min(1, 1+pan)
+	}
+}
+
+// clampPan limits a pan position to the supported -1.0 to 1.0 range
+func clampPan(pan float32) float32 {
+	if pan < -1 {
+		return -1
+	}
+	if pan > 1 {
+		return 1
+	}
+	return pan
+}
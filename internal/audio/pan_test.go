@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPanLawGains(t *testing.T) {
+	for _, tc := range []struct {
+		law         PanLaw
+		pan         float32
+		left, right float64
+	}{
+		{PanLawLinear, 0, 1, 1},
+		{PanLawLinear, 0.5, 0.5, 1},
+		{PanLawLinear, -1, 1, 0},
+		{PanLawConstantPower, 0, math.Sqrt2 / 2, math.Sqrt2 / 2},
+		{PanLawConstantPower, 0.5, math.Cos(3 * math.Pi / 8), math.Sin(3 * math.Pi / 8)},
+		{PanLawConstantPower, 1, 0, 1},
+		{PanLawMinus6dB, 0, 0.5, 0.5},
+		{PanLawMinus6dB, 0.5, 0.25, 0.75},
+		{PanLawMinus6dB, -1, 1, 0},
+	} {
+		left, right := tc.law.Gains(tc.pan)
+		if math.Abs(float64(left)-tc.left) > 1e-6 || math.Abs(float64(right)-tc.right) > 1e-6 {
+			t.Errorf("%v at %v: %v, %v; want %v, %v", tc.law, tc.pan, left, right, tc.left, tc.right)
+		}
+	}
+
+	// Constant power keeps the power, -6 dB the amplitude, across the field
+	for pan := float32(-1); pan <= 1; pan += 0.125 {
+		left, right := PanLawConstantPower.Gains(pan)
+		if power := left*left + right*right; math.Abs(float64(power)-1) > 1e-6 {
+			t.Errorf("constant power at %v: power %v, want 1", pan, power)
+		}
+		left, right = PanLawMinus6dB.Gains(pan)
+		if sum := left + right; math.Abs(float64(sum)-1) > 1e-6 {
+			t.Errorf("-6 dB at %v: amplitude %v, want 1", pan, sum)
+		}
+	}
+}
+
+func TestParsePanLaw(t *testing.T) {
+	for name, want := range map[string]PanLaw{
+		"":               PanLawLinear,
+		"linear":         PanLawLinear,
+		"constant_power": PanLawConstantPower,
+		"-3dB":           PanLawConstantPower,
+		"-6dB":           PanLawMinus6dB,
+	} {
+		if law, err := ParsePanLaw(name); err != nil || law != want {
+			t.Errorf("ParsePanLaw(%q) = %v, %v; want %v", name, law, err, want)
+		}
+	}
+	if _, err := ParsePanLaw("-4.5dB"); err == nil {
+		t.Error("ParsePanLaw accepted an unknown law")
+	}
+	for law, name := range panLawNames {
+		if parsed, _ := ParsePanLaw(name); parsed != law || law.String() != name {
+			t.Errorf("%v does not round trip through %q", law, name)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+	"sync/atomic"
+	"time"
+)
+
+// PitchQuality trades the pitch shifter's latency for sound quality: longer
+// frames resolve low voices better but hold audio back longer
+type PitchQuality int
+
+const (
+	PitchQualityLow    PitchQuality = iota // About 10 ms frames
+	PitchQualityMedium                     // About 20 ms frames
+	PitchQualityHigh                       // About 40 ms frames
+)
+
+const (
+	// PitchMaxShift is the furthest the pitch shifter moves pitch or
+	// formants, in semitones either way
+	PitchMaxShift = 12
+
+	// pitchFrameTime is the shortest analysis frame, at low quality;
+	// frames are the next power of two in samples and double with every
+	// quality step
+	pitchFrameTime = 10 * time.Millisecond
+
+	// pitchOverlap is how many frames overlap each sample
+	pitchOverlap = 4
+
+	// pitchEnvelopeWidth is the band the spectral envelope is averaged
+	// over, wide enough to smooth over the harmonics of most voices
+	pitchEnvelopeWidth = 400.0 // Hz
+)
+
+// pitchConfig configures a pitch shifter
+type pitchConfig struct {
+	Enabled   bool
+	Semitones float32      // Pitch shift, -PitchMaxShift to PitchMaxShift
+	Preserve  bool         // Keep the formants, the voice's timbre, in place
+	Formant   float32      // Formant shift in semitones while preserving, for voice effects
+	Quality   PitchQuality // Frame length
+}
+
+// defaultPitchConfig returns an unshifted, formant preserving shifter
+func defaultPitchConfig() pitchConfig {
+	return pitchConfig{
+		Enabled:  true,
+		Preserve: true,
+		Quality:  PitchQualityMedium,
+	}
+}
+
+// pitchShifter shifts pitch with a phase vocoder, locked to the spectral
+// peaks after Laroche and Dolson. Every channel is cut into Hann windowed
+// frames overlapping pitchOverlap times. Each peak's true frequency is
+// estimated from how far its phase advanced since the last frame, and the
+// bins around it move as one block to the shifted frequency, their phases
+// rotated together so the peak's phase keeps advancing at its new
+// frequency; moving whole peaks keeps every partial's shape and level. With
+// formants preserved, each bin is divided by the spectral envelope where it
+// came from and multiplied by the envelope where it lands, so the
+// resonances of the voice stay put (or move by the formant shift) while its
+// harmonics move. The output lags the input by one frame, whose length the
+// quality setting sets.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type pitchShifter struct {
+	config     atomic.Value // pitchConfig
+	sampleRate float64
+	channels   int
+	maxSize    int
+
+	// Transforms and windows of every quality
+	ffts    [PitchQualityHigh + 1]*fft
+	windows [PitchQualityHigh + 1][]float64
+
+	// Output callback state: the settings in use with their frame length
+	// and hop, the position in the current frame, per channel FIFOs,
+	// overlap-add accumulators and the phases of the last input and output
+	// frame ([channel*maxSize+i]), and the working spectra of the current
+	// frame
+	active    pitchConfig
+	size      int
+	hop       int
+	rover     int
+	inFIFO    []float32
+	outFIFO   []float32
+	accum     []float64
+	lastPhase []float64
+	outPhase  []float64
+	spectrum  []complex128
+	magnitude []float64
+	phase     []float64
+	frequency []float64 // True frequency in bins
+	envelope  []float64
+	synMag    []float64
+	synPhase  []float64
+	strongest []float64 // Largest magnitude moved to each bin, which sets its phase
+	prefix    []float64 // Running sum of magnitudes for the envelope
+}
+
+// newPitchShifter creates a pitch shifter for interleaved audio at sampleRate
+func newPitchShifter(cfg pitchConfig, channels int, sampleRate float64) *pitchShifter {
+	p := &pitchShifter{
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	for q := range p.ffts {
+		size := pitchFrameSize(PitchQuality(q), sampleRate)
+		p.ffts[q] = newFFT(size)
+		p.windows[q] = make([]float64, size)
+		for i := range p.windows[q] {
+			p.windows[q][i] = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(size)))
+		}
+		p.maxSize = max(p.maxSize, size)
+	}
+
+	n := channels * p.maxSize
+	bins := p.maxSize/2 + 1
+	p.inFIFO = make([]float32, n)
+	p.outFIFO = make([]float32, n)
+	p.accum = make([]float64, n)
+	p.lastPhase = make([]float64, n)
+	p.outPhase = make([]float64, n)
+	p.spectrum = make([]complex128, p.maxSize)
+	p.magnitude = make([]float64, bins)
+	p.phase = make([]float64, bins)
+	p.frequency = make([]float64, bins)
+	p.envelope = make([]float64, bins)
+	p.synMag = make([]float64, bins)
+	p.synPhase = make([]float64, bins)
+	p.strongest = make([]float64, bins)
+	p.prefix = make([]float64, bins+1)
+
+	p.config.Store(cfg)
+	p.reset()
+	return p
+}
+
+// pitchFrameSize returns the frame length of quality at sampleRate, a power
+// of two
+func pitchFrameSize(quality PitchQuality, sampleRate float64) int {
+	quality = min(max(quality, PitchQualityLow), PitchQualityHigh)
+	size := 1
+	for float64(size) < pitchFrameTime.Seconds()*sampleRate {
+		size *= 2
+	}
+	return size << quality
+}
+
+// set publishes new settings, applied from the next buffer on
+func (p *pitchShifter) set(cfg pitchConfig) {
+	p.config.Store(cfg)
+}
+
+// get returns the current settings
+func (p *pitchShifter) get() pitchConfig {
+	return p.config.Load().(pitchConfig)
+}
+
+// delay returns the latency the pitch shifter adds, one frame, zero while
+// it is disabled
+func (p *pitchShifter) delay() time.Duration {
+	cfg := p.get()
+	if !cfg.Enabled {
+		return 0
+	}
+	return framesDuration(pitchFrameSize(cfg.Quality, p.sampleRate), p.sampleRate)
+}
+
+// configure applies cfg, starting over from silence when the frame length
+// changes or the shifter is switched on
+func (p *pitchShifter) configure(cfg pitchConfig) {
+	size := pitchFrameSize(cfg.Quality, p.sampleRate)
+	restart := size != p.size || (cfg.Enabled && !p.active.Enabled)
+	p.active = cfg
+	if restart {
+		p.size = size
+		p.hop = size / pitchOverlap
+		p.clearFrames()
+	}
+}
+
+// reset clears the audio in flight
+func (p *pitchShifter) reset() {
+	p.configure(p.get())
+	p.clearFrames()
+}
+
+// clearFrames drops the audio in flight and restarts the first frame
+func (p *pitchShifter) clearFrames() {
+	clear(p.inFIFO)
+	clear(p.outFIFO)
+	clear(p.accum)
+	clear(p.lastPhase)
+	clear(p.outPhase)
+	p.rover = p.size - p.hop
+}
+
+// process shifts the pitch of buf in place
+func (p *pitchShifter) process(buf []float32) {
+	if cfg := p.get(); cfg != p.active {
+		p.configure(cfg)
+	}
+	if !p.active.Enabled {
+		return
+	}
+
+	ch := p.channels
+	for f := 0; f < len(buf)/ch; f++ {
+		for c := 0; c < ch; c++ {
+			p.inFIFO[c*p.maxSize+p.rover] = buf[f*ch+c]
+			buf[f*ch+c] = p.outFIFO[c*p.maxSize+p.rover-(p.size-p.hop)]
+		}
+		p.rover++
+		if p.rover == p.size {
+			for c := 0; c < ch; c++ {
+				p.processFrame(c)
+			}
+			p.rover = p.size - p.hop
+		}
+	}
+}
+
+// processFrame shifts the frame in channel c's input FIFO, adds it to the
+// accumulator and moves the next hop of output to the output FIFO
+func (p *pitchShifter) processFrame(c int) {
+	size, hop := p.size, p.hop
+	bins := size/2 + 1
+	quality := min(max(p.active.Quality, PitchQualityLow), PitchQualityHigh)
+	window := p.windows[quality]
+	in := p.inFIFO[c*p.maxSize : c*p.maxSize+size]
+	out := p.outFIFO[c*p.maxSize : c*p.maxSize+size]
+	accum := p.accum[c*p.maxSize : c*p.maxSize+size]
+	lastPhase := p.lastPhase[c*p.maxSize : c*p.maxSize+bins]
+	outPhase := p.outPhase[c*p.maxSize : c*p.maxSize+bins]
+	spectrum := p.spectrum[:size]
+
+	for i, sample := range in {
+		spectrum[i] = complex(float64(sample)*window[i], 0)
+	}
+	p.ffts[quality].forward(spectrum)
+
+	// Analysis: magnitude, phase and true frequency of every bin
+	expected := 2 * math.Pi * float64(hop) / float64(size) // Phase advance per bin and hop
+	magnitude, phase, frequency := p.magnitude[:bins], p.phase[:bins], p.frequency[:bins]
+	for k := range magnitude {
+		magnitude[k], phase[k] = cmplx.Abs(spectrum[k]), cmplx.Phase(spectrum[k])
+		deviation := phase[k] - lastPhase[k] - float64(k)*expected
+		deviation -= 2 * math.Pi * math.Round(deviation/(2*math.Pi))
+		frequency[k] = float64(k) + deviation/expected
+	}
+	copy(lastPhase, phase)
+	if p.active.Preserve {
+		p.spectralEnvelope(bins)
+	}
+
+	// Move the region around every peak, reaching halfway to its
+	// neighbours, by the whole bins that bring the peak closest to its
+	// shifted frequency
+	ratio := math.Pow(2, float64(min(max(p.active.Semitones, -PitchMaxShift), PitchMaxShift))/12)
+	formantRatio := math.Pow(2, float64(min(max(p.active.Formant, -PitchMaxShift), PitchMaxShift))/12)
+	synMag, synPhase, strongest := p.synMag[:bins], p.synPhase[:bins], p.strongest[:bins]
+	clear(synMag)
+	clear(strongest)
+	lo := 0
+	for peak := 1; peak < bins-1; peak++ {
+		if magnitude[peak] <= magnitude[peak-1] || magnitude[peak] < magnitude[peak+1] {
+			continue
+		}
+		next := peak + 1
+		for next < bins-1 && (magnitude[next] <= magnitude[next-1] || magnitude[next] < magnitude[next+1]) {
+			next++
+		}
+		hi := min((peak+next+1)/2, bins)
+		if next == bins-1 {
+			hi = bins
+		}
+
+		shifted := frequency[peak] * ratio
+		shift := int(math.Round(shifted)) - peak
+		target := peak + shift
+		if target < 0 || target >= bins {
+			lo = hi
+			continue
+		}
+		// The phase the peak reaches at its new frequency, and the rotation
+		// that gets it there
+		rotation := outPhase[target] + shifted*expected - phase[peak]
+		for k := lo; k < hi; k++ {
+			j := k + shift
+			if j < 0 || j >= bins {
+				continue
+			}
+			m := magnitude[k]
+			if p.active.Preserve {
+				m *= p.envelopeAt(float64(j)/formantRatio, bins) / p.envelope[k]
+			}
+			synMag[j] += m
+			if m > strongest[j] {
+				strongest[j] = m
+				synPhase[j] = phase[k] + rotation
+			}
+		}
+		lo = hi
+	}
+
+	// Synthesis: rebuild the real frame from the lower half of the
+	// spectrum; bins nothing moved to keep their phase running
+	for k := range synMag {
+		if strongest[k] == 0 {
+			synPhase[k] = outPhase[k] + float64(k)*expected
+		}
+		outPhase[k] = math.Remainder(synPhase[k], 2*math.Pi)
+		spectrum[k] = cmplx.Rect(synMag[k], synPhase[k])
+	}
+	spectrum[0] = complex(real(spectrum[0]), 0)
+	spectrum[size/2] = complex(real(spectrum[size/2]), 0)
+	for k := 1; k < size/2; k++ {
+		spectrum[size-k] = cmplx.Conj(spectrum[k])
+	}
+	p.ffts[quality].inverse(spectrum)
+
+	// Hann windows on analysis and synthesis overlapping pitchOverlap times
+	// sum to 3/8 of the overlap
+	scale := 1 / (float64(size) * 3 / 8 * pitchOverlap)
+	for i := range accum {
+		accum[i] += real(spectrum[i]) * window[i] * scale
+	}
+	for i := 0; i < hop; i++ {
+		out[i] = float32(accum[i])
+	}
+	copy(accum, accum[hop:])
+	clear(accum[size-hop:])
+	copy(in, in[hop:])
+}
+
+// spectralEnvelope smooths the magnitudes of the current frame over
+// pitchEnvelopeWidth into the envelope
+func (p *pitchShifter) spectralEnvelope(bins int) {
+	half := max(1, int(pitchEnvelopeWidth/2*float64(p.size)/p.sampleRate))
+	for k := 0; k < bins; k++ {
+		p.prefix[k+1] = p.prefix[k] + p.magnitude[k]
+	}
+	for k := 0; k < bins; k++ {
+		lo, hi := max(k-half, 0), min(k+half+1, bins)
+		p.envelope[k] = (p.prefix[hi]-p.prefix[lo])/float64(hi-lo) + 1e-12
+	}
+}
+
+// envelopeAt returns the envelope at the fractional bin k
+func (p *pitchShifter) envelopeAt(k float64, bins int) float64 {
+	k = min(max(k, 0), float64(bins-1))
+	i := min(int(k), bins-2)
+	frac := k - float64(i)
+	return p.envelope[i] + frac*(p.envelope[i+1]-p.envelope[i])
+}
+
+// pitchParams are the pitch shifter's processor parameters
+var pitchParams = params[pitchConfig]{
+	boolParam("enabled", true, func(c *pitchConfig) *bool { return &c.Enabled }),
+	floatParam("semitones", -PitchMaxShift, PitchMaxShift, 0, "st", func(c *pitchConfig) *float32 { return &c.Semitones }),
+	boolParam("preserve", true, func(c *pitchConfig) *bool { return &c.Preserve }),
+	floatParam("formant", -PitchMaxShift, PitchMaxShift, 0, "st", func(c *pitchConfig) *float32 { return &c.Formant }),
+	{
+		Parameter: Parameter{Name: "quality", Max: float64(PitchQualityHigh), Default: float64(PitchQualityMedium), Step: 1},
+		get:       func(c *pitchConfig) float64 { return float64(c.Quality) },
+		set:       func(c *pitchConfig, value float64) { c.Quality = PitchQuality(math.Round(value)) },
+	},
+}
+
+// Type implements Processor
+func (p *pitchShifter) Type() string { return "pitch" }
+
+// Channels implements Processor
+func (p *pitchShifter) Channels() int { return p.channels }
+
+// Process implements Processor
+func (p *pitchShifter) Process(buf []float32) { p.process(buf) }
+
+// Latency implements Processor; it follows the quality setting
+func (p *pitchShifter) Latency() time.Duration { return p.delay() }
+
+// MaxLatency implements VariableLatency; it is the latency at high quality
+func (p *pitchShifter) MaxLatency() time.Duration {
+	return framesDuration(pitchFrameSize(PitchQualityHigh, p.sampleRate), p.sampleRate)
+}
+
+// Reset implements Processor
+func (p *pitchShifter) Reset() { p.reset() }
+
+// Parameters implements Processor
+func (p *pitchShifter) Parameters() []Parameter { return pitchParams.parameters() }
+
+// Parameter implements Processor
+func (p *pitchShifter) Parameter(name string) (float64, error) {
+	return pitchParams.value(p.get(), name)
+}
+
+// SetParameter implements Processor
+func (p *pitchShifter) SetParameter(name string, value float64) error {
+	cfg, err := pitchParams.update(p.get(), name, value)
+	if err == nil {
+		p.set(cfg)
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package audio
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Processor is one step of an insert chain. Process runs in the output
+// callback; Reset is only called while the mixer is stopped; the parameter
+// methods may be called from any goroutine while audio runs.
+type Processor interface {
+	// Type returns the name the processor is registered under
+	Type() string
+
+	// Channels returns the channel count the processor was created for
+	Channels() int
+
+	// Process processes buf, interleaved audio of the channel count the
+	// processor was created for, in place
+	Process(buf []float32)
+
+	// Latency returns how far the processor delays audio
+	Latency() time.Duration
+
+	// Reset clears the processor's state, such as filter memory
+	Reset()
+
+	// Parameters describes the processor's settings
+	Parameters() []Parameter
+
+	// Parameter returns the value of the named setting
+	Parameter(name string) (float64, error)
+
+	// SetParameter changes the named setting, applied from the next buffer
+	// on; values outside the parameter's range are clamped
+	SetParameter(name string, value float64) error
+}
+
+// VariableLatency is implemented by processors whose latency changes with
+// their settings. MaxLatency returns the most they can delay audio, which an
+// insert chain sets aside room for to keep a bypassed processor's latency.
+type VariableLatency interface {
+	MaxLatency() time.Duration
+}
+
+// Parameter describes one setting of a processor. Switches range from 0
+// (off) to 1 (on) and choices from 0 to the number of choices minus one,
+// both in steps of 1.
+type Parameter struct {
+	Name    string
+	Min     float64
+	Max     float64
+	Default float64
+	Step    float64 // Smallest meaningful change; 0 for continuous values
+	Unit    string  // For display, e.g. "dB", "ms" or "Hz"; empty for none
+}
+
+// ProcessorFactory creates a processor for interleaved audio of channels
+// channels at sampleRate
+type ProcessorFactory func(channels int, sampleRate float64) Processor
+
+var (
+	processorsMu sync.RWMutex
+	processors   = map[string]ProcessorFactory{}
+)
+
+// RegisterProcessor makes a processor type available to NewProcessor and
+// insert chains under name, replacing any earlier one of that name
+func RegisterProcessor(name string, factory ProcessorFactory) {
+	processorsMu.Lock()
+	defer processorsMu.Unlock()
+	processors[name] = factory
+}
+
+// ProcessorTypes returns the names of all registered processor types, sorted
+func ProcessorTypes() []string {
+	processorsMu.RLock()
+	defer processorsMu.RUnlock()
+
+	names := make([]string, 0, len(processors))
+	for name := range processors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProcessor creates a processor of a registered type with its default
+// settings, switched on
+func NewProcessor(name string, channels int, sampleRate float64) (Processor, error) {
+	processorsMu.RLock()
+	factory, ok := processors[name]
+	processorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown processor type %q", name)
+	}
+	if channels < 1 || sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid processor format: %d channels at %v Hz", channels, sampleRate)
+	}
+	return factory(channels, sampleRate), nil
+}
+
+// The built-in processor types
+func init() {
+	RegisterProcessor("denoiser", func(channels int, sampleRate float64) Processor {
+		cfg := DefaultDenoiserConfig()
+		cfg.Enabled = true
+		return newDenoiser(cfg, channels, sampleRate)
+	})
+	RegisterProcessor("gate", func(channels int, sampleRate float64) Processor {
+		cfg := DefaultGateConfig()
+		cfg.Enabled = true
+		return newGate(cfg, channels, sampleRate)
+	})
+	RegisterProcessor("eq", func(channels int, sampleRate float64) Processor {
+		return newEqualizer(EQConfig{Enabled: true}, channels, sampleRate)
+	})
+	RegisterProcessor("agc", func(channels int, sampleRate float64) Processor {
+		cfg := DefaultAGCConfig()
+		cfg.Enabled = true
+		return newAGC(cfg, channels, sampleRate)
+	})
+	RegisterProcessor("compressor", func(channels int, sampleRate float64) Processor {
+		cfg := DefaultCompressorConfig()
+		cfg.Enabled = true
+		return newCompressor(cfg, channels, sampleRate)
+	})
+	RegisterProcessor("deesser", func(channels int, sampleRate float64) Processor {
+		cfg := DefaultDeEsserConfig()
+		cfg.Enabled = true
+		return newDeEsser(cfg, channels, sampleRate)
+	})
+	RegisterProcessor("reverb", func(channels int, sampleRate float64) Processor {
+		return newReverb(defaultReverbConfig(), channels, sampleRate)
+	})
+	RegisterProcessor("echo", func(channels int, sampleRate float64) Processor {
+		return newEcho(defaultEchoConfig(), channels, sampleRate)
+	})
+	RegisterProcessor("pitch", func(channels int, sampleRate float64) Processor {
+		return newPitchShifter(defaultPitchConfig(), channels, sampleRate)
+	})
+}
+
+// param binds a Parameter to a field of the settings struct T of a
+// processor
+type param[T any] struct {
+	Parameter
+	get func(cfg *T) float64
+	set func(cfg *T, value float64)
+}
+
+// params is the parameter table of a processor with settings T
+type params[T any] []param[T]
+
+// parameters returns the parameter descriptions
+func (p params[T]) parameters() []Parameter {
+	out := make([]Parameter, len(p))
+	for i := range p {
+		out[i] = p[i].Parameter
+	}
+	return out
+}
+
+// find returns the named parameter
+func (p params[T]) find(name string) (*param[T], error) {
+	for i := range p {
+		if p[i].Name == name {
+			return &p[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown parameter %q", name)
+}
+
+// value reads the named parameter from cfg
+func (p params[T]) value(cfg T, name string) (float64, error) {
+	par, err := p.find(name)
+	if err != nil {
+		return 0, err
+	}
+	return par.get(&cfg), nil
+}
+
+// update sets the named parameter in a copy of cfg, clamped to its range
+func (p params[T]) update(cfg T, name string, value float64) (T, error) {
+	par, err := p.find(name)
+	if err != nil {
+		return cfg, err
+	}
+	par.set(&cfg, min(max(value, par.Min), par.Max))
+	return cfg, nil
+}
+
+// boolParam binds a switch
+func boolParam[T any](name string, def bool, field func(cfg *T) *bool) param[T] {
+	return param[T]{
+		Parameter: Parameter{Name: name, Max: 1, Default: boolValue(def), Step: 1},
+		get:       func(cfg *T) float64 { return boolValue(*field(cfg)) },
+		set:       func(cfg *T, value float64) { *field(cfg) = value >= 0.5 },
+	}
+}
+
+// floatParam binds a float32 setting
+func floatParam[T any](name string, min, max, def float64, unit string, field func(cfg *T) *float32) param[T] {
+	return param[T]{
+		Parameter: Parameter{Name: name, Min: min, Max: max, Default: def, Unit: unit},
+		get:       func(cfg *T) float64 { return float64(*field(cfg)) },
+		set:       func(cfg *T, value float64) { *field(cfg) = float32(value) },
+	}
+}
+
+// msParam binds a duration setting, in milliseconds
+func msParam[T any](name string, min, max, def float64, field func(cfg *T) *time.Duration) param[T] {
+	return param[T]{
+		Parameter: Parameter{Name: name, Min: min, Max: max, Default: def, Unit: "ms"},
+		get:       func(cfg *T) float64 { return float64(*field(cfg)) / float64(time.Millisecond) },
+		set:       func(cfg *T, value float64) { *field(cfg) = time.Duration(value * float64(time.Millisecond)) },
+	}
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package audio
+
+import (
+	"math"
+)
+
+const (
+	// Resampler kernel design
+	resamplerZeroCrossings = 16    // Sinc zero crossings on each side of the kernel
+	resamplerPhases        = 256   // Kernel table entries per input frame
+	resamplerRolloff       = 0.945 // Passband edge relative to the lower Nyquist frequency
+	resamplerKaiserBeta    = 9.0   // Kaiser window shape, about 90 dB stopband rejection
+)
+
+// resampler converts interleaved audio between two sample rates with a
+// Kaiser-windowed sinc interpolator. Input is pushed in blocks of any size
+// and output pulled as soon as enough input has arrived, so it can sit
+// between callbacks that run at different rates and buffer sizes.
+//
+// It is not safe for concurrent use.
+type resampler struct {
+	channels int
+	step     float64 // Input frames advanced per output frame
+	width    int     // Kernel half width in input frames
+	kernel   []float32
+
+	// history holds interleaved input frames still needed by the kernel;
+	// pos is the position of the next output frame in it, in frames
+	history []float32
+	pos     float64
+}
+
+// newResampler creates a resampler from fromRate to toRate for input blocks
+// of up to maxFrames frames
+func newResampler(channels int, fromRate, toRate float64, maxFrames int) *resampler {
+	step := fromRate / toRate
+
+	// Band-limit to the lower of the two Nyquist frequencies
+	cutoff := resamplerRolloff * min(1, 1/step)
+	width := int(math.Ceil(resamplerZeroCrossings / cutoff))
+
+	// Tabulate one side of the symmetric kernel; the trailing zero lets
+	// lookups interpolate up to the very edge
+	kernel := make([]float32, width*resamplerPhases+2)
+	norm := besselI0(resamplerKaiserBeta)
+	for i := 0; i <= width*resamplerPhases; i++ {
+		x := float64(i) / resamplerPhases
+		w := x / float64(width)
+		window := besselI0(resamplerKaiserBeta*math.Sqrt(1-w*w)) / norm
+		kernel[i] = float32(cutoff * sinc(cutoff*x) * window)
+	}
+
+	r := &resampler{
+		channels: channels,
+		step:     step,
+		width:    width,
+		kernel:   kernel,
+		history:  make([]float32, 0, (maxFrames+2*width+2)*channels),
+	}
+	r.reset()
+	return r
+}
+
+// reset drops all buffered input
+func (r *resampler) reset() {
+	// Start with silence on the left so the first output frame lines up
+	// with the first input frame
+	r.history = r.history[:(r.width-1)*r.channels]
+	for i := range r.history {
+		r.history[i] = 0
+	}
+	r.pos = float64(r.width - 1)
+}
+
+// maxOutput returns how many output frames pushing frames input frames can produce
+func (r *resampler) maxOutput(frames int) int {
+	return int(math.Ceil(float64(frames)/r.step)) + 1
+}
+
+// push appends interleaved input samples
+func (r *resampler) push(in []float32) {
+	r.history = append(r.history, in...)
+}
+
+// pull fills out with as many converted frames as the buffered input allows
+// and returns the number of frames written
+func (r *resampler) pull(out []float32) int {
+	ch := r.channels
+	have := len(r.history) / ch
+
+	n := 0
+	for ; n < len(out)/ch; n++ {
+		i0 := int(r.pos)
+		if i0+r.width >= have {
+			break
+		}
+		frac := r.pos - float64(i0)
+
+		frame := out[n*ch : n*ch+ch]
+		for c := range frame {
+			frame[c] = 0
+		}
+		for k := 1 - r.width; k <= r.width; k++ {
+			w := r.tap(math.Abs(float64(k) - frac))
+			in := r.history[(i0+k)*ch : (i0+k)*ch+ch]
+			for c := range frame {
+				frame[c] += w * in[c]
+			}
+		}
+		r.pos += r.step
+	}
+
+	// Drop input frames left of the kernel for the next output frame
+	if drop := min(int(r.pos)-r.width+1, have); drop > 0 {
+		remaining := copy(r.history, r.history[drop*ch:])
+		r.history = r.history[:remaining]
+		r.pos -= float64(drop)
+	}
+	return n
+}
+
+// tap returns the kernel value at distance x input frames from the centre
+func (r *resampler) tap(x float64) float32 {
+	t := x * resamplerPhases
+	i := int(t)
+	if i >= len(r.kernel)-1 {
+		return 0
+	}
+	f := float32(t - float64(i))
+	return r.kernel[i] + (r.kernel[i+1]-r.kernel[i])*f
+}
+
+// sinc is the normalized sinc function sin(pi x) / (pi x)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// besselI0 is the zeroth order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
@@ -0,0 +1,238 @@
+package audio
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Freeverb's tuning at 44.1 kHz: the delays of its parallel comb filters
+// and serial all-pass filters in frames
+var (
+	reverbCombTuning    = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllPassTuning = []int{556, 441, 341, 225}
+)
+
+const (
+	reverbStereoSpread = 23 // Frames the right tank's delays are longer by
+	reverbTuningRate   = 44100
+	reverbInputGain    = 0.015 // Keeps the comb filters' sum in range
+	reverbWetGain      = 3
+	reverbMaxPreDelay  = 200 * time.Millisecond
+)
+
+// reverbConfig configures a reverb
+type reverbConfig struct {
+	Enabled  bool
+	Size     float32       // Room size, 0 to 1
+	Damping  float32       // Loss of treble as the reverb decays, 0 to 1
+	Width    float32       // Stereo width, 0 (mono) to 1
+	PreDelay time.Duration // Gap before the reverb sets in
+	Mix      float32       // Reverb in the output, 0 (dry) to 1 (reverb only, for aux buses)
+}
+
+// defaultReverbConfig returns a medium room, all reverb for an aux bus
+func defaultReverbConfig() reverbConfig {
+	return reverbConfig{
+		Enabled:  true,
+		Size:     0.5,
+		Damping:  0.5,
+		Width:    1,
+		PreDelay: 10 * time.Millisecond,
+		Mix:      1,
+	}
+}
+
+// reverbComb is a lowpass-feedback comb filter
+type reverbComb struct {
+	buf    []float32
+	pos    int
+	filter float32
+}
+
+// process runs one sample through the comb
+func (c *reverbComb) process(x, feedback, damp float32) float32 {
+	y := c.buf[c.pos]
+	c.filter = y*(1-damp) + c.filter*damp
+	c.buf[c.pos] = x + c.filter*feedback
+	c.pos++
+	if c.pos == len(c.buf) {
+		c.pos = 0
+	}
+	return y
+}
+
+// reverbAllPass is a Schroeder all-pass filter
+type reverbAllPass struct {
+	buf []float32
+	pos int
+}
+
+// process runs one sample through the all-pass
+func (a *reverbAllPass) process(x float32) float32 {
+	delayed := a.buf[a.pos]
+	a.buf[a.pos] = x + delayed*0.5
+	a.pos++
+	if a.pos == len(a.buf) {
+		a.pos = 0
+	}
+	return delayed - x
+}
+
+// reverbTank is the comb and all-pass network of one output channel
+type reverbTank struct {
+	combs     []reverbComb
+	allPasses []reverbAllPass
+}
+
+// process returns the tank's response to one sample
+func (t *reverbTank) process(x, feedback, damp float32) float32 {
+	var y float32
+	for i := range t.combs {
+		y += t.combs[i].process(x, feedback, damp)
+	}
+	for i := range t.allPasses {
+		y = t.allPasses[i].process(y)
+	}
+	return y
+}
+
+// reverb is an algorithmic reverb after Jezar's Freeverb: the input,
+// mixed down to mono and pre-delayed, feeds a left and a right tank of
+// eight damped comb filters into four all-pass filters, whose outputs are
+// crossmixed for width. Even channels get the left tank, odd ones the
+// right.
+//
+// Settings are published through config and picked up by process, which is
+// only called from the output callback.
+type reverb struct {
+	config     atomic.Value // reverbConfig
+	sampleRate float64
+	channels   int
+
+	// Output callback state: the tanks and the pre-delay ring
+	tanks    [2]reverbTank
+	preDelay []float32
+	prePos   int
+}
+
+// newReverb creates a reverb for interleaved audio at sampleRate
+func newReverb(cfg reverbConfig, channels int, sampleRate float64) *reverb {
+	r := &reverb{sampleRate: sampleRate, channels: channels}
+	scale := sampleRate / reverbTuningRate
+	for side := range r.tanks {
+		spread := side * reverbStereoSpread
+		for _, frames := range reverbCombTuning {
+			r.tanks[side].combs = append(r.tanks[side].combs, reverbComb{buf: make([]float32, int(float64(frames+spread)*scale))})
+		}
+		for _, frames := range reverbAllPassTuning {
+			r.tanks[side].allPasses = append(r.tanks[side].allPasses, reverbAllPass{buf: make([]float32, int(float64(frames+spread)*scale))})
+		}
+	}
+	r.preDelay = make([]float32, int(reverbMaxPreDelay.Seconds()*sampleRate)+1)
+	r.config.Store(cfg)
+	return r
+}
+
+// set publishes new settings, applied from the next buffer on
+func (r *reverb) set(cfg reverbConfig) {
+	r.config.Store(cfg)
+}
+
+// get returns the current settings
+func (r *reverb) get() reverbConfig {
+	return r.config.Load().(reverbConfig)
+}
+
+// reset silences the reverb
+func (r *reverb) reset() {
+	for side := range r.tanks {
+		for i := range r.tanks[side].combs {
+			clear(r.tanks[side].combs[i].buf)
+			r.tanks[side].combs[i].filter = 0
+		}
+		for i := range r.tanks[side].allPasses {
+			clear(r.tanks[side].allPasses[i].buf)
+		}
+	}
+	clear(r.preDelay)
+}
+
+// process adds reverb to buf in place
+func (r *reverb) process(buf []float32) {
+	cfg := r.get()
+	if !cfg.Enabled {
+		return
+	}
+
+	ch := r.channels
+	feedback := 0.7 + 0.28*min(max(cfg.Size, 0), 1)
+	damp := 0.4 * min(max(cfg.Damping, 0), 1)
+	wet := reverbWetGain * cfg.Mix
+	wet1 := wet * (cfg.Width/2 + 0.5)
+	wet2 := wet * (1 - cfg.Width) / 2
+	dry := 1 - cfg.Mix
+	delay := min(durationFrames(cfg.PreDelay, r.sampleRate), len(r.preDelay)-1)
+
+	for f := 0; f < len(buf)/ch; f++ {
+		frame := buf[f*ch : f*ch+ch]
+		var sum float32
+		for _, sample := range frame {
+			sum += sample
+		}
+
+		// Pre-delay the mono input
+		r.preDelay[r.prePos] = sum * reverbInputGain * 2 / float32(ch)
+		x := r.preDelay[(r.prePos-delay+len(r.preDelay))%len(r.preDelay)]
+		r.prePos = (r.prePos + 1) % len(r.preDelay)
+
+		left := r.tanks[0].process(x, feedback, damp)
+		right := r.tanks[1].process(x, feedback, damp)
+		outs := [2]float32{left*wet1 + right*wet2, right*wet1 + left*wet2}
+		if ch == 1 {
+			outs[0] = (left + right) / 2 * wet
+		}
+		for c := range frame {
+			frame[c] = frame[c]*dry + outs[c%2]
+		}
+	}
+}
+
+// reverbParams are the reverb's processor parameters
+var reverbParams = params[reverbConfig]{
+	boolParam("enabled", true, func(c *reverbConfig) *bool { return &c.Enabled }),
+	floatParam("size", 0, 1, 0.5, "", func(c *reverbConfig) *float32 { return &c.Size }),
+	floatParam("damping", 0, 1, 0.5, "", func(c *reverbConfig) *float32 { return &c.Damping }),
+	floatParam("width", 0, 1, 1, "", func(c *reverbConfig) *float32 { return &c.Width }),
+	msParam("predelay", 0, float64(reverbMaxPreDelay/time.Millisecond), 10, func(c *reverbConfig) *time.Duration { return &c.PreDelay }),
+	floatParam("mix", 0, 1, 1, "", func(c *reverbConfig) *float32 { return &c.Mix }),
+}
+
+// Type implements Processor
+func (r *reverb) Type() string { return "reverb" }
+
+// Channels implements Processor
+func (r *reverb) Channels() int { return r.channels }
+
+// Process implements Processor
+func (r *reverb) Process(buf []float32) { r.process(buf) }
+
+// Latency implements Processor; the pre-delay is an effect, not latency
+func (r *reverb) Latency() time.Duration { return 0 }
+
+// Reset implements Processor
+func (r *reverb) Reset() { r.reset() }
+
+// Parameters implements Processor
+func (r *reverb) Parameters() []Parameter { return reverbParams.parameters() }
+
+// Parameter implements Processor
+func (r *reverb) Parameter(name string) (float64, error) { return reverbParams.value(r.get(), name) }
+
+// SetParameter implements Processor
+func (r *reverb) SetParameter(name string, value float64) error {
+	cfg, err := reverbParams.update(r.get(), name, value)
+	if err == nil {
+		r.set(cfg)
+	}
+	return err
+}
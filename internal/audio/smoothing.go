@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultGainRampTime is how long gain changes take by default
+	DefaultGainRampTime = 20 * time.Millisecond
+
+	// gainFloor is where dB-linear ramps to or from silence start and end (-80 dB)
+	gainFloor = 1e-4
+)
+
+// gainSmoother ramps a gain towards its target along a dB-linear
+// (exponential) curve, so equal times give equal loudness steps no matter
+// how far the gain moves. Ramps to or from zero run to or from gainFloor
+// and then snap.
+//
+// It is only used from the output callback and is not safe for concurrent use.
+type gainSmoother struct {
+	current   float32
+	target    float32
+	factor    float32 // Per-frame multiplier while ramping
+	remaining int     // Frames left in the current ramp
+	primed    bool
+}
+
+// setTarget starts a ramp to target over frames frames if the target changed.
+// The first target after a reset is applied immediately.
+func (g *gainSmoother) setTarget(target float32, frames int) {
+	if !g.primed {
+		g.current, g.target, g.remaining = target, target, 0
+		g.primed = true
+		return
+	}
+	if target == g.target {
+		return
+	}
+
+	g.target = target
+	if frames <= 0 {
+		g.current, g.remaining = target, 0
+		return
+	}
+
+	from := max(g.current, gainFloor)
+	to := max(target, gainFloor)
+	g.current = from
+	g.factor = float32(math.Pow(float64(to/from), 1/float64(frames)))
+	g.remaining = frames
+}
+
+// next returns the gain for the next frame
+func (g *gainSmoother) next() float32 {
+	if g.remaining == 0 {
+		return g.current
+	}
+
+	g.remaining--
+	if g.remaining == 0 {
+		g.current = g.target
+	} else {
+		g.current *= g.factor
+	}
+	return g.current
+}
+
+// settled returns whether the gain has reached its target
+func (g *gainSmoother) settled() bool {
+	return g.remaining == 0
+}
+
+// reset forgets the current gain; the next target is applied immediately
+func (g *gainSmoother) reset() {
+	*g = gainSmoother{}
+}
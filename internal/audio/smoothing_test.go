@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// smoothedSteps runs input through a gain smoother in buffers of 512
+// frames, as the output callback does, stepping the target gain from 1 to
+// gain after the first buffer, and returns the output
+func smoothedSteps(input []float32, gain float32, rampFrames int) []float32 {
+	var g gainSmoother
+	out := make([]float32, len(input))
+	target := float32(1)
+	for start := 0; start < len(input); start += 512 {
+		g.setTarget(target, rampFrames)
+		for i := start; i < min(start+512, len(input)); i++ {
+			out[i] = input[i] * g.next()
+		}
+		target = gain
+	}
+	return out
+}
+
+// maxJump returns the largest change between neighbouring samples
+func maxJump(samples []float32) float32 {
+	var jump float32
+	for i := 1; i < len(samples); i++ {
+		jump = max(jump, float32(math.Abs(float64(samples[i]-samples[i-1]))))
+	}
+	return jump
+}
+
+func TestGainSmootherStepHasNoDiscontinuity(t *testing.T) {
+	const sampleRate = 48000
+	rampFrames := durationFrames(DefaultGainRampTime, sampleRate)
+
+	dc := make([]float32, sampleRate/4)
+	sine := make([]float32, sampleRate/4)
+	for i := range dc {
+		dc[i] = 1
+		sine[i] = float32(math.Sin(2 * math.Pi * 1000 * float64(i) / sampleRate))
+	}
+	// The steepest step of the sine itself, which the gain may not add much to
+	sineJump := float32(2 * math.Pi * 1000 / sampleRate)
+
+	for _, tc := range []struct {
+		name string
+		gain float32
+	}{
+		{"-40 dB", dbToGain(-40)},
+		{"mute", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := smoothedSteps(dc, tc.gain, rampFrames)
+			if jump := maxJump(out); jump > 0.02 {
+				t.Errorf("DC: largest jump %v, want at most 0.02", jump)
+			}
+			if last := out[len(out)-1]; math.Abs(float64(last-tc.gain)) > 1e-6 {
+				t.Errorf("DC: settled at %v, want %v", last, tc.gain)
+			}
+
+			out = smoothedSteps(sine, tc.gain, rampFrames)
+			if jump := maxJump(out); jump > sineJump+0.02 {
+				t.Errorf("sine: largest jump %v, want at most %v", jump, sineJump+0.02)
+			}
+
+			// Without a ramp the same step is a click
+			if jump := maxJump(smoothedSteps(dc, tc.gain, 0)); jump < 0.9 {
+				t.Errorf("unramped step jumped only %v", jump)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package audio
+
+// MixerState is the lifecycle state of a Mixer:
+//
+//	Idle -> Starting -> Running -> Stopping -> Idle
+//
+// A failed Start or Stop leaves the mixer in Failed, from which it can be
+// started again.
+type MixerState int32
+
+const (
+	StateIdle MixerState = iota
+	StateStarting
+	StateRunning
+	StateStopping
+	StateFailed
+)
+
+// String returns the state name
+func (s MixerState) String() string {
+	switch s {
+	case StateIdle:
+		return "Idle"
+	case StateStarting:
+		return "Starting"
+	case StateRunning:
+		return "Running"
+	case StateStopping:
+		return "Stopping"
+	case StateFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// StateChange describes one lifecycle transition
+type StateChange struct {
+	From MixerState
+	To   MixerState
+	Err  error // Cause of the transition into StateFailed
+}
+
+// State returns the current lifecycle state
+func (m *Mixer) State() MixerState {
+	return MixerState(m.state.Load())
+}
+
+// LastError returns the error that put the mixer into StateFailed, if any
+func (m *Mixer) LastError() error {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+
+	return m.lastErr
+}
+
+// OnStateChange registers a listener called after every lifecycle transition.
+// Listeners run synchronously on the goroutine that caused the transition,
+// so they must return quickly and must not call Start, Stop, Restart,
+// AddInput or RemoveInput.
+func (m *Mixer) OnStateChange(listener func(StateChange)) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+
+	m.listeners = append(m.listeners, listener)
+}
+
+// setState moves the mixer to a new state and notifies listeners
+func (m *Mixer) setState(to MixerState, err error) {
+	from := MixerState(m.state.Swap(int32(to)))
+
+	m.listenersMu.Lock()
+	if to == StateFailed {
+		m.lastErr = err
+	}
+	listeners := make([]func(StateChange), len(m.listeners))
+	copy(listeners, m.listeners)
+	m.listenersMu.Unlock()
+
+	change := StateChange{From: from, To: to, Err: err}
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+// Restart stops the mixer if it is running and starts it again
+func (m *Mixer) Restart() error {
+	if err := m.Stop(); err != nil {
+		return err
+	}
+	return m.Start()
+}
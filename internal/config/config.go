@@ -3,10 +3,351 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
+// Special device indices for inputs and output
+const (
+	DeviceIndexDefault  = -1 // Use the default device (or auto-detect a loopback device)
+	DeviceIndexDisabled = -2 // Input is not used
+)
+
+// MaxInputs is the maximum number of mixer inputs
+const MaxInputs = 8
+
+// MaxChannels is the maximum number of mixer channels
+const MaxChannels = 32
+
+// Pan law names, see audio.PanLaw
+const (
+	PanLawLinear        = "linear"
+	PanLawConstantPower = "constant_power"
+	PanLawMinus6dB      = "-6dB"
+)
+
+// PanLaws lists the supported pan law names
+var PanLaws = []string{PanLawLinear, PanLawConstantPower, PanLawMinus6dB}
+
+// legacyPanLaws maps pan law names of older config files to the current ones
+var legacyPanLaws = map[string]string{
+	"-3dB": PanLawConstantPower, // The same -3 dB constant power curve
+}
+
+// MaxEQBands is the maximum number of bands of an equalizer
+const MaxEQBands = 8
+
+// MaxDelayMs is the longest delay an input can be given, in milliseconds
+const MaxDelayMs = 1000
+
+// MaxInserts is the maximum number of slots of an insert chain
+const MaxInserts = 16
+
+// MaxAuxBuses is the maximum number of aux buses
+const MaxAuxBuses = 4
+
+// Equalizer filter type names, see audio.FilterType
+const (
+	FilterPeaking   = "peaking"
+	FilterLowShelf  = "low_shelf"
+	FilterHighShelf = "high_shelf"
+	FilterLowPass   = "low_pass"
+	FilterHighPass  = "high_pass"
+	FilterNotch     = "notch"
+)
+
+// FilterTypes lists the supported equalizer filter type names
+var FilterTypes = []string{FilterPeaking, FilterLowShelf, FilterHighShelf, FilterLowPass, FilterHighPass, FilterNotch}
+
+// AGC level measurement names, see audio.AGCMode
+const (
+	AGCModeRMS  = "rms"
+	AGCModeLUFS = "lufs"
+)
+
+// AGCModes lists the supported AGC level measurement names
+var AGCModes = []string{AGCModeRMS, AGCModeLUFS}
+
+// HighPassCutoffs lists the suggested high-pass filter cutoffs in Hz
+var HighPassCutoffs = []float64{40, 60, 80, 100, 120, 150, 200}
+
+// DeEsserLowEdges and DeEsserHighEdges list the suggested edges of the
+// de-esser's sibilance band in Hz
+var (
+	DeEsserLowEdges  = []float64{2000, 3000, 4000, 5000}
+	DeEsserHighEdges = []float64{6000, 8000, 10000, 12000, 16000}
+)
+
+// InputConfig represents the configuration of one mixer input
+type InputConfig struct {
+	Name        string  `json:"name"`
+	DeviceIndex int     `json:"device_index"` // DeviceIndexDefault, DeviceIndexDisabled or a device index
+	Loopback    bool    `json:"loopback"`     // With DeviceIndexDefault, auto-detect a loopback device
+	Gain        float32 `json:"gain"`         // 0.0 to 2.0
+	Pan         float32 `json:"pan"`          // -1.0 (left) to 1.0 (right)
+
+	Mute           bool `json:"mute"`
+	Solo           bool `json:"solo"`
+	PolarityInvert bool `json:"polarity_invert"`
+
+	// Device channels to capture (1-based), mapped onto the mixer channels;
+	// empty captures the first ones
+	Channels []int `json:"channels,omitempty"`
+
+	// Delay after processing to line the input up with the others, 0 to
+	// 1000 ms
+	DelayMs float64 `json:"delay_ms,omitempty"`
+
+	// Send levels to the aux buses in order, 0.0 to 2.0; missing ones are 0
+	Sends []float32 `json:"sends,omitempty"`
+
+	// Filters run as samples are captured; nil runs DefaultFilterConfig
+	Filter *FilterConfig `json:"filter,omitempty"`
+
+	// Processing settings, nil if none were ever set
+	Denoiser   *DenoiserConfig   `json:"denoiser,omitempty"`
+	Gate       *GateConfig       `json:"gate,omitempty"`
+	EQ         *EQConfig         `json:"eq,omitempty"`
+	AGC        *AGCConfig        `json:"agc,omitempty"`
+	Compressor *CompressorConfig `json:"compressor,omitempty"`
+	DeEsser    *DeEsserConfig    `json:"deesser,omitempty"`
+
+	// Processing order; the built-in processors are named "denoiser",
+	// "gate", "eq", "agc", "compressor" and "deesser", and those left out
+	// follow the listed ones in that order, so empty keeps the default
+	Inserts []InsertConfig `json:"inserts,omitempty"`
+}
+
+// InsertConfig represents one slot of an insert chain
+type InsertConfig struct {
+	Type   string             `json:"type"` // A registered processor type, see audio.ProcessorTypes
+	Bypass bool               `json:"bypass"`
+	Params map[string]float64 `json:"params,omitempty"` // Settings of an added processor, by parameter name
+}
+
+// AuxBusConfig represents an aux bus the inputs send to, returning to the
+// master bus through its effects
+type AuxBusConfig struct {
+	Name       string         `json:"name"`
+	ReturnGain float32        `json:"return_gain"` // 0.0 to 2.0
+	Inserts    []InsertConfig `json:"inserts,omitempty"`
+}
+
+// FilterConfig represents the DC blocker and high-pass filter of an input
+type FilterConfig struct {
+	DCBlocker  bool    `json:"dc_blocker"`
+	HighPass   bool    `json:"high_pass"`
+	HighPassHz float64 `json:"high_pass_hz"` // 20 to 500
+}
+
+// DefaultFilterConfig returns the DC blocker on and the high-pass filter off
+func DefaultFilterConfig() FilterConfig {
+	return FilterConfig{
+		DCBlocker:  true,
+		HighPassHz: 80,
+	}
+}
+
+// DenoiserConfig represents the noise suppression settings of an input
+type DenoiserConfig struct {
+	Enabled  bool    `json:"enabled"`
+	Bypass   bool    `json:"bypass"`   // Pass audio through, keeping the latency and the noise profile
+	Strength float32 `json:"strength"` // 0.0 to 1.0
+	Adaptive bool    `json:"adaptive"` // Track the noise continuously rather than only learn it on request
+}
+
+// DefaultDenoiserConfig returns noise suppression settings for steady noise, switched off
+func DefaultDenoiserConfig() DenoiserConfig {
+	return DenoiserConfig{
+		Strength: 0.7,
+		Adaptive: true,
+	}
+}
+
+// AGCConfig represents the automatic gain control settings of an input
+type AGCConfig struct {
+	Enabled      bool    `json:"enabled"`
+	Mode         string  `json:"mode"`            // One of AGCModes
+	TargetDB     float32 `json:"target_db"`       // -60.0 to 0.0, dBFS RMS or LUFS
+	MaxBoostDB   float32 `json:"max_boost_db"`    // 0.0 to 40.0
+	MaxCutDB     float32 `json:"max_cut_db"`      // 0.0 to 40.0
+	RateDBPerSec float32 `json:"rate_db_per_sec"` // 0.1 to 60.0
+	FreezeDB     float32 `json:"freeze_db"`       // -90.0 to 0.0 dBFS, silence below which the gain holds
+}
+
+// DefaultAGCConfig returns AGC settings for speech, switched off
+func DefaultAGCConfig() AGCConfig {
+	return AGCConfig{
+		Mode:         AGCModeRMS,
+		TargetDB:     -20,
+		MaxBoostDB:   20,
+		MaxCutDB:     12,
+		RateDBPerSec: 3,
+		FreezeDB:     -50,
+	}
+}
+
+// EQBandConfig represents one band of an equalizer
+type EQBandConfig struct {
+	Type        string  `json:"type"`         // One of FilterTypes
+	FrequencyHz float64 `json:"frequency_hz"` // 10 to 20000, below half the sample rate
+	GainDB      float32 `json:"gain_db"`      // -24.0 to 24.0, peaking and shelf bands only
+	Q           float32 `json:"q"`            // 0.1 to 18.0
+}
+
+// EQConfig represents a parametric equalizer
+type EQConfig struct {
+	Enabled bool           `json:"enabled"`
+	Bands   []EQBandConfig `json:"bands"`
+}
+
+// GateConfig represents the noise gate settings of an input
+type GateConfig struct {
+	Enabled      bool    `json:"enabled"`
+	ThresholdDB  float32 `json:"threshold_db"`  // -90.0 to 0.0 dBFS
+	HysteresisDB float32 `json:"hysteresis_db"` // 0.0 to 20.0
+	HoldMs       float32 `json:"hold_ms"`       // 0 to 2000
+	AttackMs     float32 `json:"attack_ms"`     // 0.1 to 500
+	ReleaseMs    float32 `json:"release_ms"`    // 1 to 5000
+	RangeDB      float32 `json:"range_db"`      // -90.0 to 0.0
+}
+
+// DefaultGateConfig returns noise gate settings for a microphone, switched off
+func DefaultGateConfig() GateConfig {
+	return GateConfig{
+		ThresholdDB:  -45,
+		HysteresisDB: 6,
+		HoldMs:       100,
+		AttackMs:     1,
+		ReleaseMs:    150,
+		RangeDB:      -80,
+	}
+}
+
+// Hold returns the hold time as a duration
+func (c GateConfig) Hold() time.Duration {
+	return time.Duration(c.HoldMs * float32(time.Millisecond))
+}
+
+// Attack returns the attack time as a duration
+func (c GateConfig) Attack() time.Duration {
+	return time.Duration(c.AttackMs * float32(time.Millisecond))
+}
+
+// Release returns the release time as a duration
+func (c GateConfig) Release() time.Duration {
+	return time.Duration(c.ReleaseMs * float32(time.Millisecond))
+}
+
+// CompressorConfig represents the compressor settings of an input
+type CompressorConfig struct {
+	Enabled      bool    `json:"enabled"`
+	ThresholdDB  float32 `json:"threshold_db"`   // -60.0 to 0.0 dBFS
+	Ratio        float32 `json:"ratio"`          // 1.0 to 20.0
+	KneeDB       float32 `json:"knee_db"`        // 0.0 to 24.0
+	AttackMs     float32 `json:"attack_ms"`      // 0.1 to 500
+	ReleaseMs    float32 `json:"release_ms"`     // 1 to 5000
+	MakeupGainDB float32 `json:"makeup_gain_db"` // 0.0 to 24.0
+}
+
+// DefaultCompressorConfig returns moderate compressor settings for speech, switched off
+func DefaultCompressorConfig() CompressorConfig {
+	return CompressorConfig{
+		ThresholdDB: -18,
+		Ratio:       4,
+		KneeDB:      6,
+		AttackMs:    10,
+		ReleaseMs:   150,
+	}
+}
+
+// DeEsserConfig represents the de-esser settings of an input
+type DeEsserConfig struct {
+	Enabled     bool    `json:"enabled"`
+	LowHz       float64 `json:"low_hz"`       // 1000 to 16000, below HighHz
+	HighHz      float64 `json:"high_hz"`      // 2000 to 20000
+	ThresholdDB float32 `json:"threshold_db"` // -60.0 to 0.0 dBFS, level of the sibilance band
+	ReductionDB float32 `json:"reduction_db"` // 0.0 to 24.0
+	Listen      bool    `json:"listen"`       // Output the sibilance band alone, for tuning
+}
+
+// DefaultDeEsserConfig returns de-esser settings for most voices, switched off
+func DefaultDeEsserConfig() DeEsserConfig {
+	return DeEsserConfig{
+		LowHz:       4000,
+		HighHz:      10000,
+		ThresholdDB: -30,
+		ReductionDB: 8,
+	}
+}
+
+// Attack returns the attack time as a duration
+func (c CompressorConfig) Attack() time.Duration {
+	return time.Duration(c.AttackMs * float32(time.Millisecond))
+}
+
+// Release returns the release time as a duration
+func (c CompressorConfig) Release() time.Duration {
+	return time.Duration(c.ReleaseMs * float32(time.Millisecond))
+}
+
+// Delay returns the input's delay as a duration
+func (c InputConfig) Delay() time.Duration {
+	return time.Duration(c.DelayMs * float64(time.Millisecond))
+}
+
+// DeviceChannels returns the input's device channels 0-based, or nil for the default
+func (c InputConfig) DeviceChannels() []int {
+	return zeroBased(c.Channels)
+}
+
+// LimiterConfig represents the master bus limiter settings
+type LimiterConfig struct {
+	Enabled     bool    `json:"enabled"`
+	CeilingDB   float32 `json:"ceiling_db"`   // Highest output peak, -20.0 to 0.0 dBFS
+	ReleaseMs   int     `json:"release_ms"`   // 1 to 2000
+	LookAheadMs float32 `json:"lookahead_ms"` // 0.0 to 20.0, adds as much latency
+}
+
+// Release returns the release time as a duration
+func (c LimiterConfig) Release() time.Duration {
+	return time.Duration(c.ReleaseMs) * time.Millisecond
+}
+
+// LookAhead returns the look-ahead time as a duration
+func (c LimiterConfig) LookAhead() time.Duration {
+	return time.Duration(c.LookAheadMs * float32(time.Millisecond))
+}
+
+// DuckingConfig represents the sidechain ducking settings
+type DuckingConfig struct {
+	Enabled      bool    `json:"enabled"`
+	TriggerInput int     `json:"trigger_input"` // Input (1-based) whose level ducks the others
+	ThresholdDB  float32 `json:"threshold_db"`  // -90.0 to 0.0 dBFS
+	DepthDB      float32 `json:"depth_db"`      // 0.0 to 60.0
+	AttackMs     float32 `json:"attack_ms"`     // 1 to 1000
+	HoldMs       float32 `json:"hold_ms"`       // 0 to 5000
+	ReleaseMs    float32 `json:"release_ms"`    // 1 to 5000
+}
+
+// Attack returns the attack time as a duration
+func (c DuckingConfig) Attack() time.Duration {
+	return time.Duration(c.AttackMs * float32(time.Millisecond))
+}
+
+// Hold returns the hold time as a duration
+func (c DuckingConfig) Hold() time.Duration {
+	return time.Duration(c.HoldMs * float32(time.Millisecond))
+}
+
+// Release returns the release time as a duration
+func (c DuckingConfig) Release() time.Duration {
+	return time.Duration(c.ReleaseMs * float32(time.Millisecond))
+}
+
 // Config represents the application configuration
 type Config struct {
 	// Audio settings
@@ -14,20 +355,47 @@ type Config struct {
 	BufferSize   int     `json:"buffer_size"`
 	Channels     int     `json:"channels"`
 
-	// Device indices
-	Input1DeviceIndex int `json:"input1_device_index"` // Microphone/Line input
-	Input2DeviceIndex int `json:"input2_device_index"` // System audio (loopback device)
+	// Inputs (microphones, line inputs, loopback devices)
+	Inputs []InputConfig `json:"inputs"`
+
+	// Output device index
 	OutputDeviceIndex int `json:"output_device_index"` // Virtual output (BlackHole, etc.)
 
+	// Output device channels (1-based) the mixer channels are mapped onto;
+	// empty uses the first ones
+	OutputChannels []int `json:"output_channels,omitempty"`
+
 	// Virtual device settings
 	UseVirtualOutput bool   `json:"use_virtual_output"` // Use virtual device for output
 	LoopbackDeviceName string `json:"loopback_device_name"` // Name of loopback device
 
-	// Volume settings (0.0 to 2.0)
-	Input1Gain float32 `json:"input1_gain"`
-	Input2Gain float32 `json:"input2_gain"`
+	// Master volume (0.0 to 2.0)
 	MasterGain float32 `json:"master_gain"`
 
+	// Pan law applied to every input (one of PanLaws)
+	PanLaw string `json:"pan_law"`
+
+	// Time gain and pan changes are ramped over, 0 spreads them over one buffer
+	GainSmoothingMs int `json:"gain_smoothing_ms"`
+
+	// Resample inputs to follow the output device clock
+	DriftCompensation bool `json:"drift_compensation"`
+
+	// Look-ahead peak limiter on the master bus
+	Limiter LimiterConfig `json:"limiter"`
+
+	// Sidechain ducking of the other inputs under one input
+	Ducking DuckingConfig `json:"ducking"`
+
+	// Equalizer on the master bus
+	MasterEQ EQConfig `json:"master_eq"`
+
+	// Master bus processing order before the limiter; "eq" names MasterEQ
+	MasterInserts []InsertConfig `json:"master_inserts,omitempty"`
+
+	// Effect buses the inputs send to
+	AuxBuses []AuxBusConfig `json:"aux_buses"`
+
 	// UI preferences
 	WindowWidth  int  `json:"window_width"`
 	WindowHeight int  `json:"window_height"`
@@ -40,14 +408,35 @@ func DefaultConfig() *Config {
 		SampleRate:         48000,
 		BufferSize:         512,
 		Channels:           2,
-		Input1DeviceIndex:  -1, // -1 means use default device
-		Input2DeviceIndex:  -1, // Will auto-detect loopback device
+		Inputs: []InputConfig{
+			{Name: "Microphone", DeviceIndex: DeviceIndexDefault, Gain: 1.0},                   // Default input device
+			{Name: "System Audio", DeviceIndex: DeviceIndexDefault, Loopback: true, Gain: 1.0}, // Will auto-detect loopback device
+		},
 		OutputDeviceIndex:  -1, // Will auto-detect virtual output
 		UseVirtualOutput:   true, // Use virtual device by default
 		LoopbackDeviceName: "BlackHole", // Default to BlackHole on macOS
-		Input1Gain:         1.0,
-		Input2Gain:         1.0,
 		MasterGain:         1.0,
+		PanLaw:             PanLawLinear,
+		GainSmoothingMs:    20,
+		DriftCompensation:  true,
+		Limiter: LimiterConfig{
+			Enabled:     true,
+			CeilingDB:   -1.0,
+			ReleaseMs:   100,
+			LookAheadMs: 5.0,
+		},
+		Ducking: DuckingConfig{
+			TriggerInput: 1,
+			ThresholdDB:  -40,
+			DepthDB:      12,
+			AttackMs:     20,
+			HoldMs:       300,
+			ReleaseMs:    500,
+		},
+		AuxBuses: []AuxBusConfig{
+			{Name: "Reverb", ReturnGain: 1.0, Inserts: []InsertConfig{{Type: "reverb"}}},
+			{Name: "Echo", ReturnGain: 1.0, Inserts: []InsertConfig{{Type: "echo"}}},
+		},
 		WindowWidth:        800,
 		WindowHeight:       600,
 		StartMinimized:     false,
@@ -97,6 +486,12 @@ func (cm *ConfigManager) Load() (*Config, error) {
 	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if err := migrateLegacyInputs(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if law, ok := legacyPanLaws[config.PanLaw]; ok {
+		config.PanLaw = law
+	}
 
 	// Validate configuration
 	if err := cm.validateConfig(config); err != nil {
@@ -137,22 +532,367 @@ func (cm *ConfigManager) validateConfig(config *Config) error {
 		return fmt.Errorf("buffer size must be positive")
 	}
 
-	if config.Channels <= 0 || config.Channels > 2 {
-		return fmt.Errorf("channels must be 1 or 2")
+	if config.Channels <= 0 || config.Channels > MaxChannels {
+		return fmt.Errorf("channels must be between 1 and %d", MaxChannels)
 	}
 
-	if config.Input1Gain < 0 || config.Input1Gain > 2.0 {
-		return fmt.Errorf("input1 gain must be between 0.0 and 2.0")
+	if err := validateChannels(config.OutputChannels); err != nil {
+		return fmt.Errorf("output channels: %w", err)
 	}
 
-	if config.Input2Gain < 0 || config.Input2Gain > 2.0 {
-		return fmt.Errorf("input2 gain must be between 0.0 and 2.0")
+	if len(config.Inputs) > MaxInputs {
+		return fmt.Errorf("at most %d inputs are supported", MaxInputs)
+	}
+
+	for i, input := range config.Inputs {
+		if input.Gain < 0 || input.Gain > 2.0 {
+			return fmt.Errorf("input%d gain must be between 0.0 and 2.0", i+1)
+		}
+		if input.Pan < -1.0 || input.Pan > 1.0 {
+			return fmt.Errorf("input%d pan must be between -1.0 and 1.0", i+1)
+		}
+		if err := validateChannels(input.Channels); err != nil {
+			return fmt.Errorf("input%d channels: %w", i+1, err)
+		}
+		if input.DelayMs < 0 || input.DelayMs > MaxDelayMs {
+			return fmt.Errorf("input%d delay must be between 0 and %d ms", i+1, MaxDelayMs)
+		}
+		if len(input.Sends) > MaxAuxBuses {
+			return fmt.Errorf("input%d has more than %d sends", i+1, MaxAuxBuses)
+		}
+		for b, level := range input.Sends {
+			if level < 0 || level > 2.0 {
+				return fmt.Errorf("input%d send %d must be between 0.0 and 2.0", i+1, b+1)
+			}
+		}
+		if input.Filter != nil {
+			if err := ValidateFilter(*input.Filter); err != nil {
+				return fmt.Errorf("input%d filter: %w", i+1, err)
+			}
+		}
+		if input.Denoiser != nil {
+			if err := ValidateDenoiser(*input.Denoiser); err != nil {
+				return fmt.Errorf("input%d denoiser: %w", i+1, err)
+			}
+		}
+		if input.Gate != nil {
+			if err := ValidateGate(*input.Gate); err != nil {
+				return fmt.Errorf("input%d gate: %w", i+1, err)
+			}
+		}
+		if input.EQ != nil {
+			if err := ValidateEQ(*input.EQ, config.SampleRate); err != nil {
+				return fmt.Errorf("input%d EQ: %w", i+1, err)
+			}
+		}
+		if input.AGC != nil {
+			if err := ValidateAGC(*input.AGC); err != nil {
+				return fmt.Errorf("input%d AGC: %w", i+1, err)
+			}
+		}
+		if input.Compressor != nil {
+			if err := ValidateCompressor(*input.Compressor); err != nil {
+				return fmt.Errorf("input%d compressor: %w", i+1, err)
+			}
+		}
+		if input.DeEsser != nil {
+			if err := ValidateDeEsser(*input.DeEsser); err != nil {
+				return fmt.Errorf("input%d de-esser: %w", i+1, err)
+			}
+		}
+		if err := ValidateInserts(input.Inserts); err != nil {
+			return fmt.Errorf("input%d inserts: %w", i+1, err)
+		}
 	}
 
 	if config.MasterGain < 0 || config.MasterGain > 2.0 {
 		return fmt.Errorf("master gain must be between 0.0 and 2.0")
 	}
 
+	if config.GainSmoothingMs < 0 || config.GainSmoothingMs > 1000 {
+		return fmt.Errorf("gain smoothing must be between 0 and 1000 ms")
+	}
+
+	if !validPanLaw(config.PanLaw) {
+		return fmt.Errorf("pan law must be one of %s", strings.Join(PanLaws, ", "))
+	}
+
+	if config.Limiter.CeilingDB < -20.0 || config.Limiter.CeilingDB > 0 {
+		return fmt.Errorf("limiter ceiling must be between -20.0 and 0.0 dB")
+	}
+	if config.Limiter.ReleaseMs < 1 || config.Limiter.ReleaseMs > 2000 {
+		return fmt.Errorf("limiter release must be between 1 and 2000 ms")
+	}
+	if config.Limiter.LookAheadMs < 0 || config.Limiter.LookAheadMs > 20.0 {
+		return fmt.Errorf("limiter look-ahead must be between 0.0 and 20.0 ms")
+	}
+
+	if err := ValidateDucking(config.Ducking); err != nil {
+		return fmt.Errorf("ducking: %w", err)
+	}
+
+	if err := ValidateEQ(config.MasterEQ, config.SampleRate); err != nil {
+		return fmt.Errorf("master EQ: %w", err)
+	}
+
+	if err := ValidateInserts(config.MasterInserts); err != nil {
+		return fmt.Errorf("master inserts: %w", err)
+	}
+
+	if len(config.AuxBuses) > MaxAuxBuses {
+		return fmt.Errorf("at most %d aux buses are supported", MaxAuxBuses)
+	}
+	for b, bus := range config.AuxBuses {
+		if bus.ReturnGain < 0 || bus.ReturnGain > 2.0 {
+			return fmt.Errorf("aux bus %d return gain must be between 0.0 and 2.0", b+1)
+		}
+		if err := ValidateInserts(bus.Inserts); err != nil {
+			return fmt.Errorf("aux bus %d inserts: %w", b+1, err)
+		}
+	}
+
+	return nil
+}
+
+// validPanLaw reports whether name is a supported pan law; empty selects the default
+func validPanLaw(name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, law := range PanLaws {
+		if law == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFilter checks the high-pass filter cutoff
+func ValidateFilter(c FilterConfig) error {
+	if c.HighPassHz < 20 || c.HighPassHz > 500 {
+		return fmt.Errorf("high-pass cutoff must be between 20 and 500 Hz")
+	}
+	return nil
+}
+
+// ValidateDenoiser checks the noise suppression strength
+func ValidateDenoiser(c DenoiserConfig) error {
+	if c.Strength < 0 || c.Strength > 1 {
+		return fmt.Errorf("strength must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// ValidateAGC checks the mode and ranges of AGC settings
+func ValidateAGC(c AGCConfig) error {
+	switch {
+	case c.Mode != AGCModeRMS && c.Mode != AGCModeLUFS:
+		return fmt.Errorf("mode must be one of %s", strings.Join(AGCModes, ", "))
+	case c.TargetDB < -60 || c.TargetDB > 0:
+		return fmt.Errorf("target must be between -60.0 and 0.0 dB")
+	case c.MaxBoostDB < 0 || c.MaxBoostDB > 40:
+		return fmt.Errorf("maximum boost must be between 0.0 and 40.0 dB")
+	case c.MaxCutDB < 0 || c.MaxCutDB > 40:
+		return fmt.Errorf("maximum cut must be between 0.0 and 40.0 dB")
+	case c.RateDBPerSec < 0.1 || c.RateDBPerSec > 60:
+		return fmt.Errorf("rate must be between 0.1 and 60.0 dB per second")
+	case c.FreezeDB < -90 || c.FreezeDB > 0:
+		return fmt.Errorf("freeze level must be between -90.0 and 0.0 dB")
+	}
+	return nil
+}
+
+// ValidateInserts checks that every insert names a processor type and has
+// finite parameter values; unknown types are reported when the mixer starts
+func ValidateInserts(inserts []InsertConfig) error {
+	if len(inserts) > MaxInserts {
+		return fmt.Errorf("at most %d inserts are supported", MaxInserts)
+	}
+	for i, insert := range inserts {
+		if insert.Type == "" {
+			return fmt.Errorf("insert %d has no type", i+1)
+		}
+		for name, value := range insert.Params {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return fmt.Errorf("insert %d (%s) parameter %s must be a finite number", i+1, insert.Type, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateEQ checks the bands of an equalizer running at sampleRate
+func ValidateEQ(c EQConfig, sampleRate float64) error {
+	if len(c.Bands) > MaxEQBands {
+		return fmt.Errorf("at most %d bands are supported", MaxEQBands)
+	}
+	for i, band := range c.Bands {
+		if !validFilterType(band.Type) {
+			return fmt.Errorf("band %d type must be one of %s", i+1, strings.Join(FilterTypes, ", "))
+		}
+		if band.FrequencyHz < 10 || band.FrequencyHz > 20000 || band.FrequencyHz >= sampleRate/2 {
+			return fmt.Errorf("band %d frequency must be between 10 and 20000 Hz and below half the sample rate", i+1)
+		}
+		if band.GainDB < -24 || band.GainDB > 24 {
+			return fmt.Errorf("band %d gain must be between -24.0 and 24.0 dB", i+1)
+		}
+		if band.Q < 0.1 || band.Q > 18 {
+			return fmt.Errorf("band %d Q must be between 0.1 and 18.0", i+1)
+		}
+	}
+	return nil
+}
+
+// validFilterType reports whether name is a supported equalizer filter type
+func validFilterType(name string) bool {
+	for _, filterType := range FilterTypes {
+		if filterType == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDucking checks the ranges of ducking settings
+func ValidateDucking(c DuckingConfig) error {
+	switch {
+	case c.TriggerInput < 1 || c.TriggerInput > MaxInputs:
+		return fmt.Errorf("trigger input must be between 1 and %d", MaxInputs)
+	case c.ThresholdDB < -90 || c.ThresholdDB > 0:
+		return fmt.Errorf("threshold must be between -90.0 and 0.0 dB")
+	case c.DepthDB < 0 || c.DepthDB > 60:
+		return fmt.Errorf("depth must be between 0.0 and 60.0 dB")
+	case c.AttackMs < 1 || c.AttackMs > 1000:
+		return fmt.Errorf("attack must be between 1 and 1000 ms")
+	case c.HoldMs < 0 || c.HoldMs > 5000:
+		return fmt.Errorf("hold must be between 0 and 5000 ms")
+	case c.ReleaseMs < 1 || c.ReleaseMs > 5000:
+		return fmt.Errorf("release must be between 1 and 5000 ms")
+	}
+	return nil
+}
+
+// ValidateGate checks the ranges of noise gate settings
+func ValidateGate(c GateConfig) error {
+	switch {
+	case c.ThresholdDB < -90 || c.ThresholdDB > 0:
+		return fmt.Errorf("threshold must be between -90.0 and 0.0 dB")
+	case c.HysteresisDB < 0 || c.HysteresisDB > 20:
+		return fmt.Errorf("hysteresis must be between 0.0 and 20.0 dB")
+	case c.HoldMs < 0 || c.HoldMs > 2000:
+		return fmt.Errorf("hold must be between 0 and 2000 ms")
+	case c.AttackMs < 0.1 || c.AttackMs > 500:
+		return fmt.Errorf("attack must be between 0.1 and 500 ms")
+	case c.ReleaseMs < 1 || c.ReleaseMs > 5000:
+		return fmt.Errorf("release must be between 1 and 5000 ms")
+	case c.RangeDB < -90 || c.RangeDB > 0:
+		return fmt.Errorf("range must be between -90.0 and 0.0 dB")
+	}
+	return nil
+}
+
+// ValidateCompressor checks the ranges of compressor settings
+func ValidateCompressor(c CompressorConfig) error {
+	switch {
+	case c.ThresholdDB < -60 || c.ThresholdDB > 0:
+		return fmt.Errorf("threshold must be between -60.0 and 0.0 dB")
+	case c.Ratio < 1 || c.Ratio > 20:
+		return fmt.Errorf("ratio must be between 1.0 and 20.0")
+	case c.KneeDB < 0 || c.KneeDB > 24:
+		return fmt.Errorf("knee must be between 0.0 and 24.0 dB")
+	case c.AttackMs < 0.1 || c.AttackMs > 500:
+		return fmt.Errorf("attack must be between 0.1 and 500 ms")
+	case c.ReleaseMs < 1 || c.ReleaseMs > 5000:
+		return fmt.Errorf("release must be between 1 and 5000 ms")
+	case c.MakeupGainDB < 0 || c.MakeupGainDB > 24:
+		return fmt.Errorf("makeup gain must be between 0.0 and 24.0 dB")
+	}
+	return nil
+}
+
+// ValidateDeEsser checks the band and ranges of de-esser settings
+func ValidateDeEsser(c DeEsserConfig) error {
+	switch {
+	case c.LowHz < 1000 || c.LowHz > 16000:
+		return fmt.Errorf("low edge must be between 1000 and 16000 Hz")
+	case c.HighHz < 2000 || c.HighHz > 20000:
+		return fmt.Errorf("high edge must be between 2000 and 20000 Hz")
+	case c.LowHz >= c.HighHz:
+		return fmt.Errorf("low edge must be below the high edge")
+	case c.ThresholdDB < -60 || c.ThresholdDB > 0:
+		return fmt.Errorf("threshold must be between -60.0 and 0.0 dB")
+	case c.ReductionDB < 0 || c.ReductionDB > 24:
+		return fmt.Errorf("reduction must be between 0.0 and 24.0 dB")
+	}
+	return nil
+}
+
+// validateChannels checks a list of 1-based device channels
+func validateChannels(channels []int) error {
+	for _, ch := range channels {
+		if ch < 1 || ch > MaxChannels {
+			return fmt.Errorf("channel %d must be between 1 and %d", ch, MaxChannels)
+		}
+	}
+	return nil
+}
+
+// OutputDeviceChannels returns the output device channels 0-based, or nil for the default
+func (c *Config) OutputDeviceChannels() []int {
+	return zeroBased(c.OutputChannels)
+}
+
+// zeroBased converts 1-based channel numbers to 0-based indices
+func zeroBased(channels []int) []int {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	indices := make([]int, len(channels))
+	for i, ch := range channels {
+		indices[i] = ch - 1
+	}
+	return indices
+}
+
+// legacyInputs holds the fixed two-input fields written by older versions
+type legacyInputs struct {
+	Inputs            json.RawMessage `json:"inputs"`
+	Input1DeviceIndex *int            `json:"input1_device_index"`
+	Input2DeviceIndex *int            `json:"input2_device_index"`
+	Input1Gain        *float32        `json:"input1_gain"`
+	Input2Gain        *float32        `json:"input2_gain"`
+}
+
+// migrateLegacyInputs converts input1/input2 fields of an old config file into Inputs
+func migrateLegacyInputs(data []byte, config *Config) error {
+	var legacy legacyInputs
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	// New-style files always carry the inputs list
+	if legacy.Inputs != nil {
+		return nil
+	}
+
+	defaults := DefaultConfig().Inputs
+	input1, input2 := defaults[0], defaults[1]
+
+	if legacy.Input1DeviceIndex != nil {
+		input1.DeviceIndex = *legacy.Input1DeviceIndex
+	}
+	if legacy.Input1Gain != nil {
+		input1.Gain = *legacy.Input1Gain
+	}
+	if legacy.Input2DeviceIndex != nil {
+		input2.DeviceIndex = *legacy.Input2DeviceIndex
+	}
+	if legacy.Input2Gain != nil {
+		input2.Gain = *legacy.Input2Gain
+	}
+
+	config.Inputs = []InputConfig{input1, input2}
 	return nil
 }
 
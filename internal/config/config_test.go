@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMigratesPanLaw(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"pan_law": "-3dB"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &ConfigManager{configPath: path}
+	cfg, err := cm.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PanLaw != PanLawConstantPower {
+		t.Errorf("pan law %q, want %q", cfg.PanLaw, PanLawConstantPower)
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import "github.com/entropy/audio-mixer/internal/audio"
+
+// Mixer converts compressor settings for the mixer; nil turns the
+// compressor off
+func (c *CompressorConfig) Mixer() audio.CompressorConfig {
+	if c == nil {
+		return audio.CompressorConfig{}
+	}
+	return audio.CompressorConfig{
+		Enabled:    c.Enabled,
+		Threshold:  c.ThresholdDB,
+		Ratio:      c.Ratio,
+		Knee:       c.KneeDB,
+		Attack:     c.Attack(),
+		Release:    c.Release(),
+		MakeupGain: c.MakeupGainDB,
+	}
+}
+
+// Mixer converts noise gate settings for the mixer; nil turns the gate off
+func (c *GateConfig) Mixer() audio.GateConfig {
+	if c == nil {
+		return audio.GateConfig{}
+	}
+	return audio.GateConfig{
+		Enabled:    c.Enabled,
+		Threshold:  c.ThresholdDB,
+		Hysteresis: c.HysteresisDB,
+		Hold:       c.Hold(),
+		Attack:     c.Attack(),
+		Release:    c.Release(),
+		Range:      c.RangeDB,
+	}
+}
+
+// Mixer converts equalizer settings for the mixer; nil turns the EQ off
+func (c *EQConfig) Mixer() audio.EQConfig {
+	if c == nil {
+		return audio.EQConfig{}
+	}
+	eq := audio.EQConfig{Enabled: c.Enabled}
+	for _, band := range c.Bands {
+		filterType, _ := audio.ParseFilterType(band.Type)
+		eq.Bands = append(eq.Bands, audio.EQBand{
+			Type:      filterType,
+			Frequency: band.FrequencyHz,
+			Gain:      band.GainDB,
+			Q:         band.Q,
+		})
+	}
+	return eq
+}
+
+// Mixer converts DC blocker and high-pass filter settings for the mixer;
+// nil runs the defaults
+func (c *FilterConfig) Mixer() audio.InputFilterConfig {
+	if c == nil {
+		defaults := DefaultFilterConfig()
+		c = &defaults
+	}
+	return audio.InputFilterConfig{
+		DCBlocker:      c.DCBlocker,
+		HighPass:       c.HighPass,
+		HighPassCutoff: c.HighPassHz,
+	}
+}
+
+// Mixer converts noise suppression settings for the mixer; nil turns
+// noise suppression off
+func (c *DenoiserConfig) Mixer() audio.DenoiserConfig {
+	if c == nil {
+		return audio.DenoiserConfig{}
+	}
+	return audio.DenoiserConfig{
+		Enabled:  c.Enabled,
+		Bypass:   c.Bypass,
+		Strength: c.Strength,
+		Adaptive: c.Adaptive,
+	}
+}
+
+// Mixer converts AGC settings for the mixer; nil turns the AGC off
+func (c *AGCConfig) Mixer() audio.AGCConfig {
+	if c == nil {
+		return audio.AGCConfig{}
+	}
+	mode, _ := audio.ParseAGCMode(c.Mode)
+	return audio.AGCConfig{
+		Enabled:  c.Enabled,
+		Mode:     mode,
+		Target:   c.TargetDB,
+		MaxBoost: c.MaxBoostDB,
+		MaxCut:   c.MaxCutDB,
+		Rate:     c.RateDBPerSec,
+		Freeze:   c.FreezeDB,
+	}
+}
+
+// MixerInserts converts an insert chain order for the mixer
+func MixerInserts(c []InsertConfig) []audio.InsertConfig {
+	var inserts []audio.InsertConfig
+	for _, insert := range c {
+		inserts = append(inserts, audio.InsertConfig{Type: insert.Type, Bypass: insert.Bypass, Params: insert.Params})
+	}
+	return inserts
+}
+
+// InsertsFromMixer converts the order of a running insert chain for saving
+func InsertsFromMixer(c []audio.InsertConfig) []InsertConfig {
+	var inserts []InsertConfig
+	for _, insert := range c {
+		inserts = append(inserts, InsertConfig{Type: insert.Type, Bypass: insert.Bypass, Params: insert.Params})
+	}
+	return inserts
+}
+
+// Mixer converts de-esser settings for the mixer; nil turns the de-esser
+// off
+func (c *DeEsserConfig) Mixer() audio.DeEsserConfig {
+	if c == nil {
+		return audio.DeEsserConfig{}
+	}
+	return audio.DeEsserConfig{
+		Enabled:   c.Enabled,
+		Low:       c.LowHz,
+		High:      c.HighHz,
+		Threshold: c.ThresholdDB,
+		Reduction: c.ReductionDB,
+		Listen:    c.Listen,
+	}
+}
+
+// MixerAuxBuses converts aux bus settings for the mixer
+func MixerAuxBuses(c []AuxBusConfig) []audio.AuxBusConfig {
+	var buses []audio.AuxBusConfig
+	for _, bus := range c {
+		buses = append(buses, audio.AuxBusConfig{Name: bus.Name, Return: bus.ReturnGain, Inserts: MixerInserts(bus.Inserts)})
+	}
+	return buses
+}
+
+// Mixer converts ducking settings for the mixer, with trigger the mixer's
+// index of the trigger input or -1 for none
+func (c DuckingConfig) Mixer(trigger int) audio.DuckingConfig {
+	return audio.DuckingConfig{
+		Enabled:   c.Enabled,
+		Trigger:   trigger,
+		Threshold: c.ThresholdDB,
+		Depth:     c.DepthDB,
+		Attack:    c.Attack(),
+		Hold:      c.Hold(),
+		Release:   c.Release(),
+	}
+}
@@ -3,6 +3,7 @@ package gui
 import (
 	"fmt"
 	"image/color"
+	"math"
 	"os"
 	"strings"
 	"time"
@@ -18,6 +19,9 @@ import (
 	"github.com/entropy/audio-mixer/internal/config"
 )
 
+// gainReductionRange is the most limiter gain reduction the meter shows, in dB
+const gainReductionRange = 20
+
 // App represents the GUI application
 type App struct {
 	fyneApp        fyne.App
@@ -29,23 +33,28 @@ type App struct {
 	cfg            *config.Config
 
 	// UI elements
-	input1Select        *widget.Select
-	input2Select        *widget.Select
+	inputRows           []*inputRow
+	inputOptions        []string        // Device selector entries shared by all inputs
+	inputDeviceBox      *fyne.Container // Device selector per input
+	inputGainBox        *fyne.Container // Gain slider per input
+	inputMeterBox       *fyne.Container // Level meter per input
+	addInputButton      *widget.Button
 	appSelect           *widget.Select // Application audio selector
 	outputNameEntry     *widget.Entry  // Custom output device name entry
-	input1Slider        *widget.Slider
-	input2Slider      *widget.Slider
 	masterSlider      *widget.Slider
-	input1Label       *widget.Label
-	input2Label       *widget.Label
 	masterLabel       *widget.Label
 	statusLabel       *widget.Label
 	startButton       *widget.Button
 	stopButton        *widget.Button
-	input1Meter       *widget.ProgressBar
-	input2Meter       *widget.ProgressBar
 	outputMeter       *widget.ProgressBar
+	gainReduction     *widget.ProgressBar // Limiter gain reduction in dB
 	latencyLabel      *widget.Label
+	limiterCheck      *widget.Check
+	duckButton        *widget.Button // Opens the ducking settings, highlighted while enabled
+	masterEQButton    *widget.Button // Opens the master equalizer, highlighted while enabled
+	masterChainButton *widget.Button // Opens the master insert chain
+	auxButton         *widget.Button // Opens the aux bus returns and effects
+	duckLabel         *widget.Label  // Ducking gain reduction
 	fontSelect        *widget.Select
 	fontStatus        *widget.Label
 
@@ -112,6 +121,11 @@ func (a *App) Run() {
 
 // buildUI creates the main UI layout
 func (a *App) buildUI() fyne.CanvasObject {
+	// Per-input widgets live in these boxes and are filled by rebuildInputs
+	a.inputDeviceBox = container.NewVBox()
+	a.inputGainBox = container.NewVBox()
+	a.inputMeterBox = container.NewVBox()
+
 	// Device selection section
 	deviceSection := a.buildDeviceSection()
 
@@ -130,6 +144,8 @@ func (a *App) buildUI() fyne.CanvasObject {
 	// Status bar
 	a.statusLabel = widget.NewLabel("Ready")
 
+	a.rebuildInputs()
+
 	// Main layout
 	content := container.NewVBox(
 		widget.NewLabel("Audio Mixer"),
@@ -152,99 +168,11 @@ func (a *App) buildUI() fyne.CanvasObject {
 
 // buildDeviceSection creates device selection UI
 func (a *App) buildDeviceSection() fyne.CanvasObject {
-	// Get devices
-	inputDevices, _ := a.deviceManager.GetInputDevices()
-
-	// Get loopback devices for Input2 and Output
-	loopbackDevices, _ := audio.ListLoopbackDevices(a.deviceManager)
-
-	// Build Input 1 device names (microphone/line input)
-	inputNames := make([]string, len(inputDevices))
-	for i, dev := range inputDevices {
-		inputNames[i] = fmt.Sprintf("[%d] %s", dev.Index, dev.Name)
-	}
-
-	// Build Input 2 options (loopback devices for system audio)
-	input2Names := []string{"<Auto Detect Loopback>"}
-	var input2Devices []*audio.DeviceInfo
-	for _, lb := range loopbackDevices {
-		// Convert loopback device to DeviceInfo for consistency
-		devInfo := &audio.DeviceInfo{
-			Name: lb.Name,
-		}
-		// Find the actual device index
-		allDevs, _ := a.deviceManager.ListDevices()
-		for _, d := range allDevs {
-			if d.Name == lb.Name {
-				devInfo.Index = d.Index
-				break
-			}
-		}
-		input2Devices = append(input2Devices, devInfo)
-		input2Names = append(input2Names, fmt.Sprintf("[Virtual] %s", lb.Name))
-	}
-
-	// Build Output options (loopback/virtual devices only)
-	outputNames := []string{"<Auto Detect Virtual Output>"}
-	var outputDevices []*audio.DeviceInfo
-	for _, lb := range loopbackDevices {
-		devInfo := &audio.DeviceInfo{
-			Name: lb.Name,
-		}
-		allDevs, _ := a.deviceManager.ListDevices()
-		for _, d := range allDevs {
-			if d.Name == lb.Name {
-				devInfo.Index = d.Index
-				break
-			}
-		}
-		outputDevices = append(outputDevices, devInfo)
-		outputNames = append(outputNames, fmt.Sprintf("[Virtual] %s", lb.Name))
-	}
-
-	// Helper function to find device name by index
-	findDeviceName := func(deviceIndex int, devices []*audio.DeviceInfo, names []string) string {
-		for i, dev := range devices {
-			if dev.Index == deviceIndex {
-				if i < len(names) {
-					return names[i]
-				}
-			}
-		}
-		return ""
-	}
-
-	// Input 1 select (Microphone/Line Input)
-	a.input1Select = widget.NewSelect(inputNames, nil)
-	if a.cfg.Input1DeviceIndex >= 0 {
-		selectedName := findDeviceName(a.cfg.Input1DeviceIndex, inputDevices, inputNames)
-		if selectedName != "" {
-			a.input1Select.SetSelected(selectedName)
-		}
-	}
-	a.input1Select.OnChanged = func(value string) {
-		if value != "" {
-			a.updateConfig()
-		}
-	}
-
-	// Input 2 select (System Audio via Loopback)
-	a.input2Select = widget.NewSelect(input2Names, nil)
-	if a.cfg.Input2DeviceIndex >= 0 {
-		selectedName := findDeviceName(a.cfg.Input2DeviceIndex, input2Devices, input2Names[1:])
-		if selectedName != "" {
-			a.input2Select.SetSelected(selectedName)
-		} else {
-			a.input2Select.SetSelected("<Auto Detect Loopback>")
-		}
-	} else {
-		a.input2Select.SetSelected("<Auto Detect Loopback>")
-	}
-	a.input2Select.OnChanged = func(value string) {
-		if value != "" {
-			a.updateConfig()
-		}
-	}
+	// Input device selectors
+	a.inputOptions = a.buildInputOptions()
+	a.addInputButton = widget.NewButton("➕ 添加输入 (Add Input)", func() {
+		a.addInput()
+	})
 
 	// Output select (Virtual Output Device) - removed, using custom name instead
 
@@ -323,18 +251,16 @@ func (a *App) buildDeviceSection() fyne.CanvasObject {
 
 	// Info label explaining the setup
 	infoText := "提示:\n" +
-		"• Input 1: 麦克风/线路输入\n" +
-		"• Input 2: 系统音频 (需要虚拟设备,如 BlackHole/VB-Cable)\n" +
+		"• Input: 麦克风/线路输入, 或系统音频 (需要虚拟设备,如 BlackHole/VB-Cable)\n" +
+		fmt.Sprintf("• 最多 %d 路输入 (Add Input)\n", config.MaxInputs) +
 		"• Output: 输入虚拟设备名称 (混音后输出到该设备)\n" +
 		"• 常见设备名: BlackHole 2ch, CABLE-B Input, VB-Cable"
 	infoLabel := widget.NewLabel(infoText)
 
 	sections := []fyne.CanvasObject{
 		widget.NewLabel("设备配置 (Devices)"),
-		container.New(layout.NewFormLayout(),
-			widget.NewLabel("Input 1 (麦克风):"), a.input1Select,
-			widget.NewLabel("Input 2 (系统音频):"), a.input2Select,
-		),
+		a.inputDeviceBox,
+		a.addInputButton,
 		widget.NewLabel("Output (虚拟输出设备名称):"),
 		container.NewBorder(nil, nil, nil, detectButton, a.outputNameEntry),
 	}
@@ -350,32 +276,6 @@ func (a *App) buildDeviceSection() fyne.CanvasObject {
 
 // buildVolumeSection creates volume control UI
 func (a *App) buildVolumeSection() fyne.CanvasObject {
-	// Input 1 gain
-	a.input1Label = widget.NewLabel(fmt.Sprintf("Input 1: %.2f", a.cfg.Input1Gain))
-	a.input1Slider = widget.NewSlider(0, 2.0)
-	a.input1Slider.Value = float64(a.cfg.Input1Gain)
-	a.input1Slider.Step = 0.01
-	a.input1Slider.OnChanged = func(value float64) {
-		a.input1Label.SetText(fmt.Sprintf("Input 1: %.2f", value))
-		a.cfg.Input1Gain = float32(value)
-		if a.isRunning && a.mixer != nil {
-			a.mixer.SetInput1Gain(float32(value))
-		}
-	}
-
-	// Input 2 gain
-	a.input2Label = widget.NewLabel(fmt.Sprintf("Input 2: %.2f", a.cfg.Input2Gain))
-	a.input2Slider = widget.NewSlider(0, 2.0)
-	a.input2Slider.Value = float64(a.cfg.Input2Gain)
-	a.input2Slider.Step = 0.01
-	a.input2Slider.OnChanged = func(value float64) {
-		a.input2Label.SetText(fmt.Sprintf("Input 2: %.2f", value))
-		a.cfg.Input2Gain = float32(value)
-		if a.isRunning && a.mixer != nil {
-			a.mixer.SetInput2Gain(float32(value))
-		}
-	}
-
 	// Master gain
 	a.masterLabel = widget.NewLabel(fmt.Sprintf("Master: %.2f", a.cfg.MasterGain))
 	a.masterSlider = widget.NewSlider(0, 2.0)
@@ -389,32 +289,81 @@ func (a *App) buildVolumeSection() fyne.CanvasObject {
 		}
 	}
 
+	// Pan law shared by all inputs
+	panLawSelect := widget.NewSelect(config.PanLaws, func(value string) {
+		a.cfg.PanLaw = value
+		if law, err := audio.ParsePanLaw(value); err == nil && a.mixer != nil {
+			a.mixer.SetPanLaw(law)
+		}
+	})
+	panLawSelect.SetSelected(a.cfg.PanLaw)
+
+	// Sidechain ducking of the other inputs under one input
+	a.duckButton = widget.NewButton("闪避 (Ducking)...", a.showDuckingDialog)
+	a.duckButton.Importance = toggleImportance(a.cfg.Ducking.Enabled)
+
+	// Equalizer on the master bus
+	a.masterEQButton = widget.NewButton("主输出均衡器 (Master EQ)...", a.showMasterEQDialog)
+	a.masterEQButton.Importance = toggleImportance(a.cfg.MasterEQ.Enabled)
+	a.masterChainButton = widget.NewButton("主输出效果链 (Master FX)...", a.showMasterChainDialog)
+	a.auxButton = widget.NewButton("辅助总线 (Aux buses)...", a.showAuxDialog)
+
+	// Master bus limiter; switching it on or off takes effect on the next start
+	a.limiterCheck = widget.NewCheck("限幅器 (Limiter)", func(on bool) {
+		a.cfg.Limiter.Enabled = on
+	})
+	a.limiterCheck.SetChecked(a.cfg.Limiter.Enabled)
+
+	ceilingLabel := widget.NewLabel(formatCeiling(a.cfg.Limiter.CeilingDB))
+	ceilingSlider := widget.NewSlider(-20, 0)
+	ceilingSlider.Value = float64(a.cfg.Limiter.CeilingDB)
+	ceilingSlider.Step = 0.1
+	ceilingSlider.OnChanged = func(value float64) {
+		ceilingLabel.SetText(formatCeiling(float32(value)))
+		a.cfg.Limiter.CeilingDB = float32(value)
+		if a.mixer != nil {
+			a.mixer.SetLimiterCeiling(float32(value))
+		}
+	}
+
 	return container.NewVBox(
-		widget.NewLabel("Volume (0.00-2.00)"),
-		a.input1Label,
-		a.input1Slider,
-		a.input2Label,
-		a.input2Slider,
+		widget.NewLabel("Volume (0.00-2.00) / Pan"),
+		a.inputGainBox,
 		a.masterLabel,
 		a.masterSlider,
+		container.New(layout.NewFormLayout(), widget.NewLabel("声像法则 (Pan law):"), panLawSelect),
+		container.NewBorder(nil, nil, a.limiterCheck, ceilingLabel, ceilingSlider),
+		container.NewGridWithColumns(2, a.duckButton, a.masterEQButton, a.masterChainButton, a.auxButton),
 	)
 }
 
+// formatCeiling formats the limiter ceiling
+func formatCeiling(ceiling float32) string {
+	return fmt.Sprintf("Ceiling: %.1f dBFS", ceiling)
+}
+
 // buildMetersSection creates level meters UI
 func (a *App) buildMetersSection() fyne.CanvasObject {
-	a.input1Meter = widget.NewProgressBar()
-	a.input2Meter = widget.NewProgressBar()
 	a.outputMeter = widget.NewProgressBar()
 	a.latencyLabel = widget.NewLabel("Latency: 0ms")
 
+	a.duckLabel = widget.NewLabel("")
+
+	// Gain reduction is shown up to gainReductionRange dB
+	a.gainReduction = widget.NewProgressBar()
+	a.gainReduction.Max = gainReductionRange
+	a.gainReduction.TextFormatter = func() string {
+		return fmt.Sprintf("-%.1f dB", a.gainReduction.Value)
+	}
+
 	return container.NewVBox(
 		widget.NewLabel("Levels"),
-		widget.NewLabel("In1:"),
-		a.input1Meter,
-		widget.NewLabel("In2:"),
-		a.input2Meter,
+		a.inputMeterBox,
 		widget.NewLabel("Out:"),
 		a.outputMeter,
+		widget.NewLabel("限幅增益衰减 (Limiter GR):"),
+		a.gainReduction,
+		a.duckLabel,
 		a.latencyLabel,
 	)
 }
@@ -569,44 +518,42 @@ func (a *App) startMixer() {
 	mixerConfig.SampleRate = a.cfg.SampleRate
 	mixerConfig.BufferSize = a.cfg.BufferSize
 	mixerConfig.Channels = a.cfg.Channels
-	mixerConfig.Input1Gain = a.cfg.Input1Gain
-	mixerConfig.Input2Gain = a.cfg.Input2Gain
 	mixerConfig.MasterGain = a.cfg.MasterGain
+	mixerConfig.DriftCompensation = a.cfg.DriftCompensation
+	mixerConfig.GainRampTime = time.Duration(a.cfg.GainSmoothingMs) * time.Millisecond
+	mixerConfig.Limiter = audio.LimiterConfig{
+		Enabled:   a.cfg.Limiter.Enabled,
+		Ceiling:   a.cfg.Limiter.CeilingDB,
+		Release:   a.cfg.Limiter.Release(),
+		LookAhead: a.cfg.Limiter.LookAhead(),
+	}
 	mixerConfig.UseVirtualOutput = a.cfg.UseVirtualOutput
-
-	// Get Input 1 device (microphone/line input)
-	if a.cfg.Input1DeviceIndex >= 0 {
-		dev, err := a.deviceManager.GetDeviceByIndex(a.cfg.Input1DeviceIndex)
-		if err != nil {
-			a.statusLabel.SetText(fmt.Sprintf("Error getting Input 1: %v", err))
-			return
-		}
-		mixerConfig.Input1Device = dev
-	} else {
-		// Use default input device
-		dev, err := a.deviceManager.GetDefaultInputDevice()
-		if err == nil {
-			mixerConfig.Input1Device = dev
-		}
+	if law, err := audio.ParsePanLaw(a.cfg.PanLaw); err == nil {
+		mixerConfig.PanLaw = law
 	}
 
-	// Get Input 2 device (system audio via loopback)
-	if a.cfg.Input2DeviceIndex >= 0 {
-		dev, err := a.deviceManager.GetDeviceByIndex(a.cfg.Input2DeviceIndex)
+	// Get input devices
+	for i, input := range a.cfg.Inputs {
+		a.inputRows[i].mixerIndex = -1
+
+		dev, err := a.resolveInputDevice(input)
 		if err != nil {
-			a.statusLabel.SetText(fmt.Sprintf("Error getting Input 2: %v", err))
+			a.statusLabel.SetText(fmt.Sprintf("Error getting Input %d: %v", i+1, err))
 			return
 		}
-		mixerConfig.Input2Device = dev
-	} else {
-		// Auto-detect loopback device for system audio
-		loopback, err := audio.FindLoopbackDevice(a.deviceManager)
-		if err != nil {
-			a.statusLabel.SetText(fmt.Sprintf("未找到虚拟设备! 请安装 BlackHole: %v", err))
-			return
+		if dev == nil {
+			continue
 		}
-		mixerConfig.Input2Device = loopback.Device
-		a.statusLabel.SetText(fmt.Sprintf("自动检测到虚拟设备: %s", loopback.Name))
+
+		a.inputRows[i].mixerIndex = len(mixerConfig.Inputs)
+		mixerConfig.Inputs = append(mixerConfig.Inputs, a.mixerInput(input, dev))
+	}
+	mixerConfig.Ducking = a.mixerDucking()
+	mixerConfig.MasterEQ = a.cfg.MasterEQ.Mixer()
+	mixerConfig.MasterInserts = config.MixerInserts(a.cfg.MasterInserts)
+	mixerConfig.AuxBuses = config.MixerAuxBuses(a.cfg.AuxBuses)
+	if channels := a.cfg.OutputDeviceChannels(); channels != nil {
+		mixerConfig.OutputChannelMap = audio.PlaybackChannelMap(mixerConfig.Channels, channels)
 	}
 
 	// Get Output device (virtual device by custom name)
@@ -630,102 +577,191 @@ func (a *App) startMixer() {
 		a.statusLabel.SetText(fmt.Sprintf("自动检测到虚拟输出: %s", loopback.Name))
 	}
 
-	// Create mixer
-	mixer, err := audio.NewMixer(mixerConfig)
-	if err != nil {
-		a.statusLabel.SetText(fmt.Sprintf("Error creating mixer: %v", err))
+	// Create the mixer once; later runs restart it with the current devices
+	if a.mixer == nil {
+		mixer, err := audio.NewMixer(mixerConfig)
+		if err != nil {
+			a.statusLabel.SetText(fmt.Sprintf("Error creating mixer: %v", err))
+			return
+		}
+		mixer.OnStateChange(a.onMixerStateChange)
+		a.mixer = mixer
+	} else if err := a.reconfigureMixer(mixerConfig); err != nil {
+		a.statusLabel.SetText(fmt.Sprintf("Error configuring mixer: %v", err))
 		return
 	}
-	a.mixer = mixer
 
-	// Start mixer
+	// Start mixer; UI state follows the mixer's state changes
 	if err := a.mixer.Start(); err != nil {
 		a.statusLabel.SetText(fmt.Sprintf("Error starting mixer: %v", err))
 		return
 	}
+}
+
+// reconfigureMixer applies a new device selection to the stopped mixer
+func (a *App) reconfigureMixer(mixerConfig *audio.MixerConfig) error {
+	if err := a.mixer.SetOutputDevice(mixerConfig.OutputDevice); err != nil {
+		return err
+	}
 
-	a.isRunning = true
-	a.startButton.Disable()
-	a.stopButton.Enable()
-	a.statusLabel.SetText("混音器运行中 (Mixer running)")
+	for a.mixer.NumInputs() > 0 {
+		if err := a.mixer.RemoveInput(0); err != nil {
+			return err
+		}
+	}
+	for _, input := range mixerConfig.Inputs {
+		if _, err := a.mixer.AddInput(input); err != nil {
+			return err
+		}
+	}
 
-	// Start meter update loop
-	go a.updateMeters()
+	a.mixer.SetMasterGain(mixerConfig.MasterGain)
+	a.mixer.SetPanLaw(mixerConfig.PanLaw)
+	a.mixer.SetDucking(mixerConfig.Ducking)
+	a.mixer.SetMasterEQ(mixerConfig.MasterEQ)
+	for b, bus := range mixerConfig.AuxBuses {
+		a.mixer.SetAuxReturn(b, bus.Return)
+	}
+	return a.mixer.SetLimiter(mixerConfig.Limiter)
 }
 
 // stopMixer stops the audio mixer
 func (a *App) stopMixer() {
-	if !a.isRunning {
+	if !a.isRunning || a.mixer == nil {
 		return
 	}
 
-	if a.mixer != nil {
-		if err := a.mixer.Stop(); err != nil {
-			a.statusLabel.SetText(fmt.Sprintf("Error stopping mixer: %v", err))
+	if err := a.mixer.Stop(); err != nil {
+		a.statusLabel.SetText(fmt.Sprintf("Error stopping mixer: %v", err))
+	}
+}
+
+// onMixerStateChange updates the controls whenever the mixer changes state
+func (a *App) onMixerStateChange(change audio.StateChange) {
+	switch change.To {
+	case audio.StateStarting:
+		a.startButton.Disable()
+		a.statusLabel.SetText("混音器启动中 (Starting)...")
+
+	case audio.StateRunning:
+		a.isRunning = true
+		a.startButton.Disable()
+		a.stopButton.Enable()
+		a.limiterCheck.Disable()
+		a.statusLabel.SetText("混音器运行中 (Mixer running)" + a.resamplingSummary())
+
+		// Start meter update loop
+		go a.updateMeters()
+
+	case audio.StateStopping:
+		a.stopButton.Disable()
+
+	case audio.StateIdle, audio.StateFailed:
+		a.isRunning = false
+		a.startButton.Enable()
+		a.stopButton.Disable()
+		a.limiterCheck.Enable()
+		if change.To == audio.StateFailed {
+			a.statusLabel.SetText(fmt.Sprintf("混音器错误 (Mixer failed): %v", change.Err))
+		} else {
+			a.statusLabel.SetText("Mixer stopped")
+		}
+
+		// Reset meters
+		for _, row := range a.inputRows {
+			row.meter.SetValue(0)
+			row.compLabel.SetText("")
+			row.deEsserLabel.SetText("")
+			row.agcLabel.SetText("")
+			row.gateLED.Hide()
 		}
-		a.mixer = nil
+		a.outputMeter.SetValue(0)
+		a.gainReduction.SetValue(0)
+		a.duckLabel.SetText("")
+		a.latencyLabel.SetText("Latency: 0ms")
 	}
+}
 
-	a.isRunning = false
-	a.startButton.Enable()
-	a.stopButton.Disable()
-	a.statusLabel.SetText("Mixer stopped")
+// resamplingSummary lists the streams converted to or from the mixer rate
+func (a *App) resamplingSummary() string {
+	var streams []string
+	for i := 0; i < a.mixer.NumInputs(); i++ {
+		if info := a.mixer.GetInputStreamInfo(i); info.Resampled() {
+			streams = append(streams, fmt.Sprintf("%s %.0f→%.0f Hz", a.mixer.GetInputName(i), info.SampleRate, info.MixerRate))
+		}
+	}
+	if info := a.mixer.GetOutputStreamInfo(); info.Resampled() {
+		streams = append(streams, fmt.Sprintf("Output %.0f→%.0f Hz", info.MixerRate, info.SampleRate))
+	}
 
-	// Reset meters
-	a.input1Meter.SetValue(0)
-	a.input2Meter.SetValue(0)
-	a.outputMeter.SetValue(0)
-	a.latencyLabel.SetText("Latency: 0ms")
+	if len(streams) == 0 {
+		return ""
+	}
+	return "\n重采样 (Resampling): " + strings.Join(streams, ", ")
 }
 
-// updateMeters updates the level meters
+// updateMeters updates the level meters while the mixer is running
 func (a *App) updateMeters() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	for a.isRunning {
+	for a.mixer.IsRunning() {
 		<-ticker.C
-		if a.mixer != nil {
-			input1Level := a.mixer.GetInput1Level()
-			input2Level := a.mixer.GetInput2Level()
-			outputLevel := a.mixer.GetOutputLevel()
-			latency := a.mixer.GetLatency()
+		for _, row := range a.inputRows {
+			if row.mixerIndex < 0 {
+				continue
+			}
 
 			// Clamp to 0-1 range for display
-			if input1Level > 1.0 {
-				input1Level = 1.0
+			level := a.mixer.GetInputLevel(row.mixerIndex)
+			if level > 1.0 {
+				level = 1.0
+			}
+			row.meter.SetValue(float64(level))
+			row.xrunLabel.SetText(formatXruns(a.mixer.GetInputXruns(row.mixerIndex)))
+			setGateLED(row.gateLED, a.mixer.GetInputGate(row.mixerIndex).Enabled, a.mixer.IsInputGateOpen(row.mixerIndex))
+			if a.mixer.GetInputCompressor(row.mixerIndex).Enabled {
+				row.compLabel.SetText(formatGainReduction(a.mixer.GetInputCompressorGainReduction(row.mixerIndex)))
+			} else {
+				row.compLabel.SetText("")
 			}
-			if input2Level > 1.0 {
-				input2Level = 1.0
+			switch deEsser := a.mixer.GetInputDeEsser(row.mixerIndex); {
+			case !deEsser.Enabled:
+				row.deEsserLabel.SetText("")
+			case deEsser.Listen:
+				row.deEsserLabel.SetText("DS listen")
+			default:
+				row.deEsserLabel.SetText("DS " + formatGainReduction(a.mixer.GetInputDeEsserGainReduction(row.mixerIndex)))
 			}
-			if outputLevel > 1.0 {
-				outputLevel = 1.0
+			if a.mixer.GetInputAGC(row.mixerIndex).Enabled {
+				row.agcLabel.SetText(formatAGCGain(a.mixer.GetInputAGCGain(row.mixerIndex)))
+			} else {
+				row.agcLabel.SetText("")
 			}
+		}
 
-			a.input1Meter.SetValue(float64(input1Level))
-			a.input2Meter.SetValue(float64(input2Level))
-			a.outputMeter.SetValue(float64(outputLevel))
-			a.latencyLabel.SetText(fmt.Sprintf("Latency: %v", latency.Round(time.Microsecond)))
+		outputLevel := a.mixer.GetOutputLevel()
+		latency := a.mixer.GetLatency()
+		if outputLevel > 1.0 {
+			outputLevel = 1.0
+		}
+		a.outputMeter.SetValue(float64(outputLevel))
+		a.gainReduction.SetValue(math.Min(float64(a.mixer.GetLimiterGainReduction()), gainReductionRange))
+		if a.mixer.GetDucking().Enabled {
+			a.duckLabel.SetText("闪避 (Ducking): " + formatGainReduction(a.mixer.GetDuckingGainReduction()))
+		} else {
+			a.duckLabel.SetText("")
 		}
+		a.latencyLabel.SetText(fmt.Sprintf("Latency: %v", latency.Round(time.Microsecond)))
 	}
 }
 
 // updateConfig updates the config from UI selections
 func (a *App) updateConfig() {
 	// Parse device indices from selection
-	if a.input1Select != nil && a.input1Select.Selected != "" && a.input1Select.Selected != "<None>" {
-		var idx int
-		fmt.Sscanf(a.input1Select.Selected, "[%d]", &idx)
-		a.cfg.Input1DeviceIndex = idx
-	}
-
-	if a.input2Select != nil {
-		if a.input2Select.Selected != "" && a.input2Select.Selected != "<None>" {
-			var idx int
-			fmt.Sscanf(a.input2Select.Selected, "[%d]", &idx)
-			a.cfg.Input2DeviceIndex = idx
-		} else {
-			a.cfg.Input2DeviceIndex = -2
+	for i, row := range a.inputRows {
+		if i < len(a.cfg.Inputs) {
+			applyInputOption(row.deviceSelect.Selected, &a.cfg.Inputs[i])
 		}
 	}
 
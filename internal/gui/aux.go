@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// showSendDialog opens the aux bus send levels of the input at index i;
+// every change is applied to a running mixer right away
+func (a *App) showSendDialog(i int) {
+	input := &a.cfg.Inputs[i]
+	row := a.inputRows[i]
+	if len(a.cfg.AuxBuses) == 0 {
+		a.statusLabel.SetText("没有辅助总线 (No aux buses configured)")
+		return
+	}
+	for len(input.Sends) < len(a.cfg.AuxBuses) {
+		input.Sends = append(input.Sends, 0)
+	}
+
+	form := container.New(layout.NewFormLayout())
+	for b, bus := range a.cfg.AuxBuses {
+		b := b
+		label := widget.NewLabel(fmt.Sprintf("%.2f", input.Sends[b]))
+		slider := widget.NewSlider(0, 2.0)
+		slider.Step = 0.01
+		slider.Value = float64(input.Sends[b])
+		slider.OnChanged = func(v float64) {
+			input.Sends[b] = float32(v)
+			label.SetText(fmt.Sprintf("%.2f", v))
+			row.sendButton.Importance = toggleImportance(hasSends(input))
+			row.sendButton.Refresh()
+			if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+				a.mixer.SetInputSend(row.mixerIndex, b, float32(v))
+			}
+		}
+		form.Add(widget.NewLabel(fmt.Sprintf("Aux %d (%s)", b+1, bus.Name)))
+		form.Add(container.NewBorder(nil, nil, nil, label, slider))
+	}
+
+	d := dialog.NewCustom(fmt.Sprintf("Input %d 辅助发送 (Aux sends)", i+1), "关闭 (Close)", form, a.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+// showAuxDialog opens the return levels of the aux buses, each with a
+// button to its effects
+func (a *App) showAuxDialog() {
+	if len(a.cfg.AuxBuses) == 0 {
+		a.statusLabel.SetText("没有辅助总线 (No aux buses configured)")
+		return
+	}
+
+	form := container.New(layout.NewFormLayout())
+	for b := range a.cfg.AuxBuses {
+		b := b
+		bus := &a.cfg.AuxBuses[b]
+		label := widget.NewLabel(fmt.Sprintf("%.2f", bus.ReturnGain))
+		slider := widget.NewSlider(0, 2.0)
+		slider.Step = 0.01
+		slider.Value = float64(bus.ReturnGain)
+		slider.OnChanged = func(v float64) {
+			bus.ReturnGain = float32(v)
+			label.SetText(fmt.Sprintf("%.2f", v))
+			if a.mixer != nil {
+				a.mixer.SetAuxReturn(b, float32(v))
+			}
+		}
+		effects := widget.NewButton("效果 (FX)...", func() {
+			if a.mixer == nil || b >= a.mixer.NumAuxBuses() {
+				a.statusLabel.SetText("请先启动混音器 (Start the mixer first)")
+				return
+			}
+			a.showChainDialog(fmt.Sprintf("Aux %d 效果链 (%s)", b+1, bus.Name), a.mixer.AuxChain(b), &bus.Inserts)
+		})
+		form.Add(widget.NewLabel(fmt.Sprintf("Aux %d (%s) 返回 (Return)", b+1, bus.Name)))
+		form.Add(container.NewBorder(nil, nil, nil, container.NewHBox(label, effects), slider))
+	}
+
+	d := dialog.NewCustom("辅助总线 (Aux buses)", "关闭 (Close)", form, a.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// hasSends reports whether an input sends to any aux bus
+func hasSends(input *config.InputConfig) bool {
+	for _, level := range input.Sends {
+		if level > 0 {
+			return true
+		}
+	}
+	return false
+}
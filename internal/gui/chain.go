@@ -0,0 +1,168 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/audio"
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// showInputChainDialog opens the insert chain of the input at index i. The
+// chain is edited live, so the input must be running.
+func (a *App) showInputChainDialog(i int) {
+	row := a.inputRows[i]
+	if !a.isRunning || a.mixer == nil || row.mixerIndex < 0 {
+		a.statusLabel.SetText("请先启动混音器 (Start the mixer first)")
+		return
+	}
+	a.showChainDialog(fmt.Sprintf("Input %d 效果链 (Insert chain)", i+1), a.mixer.InputChain(row.mixerIndex), &a.cfg.Inputs[i].Inserts)
+}
+
+// showMasterChainDialog opens the insert chain of the master bus
+func (a *App) showMasterChainDialog() {
+	if a.mixer == nil {
+		a.statusLabel.SetText("请先启动混音器 (Start the mixer first)")
+		return
+	}
+	a.showChainDialog("主输出效果链 (Master insert chain)", a.mixer.MasterChain(), &a.cfg.MasterInserts)
+}
+
+// showChainDialog lists the slots of chain, one row each with bypass, move
+// and remove controls, and the parameters of added processors; every edit
+// is saved to inserts
+func (a *App) showChainDialog(title string, chain *audio.InsertChain, inserts *[]config.InsertConfig) {
+	slots := container.NewVBox()
+	latency := widget.NewLabel("")
+	save := func() {
+		*inserts = config.InsertsFromMixer(chain.Config())
+		latency.SetText(fmt.Sprintf("%v", chain.Latency().Round(time.Microsecond)))
+	}
+	report := func(err error) {
+		if err != nil {
+			a.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+		}
+	}
+
+	var rebuild func()
+	rebuild = func() {
+		slots.RemoveAll()
+		for s, slot := range chain.Slots() {
+			s := s
+			bypass := widget.NewCheck("旁通 (Bypass)", func(on bool) {
+				report(chain.SetBypass(s, on))
+				save()
+			})
+			bypass.SetChecked(slot.Bypass)
+
+			up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+				report(chain.Move(s, s-1))
+				save()
+				rebuild()
+			})
+			if s == 0 {
+				up.Disable()
+			}
+			down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+				report(chain.Move(s, s+1))
+				save()
+				rebuild()
+			})
+			if s == chain.Len()-1 {
+				down.Disable()
+			}
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				report(chain.Remove(s))
+				save()
+				rebuild()
+			})
+
+			name := slot.Processor.Type()
+			if slot.Builtin {
+				name += " (内置 built in)"
+				remove.Disable()
+			}
+			if latency := slot.Processor.Latency(); latency > 0 {
+				name += fmt.Sprintf(", %v", latency.Round(time.Microsecond))
+			}
+			header := container.NewBorder(nil, nil, widget.NewLabel(fmt.Sprintf("%d. %s", s+1, name)),
+				container.NewHBox(bypass, up, down, remove))
+			slots.Add(header)
+			if !slot.Builtin {
+				slots.Add(newProcessorEditor(slot.Processor, save))
+			}
+			slots.Add(widget.NewSeparator())
+		}
+	}
+
+	types := widget.NewSelect(audio.ProcessorTypes(), nil)
+	types.PlaceHolder = "效果类型 (Processor type)"
+	add := widget.NewButtonWithIcon("添加 (Add)", theme.ContentAddIcon(), func() {
+		if types.Selected == "" {
+			return
+		}
+		p, err := audio.NewProcessor(types.Selected, a.cfg.Channels, a.cfg.SampleRate)
+		if err == nil {
+			err = chain.Insert(chain.Len(), p)
+		}
+		report(err)
+		save()
+		rebuild()
+	})
+
+	save()
+	rebuild()
+	scroll := container.NewVScroll(slots)
+	scroll.SetMinSize(fyne.NewSize(0, 320))
+	content := container.NewVBox(
+		scroll,
+		container.NewBorder(nil, nil, nil, add, types),
+		container.New(layout.NewFormLayout(), widget.NewLabel("延迟 (Latency)"), latency),
+	)
+	d := dialog.NewCustom(title, "关闭 (Close)", content, a.window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// newProcessorEditor creates a slider for every parameter of p; onChanged
+// follows every change
+func newProcessorEditor(p audio.Processor, onChanged func()) fyne.CanvasObject {
+	form := container.New(layout.NewFormLayout())
+	for _, param := range p.Parameters() {
+		param := param
+		value, _ := p.Parameter(param.Name)
+		label := widget.NewLabel(formatParameter(value, param))
+		slider := widget.NewSlider(param.Min, param.Max)
+		slider.Step = param.Step
+		if slider.Step == 0 {
+			slider.Step = (param.Max - param.Min) / 200
+		}
+		slider.Value = value
+		slider.OnChanged = func(v float64) {
+			if err := p.SetParameter(param.Name, v); err == nil {
+				label.SetText(formatParameter(v, param))
+				onChanged()
+			}
+		}
+		form.Add(widget.NewLabel(param.Name))
+		form.Add(container.NewBorder(nil, nil, nil, label, slider))
+	}
+	return form
+}
+
+// formatParameter formats a parameter value with its unit
+func formatParameter(value float64, param audio.Parameter) string {
+	format := "%.2f %s"
+	if param.Step >= 1 {
+		format = "%.0f %s"
+	}
+	return strings.TrimSpace(fmt.Sprintf(format, value, param.Unit))
+}
@@ -0,0 +1,138 @@
+package gui
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/audio"
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// showDelayDialog opens the delay of the input at index i, with automatic
+// alignment against another input; every change is applied to a running
+// mixer right away
+func (a *App) showDelayDialog(i int) {
+	input := &a.cfg.Inputs[i]
+	row := a.inputRows[i]
+
+	label := widget.NewLabel(audio.FormatDelay(input.Delay(), a.cfg.SampleRate))
+	slider := widget.NewSlider(0, config.MaxDelayMs)
+	slider.Step = 0.5
+	slider.Value = input.DelayMs
+
+	// The mixer applies an alignment as soon as it is measured, in the
+	// background; the result waits in pending until the UI takes it over
+	// into the settings, on Apply, on any other change or on closing. A
+	// measurement that ends after closing is undone instead.
+	pending := make(chan audio.Alignment, 1)
+	var closed atomic.Bool
+	var apply *widget.Button
+	var commit func()
+
+	slider.OnChanged = func(v float64) {
+		commit()
+		input.DelayMs = v
+		label.SetText(audio.FormatDelay(input.Delay(), a.cfg.SampleRate))
+		row.delayButton.Importance = toggleImportance(v > 0)
+		row.delayButton.Refresh()
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputDelay(row.mixerIndex, input.Delay())
+		}
+	}
+
+	// Alignment against any other input the mixer runs
+	var others []string
+	var otherIndex []int
+	for j := range a.cfg.Inputs {
+		if j != i {
+			others = append(others, fmt.Sprintf("Input %d (%s)", j+1, a.cfg.Inputs[j].Name))
+			otherIndex = append(otherIndex, j)
+		}
+	}
+	reference := widget.NewSelect(others, nil)
+	if len(others) > 0 {
+		reference.SetSelectedIndex(0)
+	}
+	result := widget.NewLabel("")
+
+	var align *widget.Button
+	var refIndex int
+	commit = func() {
+		select {
+		case alignment := <-pending:
+			apply.Disable()
+			refRow := a.inputRows[refIndex]
+			a.cfg.Inputs[refIndex].DelayMs = float64(alignment.ReferenceDelay) / float64(time.Millisecond)
+			refRow.delayButton.Importance = toggleImportance(alignment.ReferenceDelay > 0)
+			refRow.delayButton.Refresh()
+			slider.SetValue(float64(alignment.Delay) / float64(time.Millisecond))
+		default:
+		}
+	}
+	apply = widget.NewButton("应用 (Apply)", commit)
+	apply.Disable()
+
+	align = widget.NewButton("自动对齐 (Align)", func() {
+		if reference.SelectedIndex() < 0 {
+			return
+		}
+		commit()
+		j := otherIndex[reference.SelectedIndex()]
+		refRow := a.inputRows[j]
+		if !a.isRunning || a.mixer == nil || row.mixerIndex < 0 || refRow.mixerIndex < 0 {
+			result.SetText("请先启动混音器 (Start the mixer first)")
+			return
+		}
+
+		align.Disable()
+		refIndex = j
+		sampleRate := a.cfg.SampleRate
+		refDelay, delay := a.cfg.Inputs[j].Delay(), input.Delay()
+		result.SetText(fmt.Sprintf("请拍手一次 (Clap once now, listening for %v)...", audio.AlignCaptureTime))
+		go func() {
+			defer align.Enable()
+			alignment, err := a.mixer.AlignInput(refRow.mixerIndex, row.mixerIndex)
+			if err != nil {
+				result.SetText(fmt.Sprintf("对齐失败 (Alignment failed): %v", err))
+				return
+			}
+			pending <- alignment
+			if closed.Load() {
+				select {
+				case <-pending:
+					a.mixer.SetInputDelay(refRow.mixerIndex, refDelay)
+					a.mixer.SetInputDelay(row.mixerIndex, delay)
+				default:
+				}
+				return
+			}
+			label.SetText(audio.FormatDelay(alignment.Delay, sampleRate))
+			result.SetText(fmt.Sprintf("偏移 (Offset) %v, Input %d: %s",
+				alignment.Offset.Round(10*time.Microsecond), j+1, audio.FormatDelay(alignment.ReferenceDelay, sampleRate)))
+			apply.Enable()
+		}()
+	})
+	if len(others) == 0 {
+		align.Disable()
+	}
+
+	form := container.New(layout.NewFormLayout(),
+		widget.NewLabel("延迟 (Delay)"), container.NewBorder(nil, nil, nil, label, slider),
+		widget.NewLabel("参考输入 (Reference)"), container.NewBorder(nil, nil, nil, align, reference),
+		widget.NewLabel(""), container.NewBorder(nil, nil, nil, apply, result),
+	)
+	d := dialog.NewCustom(fmt.Sprintf("Input %d 延迟对齐 (Delay)", i+1), "关闭 (Close)", form, a.window)
+	d.SetOnClosed(func() {
+		closed.Store(true)
+		commit()
+	})
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
@@ -0,0 +1,71 @@
+package gui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// showDenoiserDialog opens the noise suppression settings of the input at
+// index i; every change is applied to a running mixer right away
+func (a *App) showDenoiserDialog(i int) {
+	if a.cfg.Inputs[i].Denoiser == nil {
+		defaults := config.DefaultDenoiserConfig()
+		a.cfg.Inputs[i].Denoiser = &defaults
+	}
+	d := a.cfg.Inputs[i].Denoiser
+	row := a.inputRows[i]
+
+	latency := widget.NewLabel("-")
+	apply := func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputDenoiser(row.mixerIndex, d.Mixer())
+			latency.SetText(fmt.Sprintf("%v", a.mixer.GetInputDenoiserLatency(row.mixerIndex).Round(time.Microsecond)))
+		}
+	}
+	apply()
+
+	bypass := widget.NewCheck("旁通 (Bypass)", func(on bool) {
+		d.Bypass = on
+		apply()
+	})
+	bypass.SetChecked(d.Bypass)
+
+	adaptive := widget.NewCheck("自动跟踪噪声 (Adaptive)", func(on bool) {
+		d.Adaptive = on
+		apply()
+	})
+	adaptive.SetChecked(d.Adaptive)
+
+	// Learning needs a running mixer; the button stays disabled until the
+	// noise profile is complete
+	var learn *widget.Button
+	learn = widget.NewButton("学习噪声 (Learn noise)", func() {
+		if !a.isRunning || a.mixer == nil || row.mixerIndex < 0 || !d.Enabled {
+			a.statusLabel.SetText("请先启用降噪并启动混音器 (Enable noise suppression and start the mixer first)")
+			return
+		}
+		index := row.mixerIndex
+		a.mixer.LearnInputNoise(index)
+		learn.Disable()
+		learn.SetText("学习中, 请保持安静... (Learning, keep quiet)")
+		go func() {
+			for a.mixer.IsInputLearningNoise(index) && a.isRunning {
+				time.Sleep(100 * time.Millisecond)
+			}
+			learn.SetText("学习噪声 (Learn noise)")
+			learn.Enable()
+		}()
+	})
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 降噪 (Noise suppression)", i+1), &d.Enabled, row.denoiserButton, apply,
+		widget.NewLabel("强度 (Strength)"), newSettingSlider(&d.Strength, 0, 1, 0.01, "%.2f", apply),
+		widget.NewLabel(""), adaptive,
+		widget.NewLabel(""), bypass,
+		widget.NewLabel("噪声样本 (Profile)"), learn,
+		widget.NewLabel("延迟 (Latency)"), latency,
+	)
+}
@@ -0,0 +1,297 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/audio"
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// showFilterDialog opens the DC blocker and high-pass filter settings of
+// the input at index i; every change is applied to a running mixer right away
+func (a *App) showFilterDialog(i int) {
+	if a.cfg.Inputs[i].Filter == nil {
+		defaults := config.DefaultFilterConfig()
+		a.cfg.Inputs[i].Filter = &defaults
+	}
+	f := a.cfg.Inputs[i].Filter
+	row := a.inputRows[i]
+
+	apply := func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputFilter(row.mixerIndex, f.Mixer())
+		}
+	}
+
+	dcBlocker := widget.NewCheck("去除直流偏移 (DC blocker)", func(on bool) {
+		f.DCBlocker = on
+		apply()
+	})
+	dcBlocker.SetChecked(f.DCBlocker)
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 高通滤波 (High-pass filter)", i+1), &f.HighPass, row.filterButton, apply,
+		widget.NewLabel("截止频率 (Cutoff)"), newFrequencySelect(config.HighPassCutoffs, &f.HighPassHz, apply),
+		widget.NewLabel(""), dcBlocker,
+	)
+}
+
+// showGateDialog opens the noise gate settings of the input at index i;
+// every change is applied to a running mixer right away
+func (a *App) showGateDialog(i int) {
+	if a.cfg.Inputs[i].Gate == nil {
+		defaults := config.DefaultGateConfig()
+		a.cfg.Inputs[i].Gate = &defaults
+	}
+	g := a.cfg.Inputs[i].Gate
+	row := a.inputRows[i]
+
+	apply := func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputGate(row.mixerIndex, g.Mixer())
+		}
+	}
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 噪声门 (Noise gate)", i+1), &g.Enabled, row.gateButton, apply,
+		widget.NewLabel("阈值 (Threshold)"), newSettingSlider(&g.ThresholdDB, -90, 0, 0.5, "%.1f dB", apply),
+		widget.NewLabel("回差 (Hysteresis)"), newSettingSlider(&g.HysteresisDB, 0, 20, 0.5, "%.1f dB", apply),
+		widget.NewLabel("保持 (Hold)"), newSettingSlider(&g.HoldMs, 0, 2000, 1, "%.0f ms", apply),
+		widget.NewLabel("启动 (Attack)"), newSettingSlider(&g.AttackMs, 0.1, 500, 0.1, "%.1f ms", apply),
+		widget.NewLabel("释放 (Release)"), newSettingSlider(&g.ReleaseMs, 1, 5000, 1, "%.0f ms", apply),
+		widget.NewLabel("衰减范围 (Range)"), newSettingSlider(&g.RangeDB, -90, 0, 0.5, "%.1f dB", apply),
+	)
+}
+
+// showAGCDialog opens the automatic gain control settings of the input at
+// index i; every change is applied to a running mixer right away
+func (a *App) showAGCDialog(i int) {
+	if a.cfg.Inputs[i].AGC == nil {
+		defaults := config.DefaultAGCConfig()
+		a.cfg.Inputs[i].AGC = &defaults
+	}
+	c := a.cfg.Inputs[i].AGC
+	row := a.inputRows[i]
+
+	apply := func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputAGC(row.mixerIndex, c.Mixer())
+		}
+	}
+
+	mode := widget.NewSelect(config.AGCModes, nil)
+	mode.SetSelected(c.Mode)
+	mode.OnChanged = func(value string) {
+		c.Mode = value
+		apply()
+	}
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 自动增益 (AGC)", i+1), &c.Enabled, row.agcButton, apply,
+		widget.NewLabel("测量 (Measure)"), mode,
+		widget.NewLabel("目标电平 (Target)"), newSettingSlider(&c.TargetDB, -60, 0, 0.5, "%.1f dB", apply),
+		widget.NewLabel("最大提升 (Max boost)"), newSettingSlider(&c.MaxBoostDB, 0, 40, 0.5, "%.1f dB", apply),
+		widget.NewLabel("最大衰减 (Max cut)"), newSettingSlider(&c.MaxCutDB, 0, 40, 0.5, "%.1f dB", apply),
+		widget.NewLabel("调节速度 (Rate)"), newSettingSlider(&c.RateDBPerSec, 0.1, 60, 0.1, "%.1f dB/s", apply),
+		widget.NewLabel("静音冻结 (Freeze below)"), newSettingSlider(&c.FreezeDB, -90, 0, 0.5, "%.1f dB", apply),
+	)
+}
+
+// showCompressorDialog opens the compressor settings of the input at index
+// i; every change is applied to a running mixer right away
+func (a *App) showCompressorDialog(i int) {
+	if a.cfg.Inputs[i].Compressor == nil {
+		defaults := config.DefaultCompressorConfig()
+		a.cfg.Inputs[i].Compressor = &defaults
+	}
+	c := a.cfg.Inputs[i].Compressor
+	row := a.inputRows[i]
+
+	apply := func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputCompressor(row.mixerIndex, c.Mixer())
+		}
+	}
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 压缩器 (Compressor)", i+1), &c.Enabled, row.compButton, apply,
+		widget.NewLabel("阈值 (Threshold)"), newSettingSlider(&c.ThresholdDB, -60, 0, 0.5, "%.1f dB", apply),
+		widget.NewLabel("压缩比 (Ratio)"), newSettingSlider(&c.Ratio, 1, 20, 0.1, "%.1f:1", apply),
+		widget.NewLabel("拐点 (Knee)"), newSettingSlider(&c.KneeDB, 0, 24, 0.5, "%.1f dB", apply),
+		widget.NewLabel("启动 (Attack)"), newSettingSlider(&c.AttackMs, 0.1, 500, 0.1, "%.1f ms", apply),
+		widget.NewLabel("释放 (Release)"), newSettingSlider(&c.ReleaseMs, 1, 5000, 1, "%.0f ms", apply),
+		widget.NewLabel("补偿增益 (Makeup)"), newSettingSlider(&c.MakeupGainDB, 0, 24, 0.5, "%.1f dB", apply),
+	)
+}
+
+// showDeEsserDialog opens the de-esser settings of the input at index i;
+// every valid change is applied to a running mixer right away
+func (a *App) showDeEsserDialog(i int) {
+	if a.cfg.Inputs[i].DeEsser == nil {
+		defaults := config.DefaultDeEsserConfig()
+		a.cfg.Inputs[i].DeEsser = &defaults
+	}
+	d := a.cfg.Inputs[i].DeEsser
+	row := a.inputRows[i]
+
+	apply := func() {
+		if err := config.ValidateDeEsser(*d); err != nil {
+			a.statusLabel.SetText(fmt.Sprintf("去齿音 (De-esser): %v", err))
+			return
+		}
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputDeEsser(row.mixerIndex, d.Mixer())
+		}
+	}
+
+	listen := widget.NewCheck("监听齿音频段 (Listen to band)", func(on bool) {
+		d.Listen = on
+		apply()
+	})
+	listen.SetChecked(d.Listen)
+
+	a.showSettingsDialog(fmt.Sprintf("Input %d 去齿音 (De-esser)", i+1), &d.Enabled, row.deEsserButton, apply,
+		widget.NewLabel("频段下限 (Low edge)"), newFrequencySelect(config.DeEsserLowEdges, &d.LowHz, apply),
+		widget.NewLabel("频段上限 (High edge)"), newFrequencySelect(config.DeEsserHighEdges, &d.HighHz, apply),
+		widget.NewLabel("阈值 (Threshold)"), newSettingSlider(&d.ThresholdDB, -60, 0, 0.5, "%.1f dB", apply),
+		widget.NewLabel("最大衰减 (Reduction)"), newSettingSlider(&d.ReductionDB, 0, 24, 0.5, "%.1f dB", apply),
+		widget.NewLabel(""), listen,
+	)
+}
+
+// showDuckingDialog opens the sidechain ducking settings; every change is
+// applied to a running mixer right away
+func (a *App) showDuckingDialog() {
+	d := &a.cfg.Ducking
+
+	var inputs []string
+	for i := range a.cfg.Inputs {
+		inputs = append(inputs, fmt.Sprintf("Input %d (%s)", i+1, a.cfg.Inputs[i].Name))
+	}
+	trigger := widget.NewSelect(inputs, nil)
+	if d.TriggerInput >= 1 && d.TriggerInput <= len(inputs) {
+		trigger.SetSelectedIndex(d.TriggerInput - 1)
+	}
+	trigger.OnChanged = func(string) {
+		d.TriggerInput = trigger.SelectedIndex() + 1
+		a.applyDucking()
+	}
+
+	a.showSettingsDialog("闪避 (Ducking)", &d.Enabled, a.duckButton, a.applyDucking,
+		widget.NewLabel("触发输入 (Trigger)"), trigger,
+		widget.NewLabel("阈值 (Threshold)"), newSettingSlider(&d.ThresholdDB, -90, 0, 0.5, "%.1f dB", a.applyDucking),
+		widget.NewLabel("深度 (Depth)"), newSettingSlider(&d.DepthDB, 0, 60, 0.5, "%.1f dB", a.applyDucking),
+		widget.NewLabel("启动 (Attack)"), newSettingSlider(&d.AttackMs, 1, 1000, 1, "%.0f ms", a.applyDucking),
+		widget.NewLabel("保持 (Hold)"), newSettingSlider(&d.HoldMs, 0, 5000, 10, "%.0f ms", a.applyDucking),
+		widget.NewLabel("释放 (Release)"), newSettingSlider(&d.ReleaseMs, 1, 5000, 1, "%.0f ms", a.applyDucking),
+	)
+}
+
+// applyDucking hands the ducking settings to the mixer; call it again
+// whenever inputs are attached or detached
+func (a *App) applyDucking() {
+	if a.mixer != nil {
+		a.mixer.SetDucking(a.mixerDucking())
+	}
+}
+
+// mixerDucking converts the ducking settings for the mixer
+func (a *App) mixerDucking() audio.DuckingConfig {
+	d := a.cfg.Ducking
+	trigger := -1
+	if d.TriggerInput >= 1 && d.TriggerInput <= len(a.inputRows) {
+		trigger = a.inputRows[d.TriggerInput-1].mixerIndex
+	}
+	return d.Mixer(trigger)
+}
+
+// showSettingsDialog shows a processor's settings as a form of label and
+// slider pairs below an enable switch. The switch updates enabled and
+// highlights button; apply follows every change.
+func (a *App) showSettingsDialog(title string, enabled *bool, button *widget.Button, apply func(), rows ...fyne.CanvasObject) {
+	check := widget.NewCheck("启用 (Enabled)", func(on bool) {
+		*enabled = on
+		button.Importance = toggleImportance(on)
+		button.Refresh()
+		apply()
+	})
+	check.SetChecked(*enabled)
+
+	form := container.New(layout.NewFormLayout(), append([]fyne.CanvasObject{widget.NewLabel(""), check}, rows...)...)
+	d := dialog.NewCustom(title, "关闭 (Close)", form, a.window)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+// newFrequencySelect creates a choice of the suggested frequencies plus the
+// current value; onChanged follows every change
+func newFrequencySelect(suggested []float64, value *float64, onChanged func()) *widget.Select {
+	values := suggested
+	if !slices.Contains(values, *value) {
+		values = append(slices.Clone(values), *value)
+		slices.Sort(values)
+	}
+	var options []string
+	for _, hz := range values {
+		options = append(options, formatFrequency(hz))
+	}
+	choice := widget.NewSelect(options, nil)
+	choice.SetSelectedIndex(slices.Index(values, *value))
+	choice.OnChanged = func(string) {
+		*value = values[choice.SelectedIndex()]
+		onChanged()
+	}
+	return choice
+}
+
+// newSettingSlider creates a slider editing value within min and max, with
+// a label showing the value through format; onChanged follows every change
+func newSettingSlider(value *float32, min, max, step float64, format string, onChanged func()) fyne.CanvasObject {
+	label := widget.NewLabel(fmt.Sprintf(format, *value))
+	slider := widget.NewSlider(min, max)
+	slider.Step = step
+	slider.Value = float64(*value)
+	slider.OnChanged = func(v float64) {
+		*value = float32(v)
+		label.SetText(fmt.Sprintf(format, v))
+		onChanged()
+	}
+	return container.NewBorder(nil, nil, nil, label, slider)
+}
+
+// formatAGCGain formats the gain an AGC applies for display
+func formatAGCGain(db float32) string {
+	return fmt.Sprintf("AGC %+5.1f dB", db)
+}
+
+// newGateLED creates the indicator showing whether an input's gate is open
+func newGateLED() *canvas.Circle {
+	led := canvas.NewCircle(theme.DisabledColor())
+	led.Hide()
+	return led
+}
+
+// setGateLED shows the gate state on led, hiding it while the gate is off
+func setGateLED(led *canvas.Circle, enabled, open bool) {
+	if !enabled {
+		led.Hide()
+		return
+	}
+	if open {
+		led.FillColor = theme.SuccessColor()
+	} else {
+		led.FillColor = theme.DisabledColor()
+	}
+	led.Show()
+	led.Refresh()
+}
+
+// formatGainReduction formats a gain reduction in dB for display
+func formatGainReduction(db float32) string {
+	return fmt.Sprintf("GR %4.1f dB", -db)
+}
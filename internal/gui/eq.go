@@ -0,0 +1,128 @@
+package gui
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// Frequency range of the equalizer's frequency sliders in Hz
+const (
+	eqMinFrequency = 20
+	eqMaxFrequency = 20000
+)
+
+// showInputEQDialog opens the equalizer of the input at index i; every
+// change is applied to a running mixer right away
+func (a *App) showInputEQDialog(i int) {
+	if a.cfg.Inputs[i].EQ == nil {
+		a.cfg.Inputs[i].EQ = &config.EQConfig{}
+	}
+	eq := a.cfg.Inputs[i].EQ
+	row := a.inputRows[i]
+
+	a.showEQDialog(fmt.Sprintf("Input %d 均衡器 (EQ)", i+1), eq, row.eqButton, func() {
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputEQ(row.mixerIndex, eq.Mixer())
+		}
+	})
+}
+
+// showMasterEQDialog opens the equalizer of the master bus
+func (a *App) showMasterEQDialog() {
+	eq := &a.cfg.MasterEQ
+	a.showEQDialog("主输出均衡器 (Master EQ)", eq, a.masterEQButton, func() {
+		if a.mixer != nil {
+			a.mixer.SetMasterEQ(eq.Mixer())
+		}
+	})
+}
+
+// showEQDialog edits the bands of eq, one row of filter type, frequency,
+// gain and Q per band; apply follows every change
+func (a *App) showEQDialog(title string, eq *config.EQConfig, button *widget.Button, apply func()) {
+	bands := container.NewVBox()
+	addButton := widget.NewButtonWithIcon("添加频段 (Add band)", theme.ContentAddIcon(), nil)
+
+	var rebuild func()
+	rebuild = func() {
+		bands.RemoveAll()
+		for b := range eq.Bands {
+			b := b
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				eq.Bands = append(eq.Bands[:b], eq.Bands[b+1:]...)
+				rebuild()
+				apply()
+			})
+			bands.Add(a.newEQBandEditor(b, &eq.Bands[b], remove, apply))
+		}
+		if len(eq.Bands) >= config.MaxEQBands {
+			addButton.Disable()
+		} else {
+			addButton.Enable()
+		}
+	}
+	addButton.OnTapped = func() {
+		eq.Bands = append(eq.Bands, config.EQBandConfig{Type: config.FilterPeaking, FrequencyHz: 1000, Q: 0.707})
+		rebuild()
+		apply()
+	}
+	rebuild()
+
+	a.showSettingsDialog(title, &eq.Enabled, button, apply,
+		widget.NewLabel("频段 (Bands)"), container.NewVBox(bands, addButton),
+	)
+}
+
+// newEQBandEditor creates the controls of band number b
+func (a *App) newEQBandEditor(b int, band *config.EQBandConfig, remove *widget.Button, apply func()) fyne.CanvasObject {
+	filterType := widget.NewSelect(config.FilterTypes, func(value string) {
+		band.Type = value
+		apply()
+	})
+	filterType.Selected = band.Type
+
+	header := container.NewBorder(nil, nil, widget.NewLabel(fmt.Sprintf("%d", b+1)), remove, filterType)
+	form := container.New(layout.NewFormLayout(),
+		widget.NewLabel("频率 (Frequency)"), newFrequencySlider(&band.FrequencyHz, a.maxEQFrequency(), apply),
+		widget.NewLabel("增益 (Gain)"), newSettingSlider(&band.GainDB, -24, 24, 0.5, "%+.1f dB", apply),
+		widget.NewLabel("Q"), newSettingSlider(&band.Q, 0.1, 18, 0.01, "%.2f", apply),
+	)
+	return container.NewVBox(header, form, widget.NewSeparator())
+}
+
+// maxEQFrequency returns the highest band frequency the sample rate allows
+func (a *App) maxEQFrequency() float64 {
+	return math.Min(eqMaxFrequency, float64(a.cfg.SampleRate)/2-1)
+}
+
+// newFrequencySlider creates a slider editing a frequency on a logarithmic
+// scale from eqMinFrequency to max
+func newFrequencySlider(value *float64, max float64, onChanged func()) fyne.CanvasObject {
+	span := math.Log(max / eqMinFrequency)
+	label := widget.NewLabel(formatFrequency(*value))
+	slider := widget.NewSlider(0, 1)
+	slider.Step = 0.001
+	slider.Value = math.Log(math.Max(*value, eqMinFrequency)/eqMinFrequency) / span
+	slider.OnChanged = func(v float64) {
+		*value = math.Round(eqMinFrequency * math.Exp(v*span))
+		label.SetText(formatFrequency(*value))
+		onChanged()
+	}
+	return container.NewBorder(nil, nil, nil, label, slider)
+}
+
+// formatFrequency formats a frequency in Hz or kHz
+func formatFrequency(hz float64) string {
+	if hz >= 1000 {
+		return fmt.Sprintf("%.2f kHz", hz/1000)
+	}
+	return fmt.Sprintf("%.0f Hz", hz)
+}
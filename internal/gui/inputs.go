@@ -0,0 +1,476 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/entropy/audio-mixer/internal/audio"
+	"github.com/entropy/audio-mixer/internal/config"
+)
+
+// Special entries of the input device selector
+const (
+	optionDefaultInput = "<Default Input>"
+	optionAutoLoopback = "<Auto Detect Loopback>"
+	optionNone         = "<None>"
+)
+
+// gateLEDSize is the diameter of the gate indicator in pixels
+const gateLEDSize = 12
+
+// inputRow holds the widgets of one mixer input
+type inputRow struct {
+	deviceSelect   *widget.Select
+	removeButton   *widget.Button
+	gainLabel      *widget.Label
+	agcLabel       *widget.Label // Gain the AGC applies, empty while it is off
+	gainSlider     *widget.Slider
+	panKnob        *Knob
+	panLabel       *widget.Label
+	muteButton     *widget.Button
+	soloButton     *widget.Button
+	invertButton   *widget.Button
+	filterButton   *widget.Button // Opens the DC blocker and high-pass filter settings, highlighted while the high-pass is on
+	denoiserButton *widget.Button // Opens the noise suppression settings, highlighted while enabled
+	gateButton     *widget.Button // Opens the noise gate settings, highlighted while enabled
+	agcButton      *widget.Button // Opens the AGC settings, highlighted while enabled
+	compButton     *widget.Button // Opens the compressor settings, highlighted while enabled
+	deEsserButton  *widget.Button // Opens the de-esser settings, highlighted while enabled
+	eqButton       *widget.Button // Opens the equalizer, highlighted while enabled
+	chainButton    *widget.Button // Opens the insert chain
+	sendButton     *widget.Button // Opens the aux bus sends, highlighted while sending
+	delayButton    *widget.Button // Opens the delay and alignment, highlighted while delayed
+	meter          *widget.ProgressBar
+	gateLED        *canvas.Circle // Lit while the noise gate is open
+	xrunLabel      *widget.Label
+	compLabel      *widget.Label // Compressor gain reduction
+	deEsserLabel   *widget.Label // De-esser gain reduction
+
+	// Index of this input in the running mixer, -1 if it is not being mixed
+	mixerIndex int
+}
+
+// buildInputOptions returns the device selector entries shared by all inputs
+func (a *App) buildInputOptions() []string {
+	options := []string{optionDefaultInput, optionAutoLoopback, optionNone}
+
+	inputDevices, _ := a.deviceManager.GetInputDevices()
+	for _, dev := range inputDevices {
+		options = append(options, fmt.Sprintf("[%d] %s", dev.Index, dev.Name))
+	}
+	return options
+}
+
+// inputOption returns the selector entry matching an input configuration
+func (a *App) inputOption(input config.InputConfig) string {
+	switch input.DeviceIndex {
+	case config.DeviceIndexDefault:
+		if input.Loopback {
+			return optionAutoLoopback
+		}
+		return optionDefaultInput
+	case config.DeviceIndexDisabled:
+		return optionNone
+	}
+
+	prefix := fmt.Sprintf("[%d] ", input.DeviceIndex)
+	for _, option := range a.inputOptions {
+		if strings.HasPrefix(option, prefix) {
+			return option
+		}
+	}
+	return optionDefaultInput
+}
+
+// applyInputOption stores a selector entry into an input configuration
+func applyInputOption(value string, input *config.InputConfig) {
+	switch value {
+	case "":
+		return
+	case optionDefaultInput:
+		input.DeviceIndex = config.DeviceIndexDefault
+		input.Loopback = false
+	case optionAutoLoopback:
+		input.DeviceIndex = config.DeviceIndexDefault
+		input.Loopback = true
+	case optionNone:
+		input.DeviceIndex = config.DeviceIndexDisabled
+	default:
+		var idx int
+		if _, err := fmt.Sscanf(value, "[%d]", &idx); err == nil {
+			input.DeviceIndex = idx
+		}
+	}
+}
+
+// rebuildInputs recreates the per-input widgets from the configuration,
+// keeping the mixer index of rows that already existed
+func (a *App) rebuildInputs() {
+	previous := a.inputRows
+	a.inputRows = nil
+	a.inputDeviceBox.RemoveAll()
+	a.inputGainBox.RemoveAll()
+	a.inputMeterBox.RemoveAll()
+
+	for i := range a.cfg.Inputs {
+		row := a.newInputRow(i)
+		if i < len(previous) {
+			row.mixerIndex = previous[i].mixerIndex
+		}
+		a.inputRows = append(a.inputRows, row)
+
+		label := fmt.Sprintf("Input %d (%s):", i+1, a.cfg.Inputs[i].Name)
+		a.inputDeviceBox.Add(container.New(layout.NewFormLayout(),
+			widget.NewLabel(label),
+			container.NewBorder(nil, nil, nil, row.removeButton, row.deviceSelect),
+		))
+		a.inputGainBox.Add(container.NewHBox(row.gainLabel, row.agcLabel))
+		a.inputGainBox.Add(container.NewBorder(nil, nil, nil,
+			container.NewHBox(row.panKnob, row.panLabel, row.muteButton, row.soloButton, row.invertButton, row.filterButton, row.denoiserButton, row.gateButton, row.eqButton, row.agcButton, row.compButton, row.deEsserButton, row.chainButton, row.sendButton, row.delayButton),
+			row.gainSlider))
+		a.inputMeterBox.Add(widget.NewLabel(fmt.Sprintf("In%d:", i+1)))
+		a.inputMeterBox.Add(container.NewBorder(nil, nil,
+			container.NewGridWrap(fyne.NewSize(gateLEDSize, gateLEDSize), row.gateLED),
+			container.NewHBox(row.compLabel, row.deEsserLabel, row.xrunLabel),
+			row.meter))
+	}
+
+	a.updateInputButtons()
+}
+
+// newInputRow creates the widgets for the input at index i
+func (a *App) newInputRow(i int) *inputRow {
+	input := &a.cfg.Inputs[i]
+	row := &inputRow{mixerIndex: -1}
+
+	row.deviceSelect = widget.NewSelect(a.inputOptions, nil)
+	row.deviceSelect.SetSelected(a.inputOption(*input))
+	row.deviceSelect.OnChanged = func(value string) {
+		applyInputOption(value, &a.cfg.Inputs[i])
+		if a.isRunning {
+			a.reattachInput(i)
+		}
+	}
+
+	row.removeButton = widget.NewButton("✕", func() {
+		a.removeInput(i)
+	})
+
+	row.gainLabel = widget.NewLabel(fmt.Sprintf("Input %d: %.2f", i+1, input.Gain))
+	row.gainSlider = widget.NewSlider(0, 2.0)
+	row.gainSlider.Value = float64(input.Gain)
+	row.gainSlider.Step = 0.01
+	row.gainSlider.OnChanged = func(value float64) {
+		row.gainLabel.SetText(fmt.Sprintf("Input %d: %.2f", i+1, value))
+		a.cfg.Inputs[i].Gain = float32(value)
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputGain(row.mixerIndex, float32(value))
+		}
+	}
+
+	row.panLabel = widget.NewLabel(formatPan(input.Pan))
+	row.panKnob = NewKnob(-1, 1, 0)
+	row.panKnob.Step = 0.01
+	row.panKnob.Value = float64(input.Pan)
+	row.panKnob.OnChanged = func(value float64) {
+		row.panLabel.SetText(formatPan(float32(value)))
+		a.cfg.Inputs[i].Pan = float32(value)
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputPan(row.mixerIndex, float32(value))
+		}
+	}
+
+	row.muteButton = newToggleButton("M", input.Mute, func(on bool) {
+		a.cfg.Inputs[i].Mute = on
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputMute(row.mixerIndex, on)
+		}
+	})
+	row.soloButton = newToggleButton("S", input.Solo, func(on bool) {
+		a.cfg.Inputs[i].Solo = on
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputSolo(row.mixerIndex, on)
+		}
+	})
+	row.invertButton = newToggleButton("Ø", input.PolarityInvert, func(on bool) {
+		a.cfg.Inputs[i].PolarityInvert = on
+		if a.isRunning && a.mixer != nil && row.mixerIndex >= 0 {
+			a.mixer.SetInputPolarityInvert(row.mixerIndex, on)
+		}
+	})
+
+	row.filterButton = widget.NewButton("HPF", func() {
+		a.showFilterDialog(i)
+	})
+	row.filterButton.Importance = toggleImportance(input.Filter != nil && input.Filter.HighPass)
+
+	row.denoiserButton = widget.NewButton("NR", func() {
+		a.showDenoiserDialog(i)
+	})
+	row.denoiserButton.Importance = toggleImportance(input.Denoiser != nil && input.Denoiser.Enabled)
+
+	row.gateButton = widget.NewButton("G", func() {
+		a.showGateDialog(i)
+	})
+	row.gateButton.Importance = toggleImportance(input.Gate != nil && input.Gate.Enabled)
+
+	row.agcLabel = widget.NewLabel("")
+	row.agcButton = widget.NewButton("AGC", func() {
+		a.showAGCDialog(i)
+	})
+	row.agcButton.Importance = toggleImportance(input.AGC != nil && input.AGC.Enabled)
+
+	row.compButton = widget.NewButton("C", func() {
+		a.showCompressorDialog(i)
+	})
+	row.compButton.Importance = toggleImportance(input.Compressor != nil && input.Compressor.Enabled)
+
+	row.deEsserButton = widget.NewButton("DS", func() {
+		a.showDeEsserDialog(i)
+	})
+	row.deEsserButton.Importance = toggleImportance(input.DeEsser != nil && input.DeEsser.Enabled)
+
+	row.eqButton = widget.NewButton("EQ", func() {
+		a.showInputEQDialog(i)
+	})
+	row.eqButton.Importance = toggleImportance(input.EQ != nil && input.EQ.Enabled)
+
+	row.chainButton = widget.NewButton("FX", func() {
+		a.showInputChainDialog(i)
+	})
+
+	row.sendButton = widget.NewButton("AUX", func() {
+		a.showSendDialog(i)
+	})
+	row.sendButton.Importance = toggleImportance(hasSends(input))
+
+	row.delayButton = widget.NewButton("DLY", func() {
+		a.showDelayDialog(i)
+	})
+	row.delayButton.Importance = toggleImportance(input.DelayMs > 0)
+
+	row.meter = widget.NewProgressBar()
+	row.gateLED = newGateLED()
+	row.xrunLabel = widget.NewLabel(formatXruns(audio.XrunStats{}))
+	row.compLabel = widget.NewLabel("")
+	row.deEsserLabel = widget.NewLabel("")
+
+	return row
+}
+
+// addInput appends a new input using the default device; on a running
+// mixer the input is attached right away
+func (a *App) addInput() {
+	if len(a.cfg.Inputs) >= config.MaxInputs {
+		return
+	}
+
+	a.cfg.Inputs = append(a.cfg.Inputs, config.InputConfig{
+		Name:        fmt.Sprintf("Input %d", len(a.cfg.Inputs)+1),
+		DeviceIndex: config.DeviceIndexDefault,
+		Gain:        1.0,
+	})
+	a.rebuildInputs()
+
+	if a.isRunning {
+		a.attachInput(len(a.cfg.Inputs) - 1)
+	}
+}
+
+// removeInput deletes the input at index i; on a running mixer the input
+// is detached without interrupting the others
+func (a *App) removeInput(i int) {
+	if i < 0 || i >= len(a.cfg.Inputs) {
+		return
+	}
+
+	if a.isRunning {
+		if err := a.detachInput(i); err != nil {
+			a.statusLabel.SetText(fmt.Sprintf("Error removing Input %d: %v", i+1, err))
+			return
+		}
+	}
+
+	a.inputRows = append(a.inputRows[:i], a.inputRows[i+1:]...)
+	a.cfg.Inputs = append(a.cfg.Inputs[:i], a.cfg.Inputs[i+1:]...)
+	if a.cfg.Ducking.TriggerInput > i+1 {
+		a.cfg.Ducking.TriggerInput--
+	}
+	a.rebuildInputs()
+	a.applyDucking()
+}
+
+// attachInput adds the input at index i to the running mixer
+func (a *App) attachInput(i int) {
+	if a.mixer == nil {
+		return
+	}
+
+	dev, err := a.resolveInputDevice(a.cfg.Inputs[i])
+	if err != nil {
+		a.statusLabel.SetText(fmt.Sprintf("Error getting Input %d: %v", i+1, err))
+		return
+	}
+	if dev == nil {
+		return
+	}
+
+	idx, err := a.mixer.AddInput(a.mixerInput(a.cfg.Inputs[i], dev))
+	if err != nil {
+		a.statusLabel.SetText(fmt.Sprintf("Error adding Input %d: %v", i+1, err))
+		return
+	}
+	a.inputRows[i].mixerIndex = idx
+	a.applyDucking()
+	a.statusLabel.SetText(fmt.Sprintf("Input %d 已连接: %s", i+1, dev.Name))
+}
+
+// detachInput removes the input at index i from the running mixer
+func (a *App) detachInput(i int) error {
+	idx := a.inputRows[i].mixerIndex
+	if a.mixer == nil || idx < 0 {
+		return nil
+	}
+
+	if err := a.mixer.RemoveInput(idx); err != nil {
+		return err
+	}
+
+	// Later mixer inputs shifted down by one
+	a.inputRows[i].mixerIndex = -1
+	for _, row := range a.inputRows {
+		if row.mixerIndex > idx {
+			row.mixerIndex--
+		}
+	}
+	return nil
+}
+
+// reattachInput swaps the device of a running input
+func (a *App) reattachInput(i int) {
+	if err := a.detachInput(i); err != nil {
+		a.statusLabel.SetText(fmt.Sprintf("Error removing Input %d: %v", i+1, err))
+		return
+	}
+	a.attachInput(i)
+}
+
+// updateInputButtons enables or disables add/remove buttons
+func (a *App) updateInputButtons() {
+	if a.addInputButton != nil {
+		if len(a.cfg.Inputs) >= config.MaxInputs {
+			a.addInputButton.Disable()
+		} else {
+			a.addInputButton.Enable()
+		}
+	}
+
+	for _, row := range a.inputRows {
+		if len(a.inputRows) <= 1 {
+			row.removeButton.Disable()
+		} else {
+			row.removeButton.Enable()
+		}
+	}
+}
+
+// resolveInputDevice returns the device for an input, or nil if the input is disabled
+func (a *App) resolveInputDevice(input config.InputConfig) (*audio.DeviceInfo, error) {
+	switch {
+	case input.DeviceIndex >= 0:
+		return a.deviceManager.GetDeviceByIndex(input.DeviceIndex)
+	case input.DeviceIndex == config.DeviceIndexDefault && input.Loopback:
+		// Auto-detect loopback device for system audio
+		loopback, err := audio.FindLoopbackDevice(a.deviceManager)
+		if err != nil {
+			return nil, fmt.Errorf("未找到虚拟设备! 请安装 BlackHole: %w", err)
+		}
+		return loopback.Device, nil
+	case input.DeviceIndex == config.DeviceIndexDefault:
+		// Use default input device; a missing one just leaves the input silent
+		dev, err := a.deviceManager.GetDefaultInputDevice()
+		if err != nil {
+			return nil, nil
+		}
+		return dev, nil
+	default:
+		return nil, nil
+	}
+}
+
+// mixerInput converts an input configuration with its resolved device for the mixer
+func (a *App) mixerInput(input config.InputConfig, dev *audio.DeviceInfo) audio.InputConfig {
+	mixerInput := audio.InputConfig{
+		Name:   input.Name,
+		Device: dev,
+		Gain:   input.Gain,
+		Pan:    input.Pan,
+
+		Mute:           input.Mute,
+		Solo:           input.Solo,
+		PolarityInvert: input.PolarityInvert,
+
+		Filter:     input.Filter.Mixer(),
+		Denoiser:   input.Denoiser.Mixer(),
+		Gate:       input.Gate.Mixer(),
+		EQ:         input.EQ.Mixer(),
+		AGC:        input.AGC.Mixer(),
+		Compressor: input.Compressor.Mixer(),
+		DeEsser:    input.DeEsser.Mixer(),
+		Inserts:    config.MixerInserts(input.Inserts),
+		Sends:      input.Sends,
+		Delay:      input.Delay(),
+	}
+	if channels := input.DeviceChannels(); channels != nil {
+		mixerInput.ChannelMap = audio.CaptureChannelMap(channels, a.cfg.Channels)
+	}
+	return mixerInput
+}
+
+// newToggleButton creates a button that switches between on and off,
+// highlighted while on, and reports every switch to onToggled
+func newToggleButton(label string, on bool, onToggled func(on bool)) *widget.Button {
+	button := widget.NewButton(label, nil)
+	update := func() {
+		button.Importance = toggleImportance(on)
+		button.Refresh()
+	}
+	button.OnTapped = func() {
+		on = !on
+		update()
+		onToggled(on)
+	}
+	update()
+	return button
+}
+
+// toggleImportance returns the button importance showing a switch state
+func toggleImportance(on bool) widget.Importance {
+	if on {
+		return widget.HighImportance
+	}
+	return widget.MediumImportance
+}
+
+// formatPan formats a pan position as L/C/R with a percentage
+func formatPan(pan float32) string {
+	switch {
+	case pan < -0.005:
+		return fmt.Sprintf("L%3.0f", -pan*100)
+	case pan > 0.005:
+		return fmt.Sprintf("R%3.0f", pan*100)
+	default:
+		return "  C "
+	}
+}
+
+// formatXruns formats an input's underrun and overrun counts for display
+func formatXruns(xruns audio.XrunStats) string {
+	return fmt.Sprintf("欠载 %d / 溢出 %d", xruns.Underruns, xruns.Overruns)
+}
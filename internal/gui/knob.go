@@ -0,0 +1,147 @@
+package gui
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	knobSize     = 36             // Minimum diameter in pixels
+	knobSweep    = 0.75 * math.Pi // Indicator angle either side of the top at Min and Max
+	knobDragSpan = float32(150)   // Drag distance in pixels for the full range
+)
+
+// Knob is a rotary control for a value between Min and Max. Drag up or to
+// the right (or scroll) to turn it clockwise; double-tap resets it to Default.
+type Knob struct {
+	widget.BaseWidget
+
+	Min, Max float64
+	Default  float64
+	Step     float64 // Values are rounded to multiples of Step, 0 disables rounding
+	Value    float64
+
+	OnChanged func(float64)
+}
+
+// NewKnob creates a knob for the range min to max, starting at def
+func NewKnob(min, max, def float64) *Knob {
+	k := &Knob{Min: min, Max: max, Default: def, Value: def}
+	k.ExtendBaseWidget(k)
+	return k
+}
+
+// SetValue moves the knob and notifies OnChanged if the value changed
+func (k *Knob) SetValue(value float64) {
+	value = math.Max(k.Min, math.Min(value, k.Max))
+	if k.Step > 0 {
+		value = math.Round(value/k.Step) * k.Step
+	}
+	if value == k.Value {
+		return
+	}
+
+	k.Value = value
+	k.Refresh()
+	if k.OnChanged != nil {
+		k.OnChanged(value)
+	}
+}
+
+// Dragged turns the knob by the drag distance
+func (k *Knob) Dragged(e *fyne.DragEvent) {
+	delta := (e.Dragged.DX - e.Dragged.DY) / knobDragSpan
+	k.SetValue(k.Value + float64(delta)*(k.Max-k.Min))
+}
+
+// DragEnd implements fyne.Draggable
+func (k *Knob) DragEnd() {}
+
+// Scrolled turns the knob by one step per scroll notch
+func (k *Knob) Scrolled(e *fyne.ScrollEvent) {
+	step := k.Step
+	if step <= 0 {
+		step = (k.Max - k.Min) / 100
+	}
+	if e.Scrolled.DY > 0 {
+		k.SetValue(k.Value + step)
+	} else if e.Scrolled.DY < 0 {
+		k.SetValue(k.Value - step)
+	}
+}
+
+// DoubleTapped resets the knob to its default value
+func (k *Knob) DoubleTapped(*fyne.PointEvent) {
+	k.SetValue(k.Default)
+}
+
+// CreateRenderer implements fyne.Widget
+func (k *Knob) CreateRenderer() fyne.WidgetRenderer {
+	r := &knobRenderer{
+		knob:      k,
+		face:      canvas.NewCircle(theme.InputBackgroundColor()),
+		indicator: canvas.NewLine(theme.PrimaryColor()),
+	}
+	r.face.StrokeColor = theme.ForegroundColor()
+	r.face.StrokeWidth = 1
+	r.indicator.StrokeWidth = 3
+	return r
+}
+
+// knobRenderer draws a Knob as a circle with an indicator line
+type knobRenderer struct {
+	knob      *Knob
+	face      *canvas.Circle
+	indicator *canvas.Line
+}
+
+// Layout places the face and indicator for the knob size and value
+func (r *knobRenderer) Layout(size fyne.Size) {
+	diameter := fyne.Min(size.Width, size.Height)
+	offset := fyne.NewPos((size.Width-diameter)/2, (size.Height-diameter)/2)
+	r.face.Move(offset)
+	r.face.Resize(fyne.NewSize(diameter, diameter))
+
+	// Map the value onto an arc centred on the top of the knob
+	k := r.knob
+	fraction := 0.5
+	if k.Max > k.Min {
+		fraction = (k.Value - k.Min) / (k.Max - k.Min)
+	}
+	angle := -knobSweep + fraction*2*knobSweep
+
+	radius := diameter / 2
+	centre := offset.Add(fyne.NewPos(radius, radius))
+	tip := fyne.NewPos(
+		centre.X+float32(math.Sin(angle))*radius*0.8,
+		centre.Y-float32(math.Cos(angle))*radius*0.8,
+	)
+	r.indicator.Position1 = centre
+	r.indicator.Position2 = tip
+}
+
+// MinSize returns the smallest usable knob size
+func (r *knobRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(knobSize, knobSize)
+}
+
+// Refresh redraws the knob after a value or theme change
+func (r *knobRenderer) Refresh() {
+	r.face.FillColor = theme.InputBackgroundColor()
+	r.face.StrokeColor = theme.ForegroundColor()
+	r.indicator.StrokeColor = theme.PrimaryColor()
+	r.Layout(r.knob.Size())
+	canvas.Refresh(r.knob)
+}
+
+// Objects returns the canvas objects of the knob
+func (r *knobRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.face, r.indicator}
+}
+
+// Destroy implements fyne.WidgetRenderer
+func (r *knobRenderer) Destroy() {}
@@ -84,15 +84,28 @@ func main() {
 
 	fmt.Println("\n=== Device Configuration ===")
 
-	// Select Input 1 (Microphone)
-	fmt.Printf("\nSelect Input 1 device (Microphone) [current: %d, -1 for default]: ", cfg.Input1DeviceIndex)
-	input1Idx := readInt(reader, cfg.Input1DeviceIndex)
-	cfg.Input1DeviceIndex = input1Idx
+	// Number of inputs
+	fmt.Printf("\nNumber of inputs (1-%d) [current: %d]: ", config.MaxInputs, len(cfg.Inputs))
+	numInputs := readInt(reader, len(cfg.Inputs))
+	if numInputs < 1 || numInputs > config.MaxInputs {
+		fmt.Printf("Value out of range, using default: %d\n", len(cfg.Inputs))
+		numInputs = len(cfg.Inputs)
+	}
+	for len(cfg.Inputs) < numInputs {
+		cfg.Inputs = append(cfg.Inputs, config.InputConfig{
+			Name:        fmt.Sprintf("Input %d", len(cfg.Inputs)+1),
+			DeviceIndex: config.DeviceIndexDefault,
+			Gain:        1.0,
+		})
+	}
+	cfg.Inputs = cfg.Inputs[:numInputs]
 
-	// Select Input 2 (Application Audio)
-	fmt.Printf("Select Input 2 device (Application Audio) [current: %d, -1 for default, -2 to skip]: ", cfg.Input2DeviceIndex)
-	input2Idx := readInt(reader, cfg.Input2DeviceIndex)
-	cfg.Input2DeviceIndex = input2Idx
+	// Select input devices
+	for i := range cfg.Inputs {
+		input := &cfg.Inputs[i]
+		fmt.Printf("Select Input %d device (%s) [current: %d, -1 for default, -2 to skip]: ", i+1, input.Name, input.DeviceIndex)
+		input.DeviceIndex = readInt(reader, input.DeviceIndex)
+	}
 
 	// Select Output
 	fmt.Printf("Select Output device [current: %d, -1 for default]: ", cfg.OutputDeviceIndex)
@@ -101,13 +114,18 @@ func main() {
 
 	// Volume settings
 	fmt.Println("\n=== Volume Configuration (0.0 - 2.0) ===")
-	fmt.Printf("Input 1 Gain [current: %.2f]: ", cfg.Input1Gain)
-	input1Gain := readFloat32(reader, cfg.Input1Gain)
-	cfg.Input1Gain = input1Gain
+	for i := range cfg.Inputs {
+		input := &cfg.Inputs[i]
+		fmt.Printf("Input %d Gain (%s) [current: %.2f]: ", i+1, input.Name, input.Gain)
+		input.Gain = readFloat32(reader, input.Gain)
+	}
 
-	fmt.Printf("Input 2 Gain [current: %.2f]: ", cfg.Input2Gain)
-	input2Gain := readFloat32(reader, cfg.Input2Gain)
-	cfg.Input2Gain = input2Gain
+	fmt.Println("\n=== Pan Configuration (-1.0 left - 1.0 right) ===")
+	for i := range cfg.Inputs {
+		input := &cfg.Inputs[i]
+		fmt.Printf("Input %d Pan (%s) [current: %.2f]: ", i+1, input.Name, input.Pan)
+		input.Pan = readFloat32Range(reader, input.Pan, -1.0, 1.0)
+	}
 
 	fmt.Printf("Master Gain [current: %.2f]: ", cfg.MasterGain)
 	masterGain := readFloat32(reader, cfg.MasterGain)
@@ -123,41 +141,67 @@ func main() {
 	mixerConfig.SampleRate = cfg.SampleRate
 	mixerConfig.BufferSize = cfg.BufferSize
 	mixerConfig.Channels = cfg.Channels
-	mixerConfig.Input1Gain = cfg.Input1Gain
-	mixerConfig.Input2Gain = cfg.Input2Gain
 	mixerConfig.MasterGain = cfg.MasterGain
+	mixerConfig.DriftCompensation = cfg.DriftCompensation
+	mixerConfig.GainRampTime = time.Duration(cfg.GainSmoothingMs) * time.Millisecond
+	mixerConfig.Limiter = audio.LimiterConfig{
+		Enabled:   cfg.Limiter.Enabled,
+		Ceiling:   cfg.Limiter.CeilingDB,
+		Release:   cfg.Limiter.Release(),
+		LookAhead: cfg.Limiter.LookAhead(),
+	}
+
+	panLaw, err := audio.ParsePanLaw(cfg.PanLaw)
+	if err != nil {
+		fmt.Printf("Error in config: %v\n", err)
+		os.Exit(1)
+	}
+	mixerConfig.PanLaw = panLaw
 
-	// Get device info
-	if cfg.Input1DeviceIndex >= 0 {
-		dev, err := deviceManager.GetDeviceByIndex(cfg.Input1DeviceIndex)
+	// Get device info; configIndex maps mixer inputs back to cfg.Inputs
+	var configIndex []int
+	for i, input := range cfg.Inputs {
+		dev, err := resolveInputDevice(deviceManager, input)
 		if err != nil {
-			fmt.Printf("Error getting input1 device: %v\n", err)
+			fmt.Printf("Error getting input %d device: %v\n", i+1, err)
 			os.Exit(1)
 		}
-		mixerConfig.Input1Device = dev
-	} else if cfg.Input1DeviceIndex == -1 {
-		dev, err := deviceManager.GetDefaultInputDevice()
-		if err != nil {
-			fmt.Printf("Error getting default input device: %v\n", err)
-			os.Exit(1)
+		if dev == nil {
+			continue
 		}
-		mixerConfig.Input1Device = dev
-	}
+		mixerInput := audio.InputConfig{
+			Name:   input.Name,
+			Device: dev,
+			Gain:   input.Gain,
+			Pan:    input.Pan,
 
-	if cfg.Input2DeviceIndex >= 0 {
-		dev, err := deviceManager.GetDeviceByIndex(cfg.Input2DeviceIndex)
-		if err != nil {
-			fmt.Printf("Error getting input2 device: %v\n", err)
-			os.Exit(1)
+			Mute:           input.Mute,
+			Solo:           input.Solo,
+			PolarityInvert: input.PolarityInvert,
+
+			Filter:     input.Filter.Mixer(),
+			Denoiser:   input.Denoiser.Mixer(),
+			Gate:       input.Gate.Mixer(),
+			EQ:         input.EQ.Mixer(),
+			AGC:        input.AGC.Mixer(),
+			Compressor: input.Compressor.Mixer(),
+			DeEsser:    input.DeEsser.Mixer(),
+			Inserts:    config.MixerInserts(input.Inserts),
+			Sends:      input.Sends,
+			Delay:      input.Delay(),
 		}
-		mixerConfig.Input2Device = dev
-	} else if cfg.Input2DeviceIndex == -1 {
-		dev, err := deviceManager.GetDefaultInputDevice()
-		if err != nil {
-			fmt.Printf("Warning: Could not get default input device for input2: %v\n", err)
-		} else {
-			mixerConfig.Input2Device = dev
+		if channels := input.DeviceChannels(); channels != nil {
+			mixerInput.ChannelMap = audio.CaptureChannelMap(channels, mixerConfig.Channels)
 		}
+		mixerConfig.Inputs = append(mixerConfig.Inputs, mixerInput)
+		configIndex = append(configIndex, i)
+	}
+	mixerConfig.Ducking = mixerDucking(cfg.Ducking, configIndex)
+	mixerConfig.MasterEQ = cfg.MasterEQ.Mixer()
+	mixerConfig.MasterInserts = config.MixerInserts(cfg.MasterInserts)
+	mixerConfig.AuxBuses = config.MixerAuxBuses(cfg.AuxBuses)
+	if channels := cfg.OutputDeviceChannels(); channels != nil {
+		mixerConfig.OutputChannelMap = audio.PlaybackChannelMap(mixerConfig.Channels, channels)
 	}
 
 	if cfg.OutputDeviceIndex >= 0 {
@@ -183,6 +227,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Report lifecycle changes; a failure ends the session
+	failedCh := make(chan error, 1)
+	mixer.OnStateChange(func(change audio.StateChange) {
+		fmt.Printf("\nMixer state: %s -> %s\n", change.From, change.To)
+		if change.To == audio.StateFailed {
+			select {
+			case failedCh <- change.Err:
+			default:
+			}
+		}
+	})
+
 	// Start mixer
 	fmt.Println("\n=== Starting Audio Mixer ===")
 	if err := mixer.Start(); err != nil {
@@ -191,7 +247,20 @@ func main() {
 	}
 
 	fmt.Println("Mixer started successfully!")
-	fmt.Println("\nPress Ctrl+C to stop")
+	fmt.Printf("Audio backend: %s\n", mixer.BackendName())
+	for i := 0; i < mixer.NumInputs(); i++ {
+		fmt.Printf("Input %d (%s): %s\n", i+1, mixer.GetInputName(i), mixer.GetInputStreamInfo(i))
+	}
+	fmt.Printf("Output: %s\n", mixer.GetOutputStreamInfo())
+	for b := 0; b < mixer.NumAuxBuses(); b++ {
+		fmt.Printf("Aux %d (%s): return %.2f\n", b+1, mixer.GetAuxBusName(b), mixer.GetAuxReturn(b))
+	}
+	if cfg.Limiter.Enabled {
+		fmt.Printf("Limiter: ceiling %.1f dBFS, release %v, look-ahead %v\n",
+			cfg.Limiter.CeilingDB, cfg.Limiter.Release(), cfg.Limiter.LookAhead())
+	}
+	fmt.Println("\nPress Ctrl+C to stop, send SIGHUP to restart")
+	fmt.Println(commandHelp)
 	fmt.Println("\nReal-time Monitoring:")
 	fmt.Println("---------------------")
 
@@ -199,6 +268,10 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
+	// SIGHUP restarts the mixer with the same devices
+	restartCh := make(chan os.Signal, 1)
+	signal.Notify(restartCh, syscall.SIGHUP)
+
 	// Monitoring goroutine
 	stopMonitor := make(chan struct{})
 	go func() {
@@ -208,21 +281,67 @@ func main() {
 		for {
 			select {
 			case <-ticker.C:
-				input1Level := mixer.GetInput1Level()
-				input2Level := mixer.GetInput2Level()
-				outputLevel := mixer.GetOutputLevel()
-				latency := mixer.GetLatency()
+				var line strings.Builder
+				barWidth := 20
+				if mixer.NumInputs() > 2 {
+					barWidth = 10
+				}
 
 				// Convert to dB for display
-				input1DB := levelToDB(input1Level)
-				input2DB := levelToDB(input2Level)
-				outputDB := levelToDB(outputLevel)
+				for i := 0; i < mixer.NumInputs(); i++ {
+					level := mixer.GetInputLevel(i)
+					xruns := mixer.GetInputXruns(i)
+					fmt.Fprintf(&line, "[Input%d: %6.1f dB %s U:%d O:%d", i+1, levelToDB(level), getLevelBar(level, barWidth),
+						xruns.Underruns, xruns.Overruns)
+					if mixer.GetInputDenoiser(i).Enabled {
+						nr := "on"
+						if mixer.IsInputLearningNoise(i) {
+							nr = "learning"
+						} else if mixer.GetInputDenoiser(i).Bypass {
+							nr = "bypass"
+						}
+						fmt.Fprintf(&line, " NR:%s", nr)
+					}
+					if delay := mixer.GetInputDelay(i); delay > 0 {
+						fmt.Fprintf(&line, " Delay:%v", delay.Round(time.Millisecond))
+					}
+					if mixer.GetInputGate(i).Enabled {
+						gate := "closed"
+						if mixer.IsInputGateOpen(i) {
+							gate = "open"
+						}
+						fmt.Fprintf(&line, " Gate:%s", gate)
+					}
+					if mixer.GetInputAGC(i).Enabled {
+						fmt.Fprintf(&line, " AGC:%+5.1f", mixer.GetInputAGCGain(i))
+					}
+					if mixer.GetInputCompressor(i).Enabled {
+						fmt.Fprintf(&line, " GR:%4.1f", mixer.GetInputCompressorGainReduction(i))
+					}
+					if deEsser := mixer.GetInputDeEsser(i); deEsser.Enabled {
+						if deEsser.Listen {
+							line.WriteString(" DS:listen")
+						} else {
+							fmt.Fprintf(&line, " DS:%4.1f", mixer.GetInputDeEsserGainReduction(i))
+						}
+					}
+					line.WriteString("] ")
+				}
+
+				if mixer.GetDucking().Enabled {
+					fmt.Fprintf(&line, "[Duck: %4.1f dB] ", mixer.GetDuckingGainReduction())
+				}
+
+				outputLevel := mixer.GetOutputLevel()
+				latency := mixer.GetLatency()
+				fmt.Fprintf(&line, "[Output: %6.1f dB %s] ",
+					levelToDB(outputLevel), getLevelBar(outputLevel, 20))
+				if cfg.Limiter.Enabled {
+					fmt.Fprintf(&line, "[GR: %4.1f dB] ", mixer.GetLimiterGainReduction())
+				}
+				fmt.Fprintf(&line, "[Latency: %v]", latency.Round(time.Microsecond))
 
-				fmt.Printf("\r[Input1: %6.1f dB %s] [Input2: %6.1f dB %s] [Output: %6.1f dB %s] [Latency: %v]",
-					input1DB, getLevelBar(input1Level, 20),
-					input2DB, getLevelBar(input2Level, 20),
-					outputDB, getLevelBar(outputLevel, 20),
-					latency.Round(time.Microsecond))
+				fmt.Printf("\r%s", line.String())
 
 			case <-stopMonitor:
 				return
@@ -230,8 +349,46 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal
-	<-sigCh
+	// Commands typed while the mixer runs
+	commandCh := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			commandCh <- line
+		}
+	}()
+
+	// Wait for interrupt signal or a failure, restarting on request
+	running := true
+	for running {
+		select {
+		case line := <-commandCh:
+			result, err := handleCommand(line, mixer, cfg, configIndex)
+			if err != nil {
+				fmt.Printf("\n%v\n", err)
+				continue
+			}
+			if result == "" {
+				continue
+			}
+			fmt.Printf("\n%s\n", result)
+			if err := configManager.Save(cfg); err != nil {
+				fmt.Printf("Warning: Failed to save config: %v\n", err)
+			}
+		case <-restartCh:
+			if err := mixer.Restart(); err != nil {
+				fmt.Printf("Error restarting mixer: %v\n", err)
+			}
+		case err := <-failedCh:
+			fmt.Printf("Mixer failed: %v\n", err)
+			running = false
+		case <-sigCh:
+			running = false
+		}
+	}
 
 	fmt.Println("\n\nShutting down...")
 	close(stopMonitor)
@@ -244,6 +401,931 @@ func main() {
 	fmt.Println("Goodbye!")
 }
 
+// commandHelp lists the commands accepted while the mixer runs
+const commandHelp = "Commands: mute|solo|invert|dc|hpf|gate|agc|comp|deess <input> [on|off] (toggles without on/off), " +
+	"hpf <input> cutoff <Hz>, " +
+	"nr <input> [on|off|learn], nr <input> bypass|adaptive [on|off], nr <input> strength <0-1>, " +
+	"gate <input> threshold|hysteresis|hold|attack|release|range <value>, " +
+	"agc <input> target|boost|cut|rate|freeze <value>, agc <input> mode rms|lufs, " +
+	"comp <input> threshold|ratio|knee|attack|release|makeup <value>, " +
+	"deess <input> low|high|threshold|reduction <value>, deess <input> listen on|off, " +
+	"duck [on|off], duck trigger|threshold|depth|attack|hold|release <value>, " +
+	"eq <input|master> [on|off|list], eq <input|master> add <type> <Hz> [dB] [Q], eq <input|master> remove <band>, " +
+	"chain <input|master|aux<n>> [list], chain <input|master|aux<n>> move <from> <to>|bypass <slot> [on|off]|add <type> [slot]|remove <slot>, " +
+	"chain <input|master|aux<n>> set <slot> <param> <value>, " +
+	"send <input> <aux> <level>, aux <aux> [list], aux <aux> return <gain>, " +
+	"delay <input> <value> [ms|samples], align <input> <reference input>, help"
+
+// handleCommand applies a command typed while the mixer runs, such as
+// "mute 2", to the mixer and the configuration
+func handleCommand(line string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	if fields[0] == "help" {
+		fmt.Println()
+		fmt.Println(commandHelp)
+		return "", nil
+	}
+	if fields[0] == "duck" {
+		return handleDuckCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "eq" {
+		return handleEQCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "nr" {
+		return handleNoiseCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "chain" {
+		return handleChainCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "send" {
+		return handleSendCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "aux" {
+		return handleAuxCommand(fields, mixer, cfg)
+	}
+	if fields[0] == "delay" {
+		return handleDelayCommand(fields, mixer, cfg, configIndex)
+	}
+	if fields[0] == "align" {
+		return handleAlignCommand(fields, mixer, cfg, configIndex)
+	}
+	if len(fields) == 4 && (fields[0] == "hpf" || fields[0] == "gate" || fields[0] == "agc" || fields[0] == "comp" || fields[0] == "deess") {
+		return handleSettingCommand(fields, mixer, cfg, configIndex)
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return "", fmt.Errorf("usage: %s <input> [on|off]", fields[0])
+	}
+
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	input := &cfg.Inputs[configIndex[index]]
+
+	var on bool
+	var apply func(on bool)
+	switch fields[0] {
+	case "mute":
+		on = !mixer.IsInputMuted(index)
+		apply = func(on bool) {
+			mixer.SetInputMute(index, on)
+			input.Mute = on
+		}
+	case "solo":
+		on = !mixer.IsInputSoloed(index)
+		apply = func(on bool) {
+			mixer.SetInputSolo(index, on)
+			input.Solo = on
+		}
+	case "invert":
+		on = !mixer.IsInputPolarityInverted(index)
+		apply = func(on bool) {
+			mixer.SetInputPolarityInvert(index, on)
+			input.PolarityInvert = on
+		}
+	case "dc":
+		on = !mixer.GetInputFilter(index).DCBlocker
+		apply = func(on bool) {
+			filter := inputFilter(input)
+			filter.DCBlocker = on
+			mixer.SetInputFilter(index, filter.Mixer())
+		}
+	case "hpf":
+		on = !mixer.GetInputFilter(index).HighPass
+		apply = func(on bool) {
+			filter := inputFilter(input)
+			filter.HighPass = on
+			mixer.SetInputFilter(index, filter.Mixer())
+		}
+	case "gate":
+		on = !mixer.GetInputGate(index).Enabled
+		apply = func(on bool) {
+			gate := inputGate(input)
+			gate.Enabled = on
+			mixer.SetInputGate(index, gate.Mixer())
+		}
+	case "agc":
+		on = !mixer.GetInputAGC(index).Enabled
+		apply = func(on bool) {
+			agc := inputAGC(input)
+			agc.Enabled = on
+			mixer.SetInputAGC(index, agc.Mixer())
+		}
+	case "comp":
+		on = !mixer.GetInputCompressor(index).Enabled
+		apply = func(on bool) {
+			compressor := inputCompressor(input)
+			compressor.Enabled = on
+			mixer.SetInputCompressor(index, compressor.Mixer())
+		}
+	case "deess":
+		on = !mixer.GetInputDeEsser(index).Enabled
+		apply = func(on bool) {
+			deEsser := inputDeEsser(input)
+			deEsser.Enabled = on
+			mixer.SetInputDeEsser(index, deEsser.Mixer())
+		}
+	default:
+		return "", fmt.Errorf("unknown command %q; %s", fields[0], commandHelp)
+	}
+
+	if len(fields) == 3 {
+		switch fields[2] {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		default:
+			return "", fmt.Errorf("expected on or off, got %q", fields[2])
+		}
+	}
+
+	apply(on)
+	state := "off"
+	if on {
+		state = "on"
+	}
+	return fmt.Sprintf("Input %d (%s) %s: %s", n, mixer.GetInputName(index), fields[0], state), nil
+}
+
+// handleSettingCommand sets one high-pass filter, gate, AGC or compressor
+// setting of an input, e.g. "comp 1 threshold -24"
+func handleSettingCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	input := &cfg.Inputs[configIndex[index]]
+	result := fmt.Sprintf("Input %d (%s) %s %s: %s", n, mixer.GetInputName(index), fields[0], fields[2], fields[3])
+
+	// The AGC mode is the only setting that is not a number
+	if fields[0] == "agc" && fields[2] == "mode" {
+		updated := *inputAGC(input)
+		updated.Mode = fields[3]
+		if err := config.ValidateAGC(updated); err != nil {
+			return "", err
+		}
+		*input.AGC = updated
+		mixer.SetInputAGC(index, input.AGC.Mixer())
+		return result, nil
+	}
+
+	// Neither is the de-esser's listen switch
+	if fields[0] == "deess" && fields[2] == "listen" {
+		updated := *inputDeEsser(input)
+		switch fields[3] {
+		case "on":
+			updated.Listen = true
+		case "off":
+			updated.Listen = false
+		default:
+			return "", fmt.Errorf("expected on or off, got %q", fields[3])
+		}
+		*input.DeEsser = updated
+		mixer.SetInputDeEsser(index, input.DeEsser.Mixer())
+		return result, nil
+	}
+
+	parsed, err := strconv.ParseFloat(fields[3], 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q", fields[3])
+	}
+	value := float32(parsed)
+
+	switch fields[0] {
+	case "hpf":
+		updated := *inputFilter(input)
+		if fields[2] != "cutoff" {
+			return "", fmt.Errorf("unknown hpf setting %q", fields[2])
+		}
+		updated.HighPassHz = parsed
+		if err := config.ValidateFilter(updated); err != nil {
+			return "", err
+		}
+		*input.Filter = updated
+		mixer.SetInputFilter(index, input.Filter.Mixer())
+
+	case "gate":
+		updated := *inputGate(input)
+		switch fields[2] {
+		case "threshold":
+			updated.ThresholdDB = value
+		case "hysteresis":
+			updated.HysteresisDB = value
+		case "hold":
+			updated.HoldMs = value
+		case "attack":
+			updated.AttackMs = value
+		case "release":
+			updated.ReleaseMs = value
+		case "range":
+			updated.RangeDB = value
+		default:
+			return "", fmt.Errorf("unknown gate setting %q", fields[2])
+		}
+		if err := config.ValidateGate(updated); err != nil {
+			return "", err
+		}
+		*input.Gate = updated
+		mixer.SetInputGate(index, input.Gate.Mixer())
+
+	case "agc":
+		updated := *inputAGC(input)
+		switch fields[2] {
+		case "target":
+			updated.TargetDB = value
+		case "boost":
+			updated.MaxBoostDB = value
+		case "cut":
+			updated.MaxCutDB = value
+		case "rate":
+			updated.RateDBPerSec = value
+		case "freeze":
+			updated.FreezeDB = value
+		default:
+			return "", fmt.Errorf("unknown AGC setting %q", fields[2])
+		}
+		if err := config.ValidateAGC(updated); err != nil {
+			return "", err
+		}
+		*input.AGC = updated
+		mixer.SetInputAGC(index, input.AGC.Mixer())
+
+	case "comp":
+		updated := *inputCompressor(input)
+		switch fields[2] {
+		case "threshold":
+			updated.ThresholdDB = value
+		case "ratio":
+			updated.Ratio = value
+		case "knee":
+			updated.KneeDB = value
+		case "attack":
+			updated.AttackMs = value
+		case "release":
+			updated.ReleaseMs = value
+		case "makeup":
+			updated.MakeupGainDB = value
+		default:
+			return "", fmt.Errorf("unknown compressor setting %q", fields[2])
+		}
+		if err := config.ValidateCompressor(updated); err != nil {
+			return "", err
+		}
+		*input.Compressor = updated
+		mixer.SetInputCompressor(index, input.Compressor.Mixer())
+
+	case "deess":
+		updated := *inputDeEsser(input)
+		switch fields[2] {
+		case "low":
+			updated.LowHz = parsed
+		case "high":
+			updated.HighHz = parsed
+		case "threshold":
+			updated.ThresholdDB = value
+		case "reduction":
+			updated.ReductionDB = value
+		default:
+			return "", fmt.Errorf("unknown de-esser setting %q", fields[2])
+		}
+		if err := config.ValidateDeEsser(updated); err != nil {
+			return "", err
+		}
+		*input.DeEsser = updated
+		mixer.SetInputDeEsser(index, input.DeEsser.Mixer())
+	}
+
+	return result, nil
+}
+
+// handleDuckCommand switches ducking on or off, e.g. "duck on", or sets
+// one of its settings, e.g. "duck depth 18"
+func handleDuckCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	updated := cfg.Ducking
+	switch len(fields) {
+	case 1:
+		updated.Enabled = !updated.Enabled
+	case 2:
+		switch fields[1] {
+		case "on":
+			updated.Enabled = true
+		case "off":
+			updated.Enabled = false
+		default:
+			return "", fmt.Errorf("expected on or off, got %q", fields[1])
+		}
+	case 3:
+		parsed, err := strconv.ParseFloat(fields[2], 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid value %q", fields[2])
+		}
+		value := float32(parsed)
+		switch fields[1] {
+		case "trigger":
+			updated.TriggerInput = int(value)
+		case "threshold":
+			updated.ThresholdDB = value
+		case "depth":
+			updated.DepthDB = value
+		case "attack":
+			updated.AttackMs = value
+		case "hold":
+			updated.HoldMs = value
+		case "release":
+			updated.ReleaseMs = value
+		default:
+			return "", fmt.Errorf("unknown ducking setting %q", fields[1])
+		}
+	default:
+		return "", fmt.Errorf("usage: duck [on|off] or duck <setting> <value>")
+	}
+	if err := config.ValidateDucking(updated); err != nil {
+		return "", err
+	}
+
+	cfg.Ducking = updated
+	mixer.SetDucking(mixerDucking(updated, configIndex))
+	if len(fields) == 3 {
+		return fmt.Sprintf("Ducking %s: %s", fields[1], fields[2]), nil
+	}
+	state := "off"
+	if updated.Enabled {
+		state = "on"
+	}
+	return fmt.Sprintf("Ducking under input %d: %s", updated.TriggerInput, state), nil
+}
+
+// handleNoiseCommand controls the noise suppression of an input, e.g.
+// "nr 1 learn", "nr 1 bypass on" or "nr 1 strength 0.5"
+func handleNoiseCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) < 2 || len(fields) > 4 {
+		return "", fmt.Errorf("usage: nr <input> [on|off|learn|bypass|adaptive|strength]")
+	}
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	input := &cfg.Inputs[configIndex[index]]
+	name := fmt.Sprintf("Input %d (%s)", n, mixer.GetInputName(index))
+
+	updated := *inputDenoiser(input)
+	action := "toggle"
+	if len(fields) > 2 {
+		action = fields[2]
+	}
+	switch action {
+	case "toggle":
+		updated.Enabled = !updated.Enabled
+	case "on":
+		updated.Enabled = true
+	case "off":
+		updated.Enabled = false
+	case "learn":
+		if !updated.Enabled {
+			return "", fmt.Errorf("%s noise suppression is off", name)
+		}
+		mixer.LearnInputNoise(index)
+		return fmt.Sprintf("%s learning noise for %v, keep quiet", name, audio.DenoiserLearnTime), nil
+	case "bypass", "adaptive":
+		setting := &updated.Bypass
+		if action == "adaptive" {
+			setting = &updated.Adaptive
+		}
+		*setting = !*setting
+		if len(fields) == 4 {
+			switch fields[3] {
+			case "on":
+				*setting = true
+			case "off":
+				*setting = false
+			default:
+				return "", fmt.Errorf("expected on or off, got %q", fields[3])
+			}
+		}
+	case "strength":
+		if len(fields) != 4 {
+			return "", fmt.Errorf("usage: nr <input> strength <0-1>")
+		}
+		strength, err := strconv.ParseFloat(fields[3], 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid value %q", fields[3])
+		}
+		updated.Strength = float32(strength)
+	default:
+		return "", fmt.Errorf("unknown nr action %q", action)
+	}
+	if err := config.ValidateDenoiser(updated); err != nil {
+		return "", err
+	}
+
+	*input.Denoiser = updated
+	mixer.SetInputDenoiser(index, input.Denoiser.Mixer())
+	state := "off"
+	if updated.Enabled {
+		state = fmt.Sprintf("on, strength %.2f, adaptive %v, bypass %v, latency %v", updated.Strength, updated.Adaptive, updated.Bypass,
+			mixer.GetInputDenoiserLatency(index))
+	}
+	return fmt.Sprintf("%s noise suppression: %s", name, state), nil
+}
+
+// handleEQCommand edits the equalizer of an input or the master bus, e.g.
+// "eq 1 add high_pass 80" or "eq master remove 2"
+func handleEQCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("usage: eq <input|master> [on|off|list|add|remove]")
+	}
+
+	// Resolve the target's settings and how to hand them to the mixer
+	var eq *config.EQConfig
+	var apply func()
+	name := "Master"
+	if fields[1] == "master" {
+		eq = &cfg.MasterEQ
+		apply = func() { mixer.SetMasterEQ(eq.Mixer()) }
+	} else {
+		n, index, err := parseInputNumber(fields[1], mixer)
+		if err != nil {
+			return "", err
+		}
+		input := &cfg.Inputs[configIndex[index]]
+		if input.EQ == nil {
+			input.EQ = &config.EQConfig{}
+		}
+		eq = input.EQ
+		apply = func() { mixer.SetInputEQ(index, eq.Mixer()) }
+		name = fmt.Sprintf("Input %d (%s)", n, mixer.GetInputName(index))
+	}
+
+	updated := config.EQConfig{Enabled: eq.Enabled, Bands: append([]config.EQBandConfig(nil), eq.Bands...)}
+	action := "toggle"
+	if len(fields) > 2 {
+		action = fields[2]
+	}
+	switch action {
+	case "toggle":
+		updated.Enabled = !updated.Enabled
+	case "on":
+		updated.Enabled = true
+	case "off":
+		updated.Enabled = false
+	case "list":
+		return formatEQ(name, *eq), nil
+	case "add":
+		band, err := parseEQBand(fields[3:])
+		if err != nil {
+			return "", err
+		}
+		updated.Bands = append(updated.Bands, band)
+		updated.Enabled = true
+	case "remove":
+		if len(fields) != 4 {
+			return "", fmt.Errorf("usage: eq <input|master> remove <band>")
+		}
+		b, err := strconv.Atoi(fields[3])
+		if err != nil || b < 1 || b > len(updated.Bands) {
+			return "", fmt.Errorf("invalid band %q (1-%d)", fields[3], len(updated.Bands))
+		}
+		updated.Bands = append(updated.Bands[:b-1], updated.Bands[b:]...)
+	default:
+		return "", fmt.Errorf("unknown eq action %q", action)
+	}
+	if err := config.ValidateEQ(updated, cfg.SampleRate); err != nil {
+		return "", err
+	}
+
+	*eq = updated
+	apply()
+	return formatEQ(name, *eq), nil
+}
+
+// handleDelayCommand sets how long an input is held back, e.g. "delay 2
+// 120" for 120 ms or "delay 2 5760 samples"
+func handleDelayCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) < 3 || len(fields) > 4 {
+		return "", fmt.Errorf("usage: delay <input> <value> [ms|samples]")
+	}
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	input := &cfg.Inputs[configIndex[index]]
+
+	value, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q", fields[2])
+	}
+	delayMs := value
+	if len(fields) == 4 {
+		switch fields[3] {
+		case "ms":
+		case "samples":
+			delayMs = value / cfg.SampleRate * 1000
+		default:
+			return "", fmt.Errorf("expected ms or samples, got %q", fields[3])
+		}
+	}
+	if delayMs < 0 || delayMs > config.MaxDelayMs {
+		return "", fmt.Errorf("delay must be between 0 and %d ms", config.MaxDelayMs)
+	}
+
+	input.DelayMs = delayMs
+	mixer.SetInputDelay(index, input.Delay())
+	return fmt.Sprintf("Input %d (%s) delay: %s", n, mixer.GetInputName(index), audio.FormatDelay(mixer.GetInputDelay(index), cfg.SampleRate)), nil
+}
+
+// handleAlignCommand lines an input up with a reference input by listening
+// for a clap both pick up, e.g. "align 2 1"
+func handleAlignCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) != 3 {
+		return "", fmt.Errorf("usage: align <input> <reference input>")
+	}
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	r, reference, err := parseInputNumber(fields[2], mixer)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("\nListening to inputs %d and %d for %v, clap once now...\n", n, r, audio.AlignCaptureTime)
+	result, err := mixer.AlignInput(reference, index)
+	if err != nil {
+		return "", fmt.Errorf("alignment failed: %w", err)
+	}
+
+	cfg.Inputs[configIndex[reference]].DelayMs = float64(result.ReferenceDelay) / float64(time.Millisecond)
+	cfg.Inputs[configIndex[index]].DelayMs = float64(result.Delay) / float64(time.Millisecond)
+	relation, offset := "behind", result.Offset
+	if offset < 0 {
+		relation, offset = "ahead of", -offset
+	}
+	return fmt.Sprintf("Input %d is %v %s input %d (confidence %.0f); delays: input %d %s, input %d %s",
+		n, offset.Round(10*time.Microsecond), relation, r, result.Confidence,
+		r, audio.FormatDelay(result.ReferenceDelay, cfg.SampleRate), n, audio.FormatDelay(result.Delay, cfg.SampleRate)), nil
+}
+
+// handleSendCommand sets how much of an input goes to an aux bus, e.g.
+// "send 1 2 0.5"
+func handleSendCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) != 4 {
+		return "", fmt.Errorf("usage: send <input> <aux> <level>")
+	}
+	n, index, err := parseInputNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+	b, bus, err := parseAuxNumber(fields[2], mixer)
+	if err != nil {
+		return "", err
+	}
+	level, err := strconv.ParseFloat(fields[3], 32)
+	if err != nil || level < 0 || level > 2.0 {
+		return "", fmt.Errorf("send level must be between 0.0 and 2.0")
+	}
+
+	input := &cfg.Inputs[configIndex[index]]
+	for len(input.Sends) <= bus {
+		input.Sends = append(input.Sends, 0)
+	}
+	input.Sends[bus] = float32(level)
+	mixer.SetInputSend(index, bus, float32(level))
+	return fmt.Sprintf("Input %d (%s) send to aux %d (%s): %.2f", n, mixer.GetInputName(index), b, mixer.GetAuxBusName(bus), mixer.GetInputSend(index, bus)), nil
+}
+
+// handleAuxCommand lists an aux bus with its sends or sets its return
+// level, e.g. "aux 1 return 0.8"
+func handleAuxCommand(fields []string, mixer *audio.Mixer, cfg *config.Config) (string, error) {
+	if len(fields) != 2 && !(len(fields) == 3 && fields[2] == "list") && !(len(fields) == 4 && fields[2] == "return") {
+		return "", fmt.Errorf("usage: aux <aux> [list], aux <aux> return <gain>")
+	}
+	b, bus, err := parseAuxNumber(fields[1], mixer)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fields) == 4 {
+		gain, err := strconv.ParseFloat(fields[3], 32)
+		if err != nil || gain < 0 || gain > 2.0 {
+			return "", fmt.Errorf("return gain must be between 0.0 and 2.0")
+		}
+		cfg.AuxBuses[bus].ReturnGain = float32(gain)
+		mixer.SetAuxReturn(bus, float32(gain))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Aux %d (%s) return %.2f, sends:", b, mixer.GetAuxBusName(bus), mixer.GetAuxReturn(bus))
+	for i := 0; i < mixer.NumInputs(); i++ {
+		fmt.Fprintf(&sb, " input %d %.2f", i+1, mixer.GetInputSend(i, bus))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(formatChain(fmt.Sprintf("Aux %d", b), mixer.AuxChain(bus)))
+	return sb.String(), nil
+}
+
+// handleChainCommand lists or edits the insert chain of an input, the
+// master bus or an aux bus; slots are numbered from 1
+func handleChainCommand(fields []string, mixer *audio.Mixer, cfg *config.Config, configIndex []int) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("usage: chain <input|master|aux<n>> [list|move|bypass|add|remove|set]")
+	}
+
+	// Resolve the target's chain and where its order is saved
+	var chain *audio.InsertChain
+	var inserts *[]config.InsertConfig
+	name := "Master"
+	if fields[1] == "master" {
+		chain = mixer.MasterChain()
+		inserts = &cfg.MasterInserts
+	} else if number, ok := strings.CutPrefix(fields[1], "aux"); ok {
+		b, bus, err := parseAuxNumber(number, mixer)
+		if err != nil {
+			return "", err
+		}
+		chain = mixer.AuxChain(bus)
+		inserts = &cfg.AuxBuses[bus].Inserts
+		name = fmt.Sprintf("Aux %d (%s)", b, mixer.GetAuxBusName(bus))
+	} else {
+		n, index, err := parseInputNumber(fields[1], mixer)
+		if err != nil {
+			return "", err
+		}
+		chain = mixer.InputChain(index)
+		inserts = &cfg.Inputs[configIndex[index]].Inserts
+		name = fmt.Sprintf("Input %d (%s)", n, mixer.GetInputName(index))
+	}
+
+	action := "list"
+	if len(fields) > 2 {
+		action = fields[2]
+	}
+	args := fields[min(len(fields), 3):]
+
+	// slot parses the 1-based slot number at args[i]; add accepts one past
+	// the end
+	slot := func(i, extra int) (int, error) {
+		if i >= len(args) {
+			return 0, fmt.Errorf("usage: chain <input|master|aux<n>> %s <slot>", action)
+		}
+		s, err := strconv.Atoi(args[i])
+		if err != nil || s < 1 || s > chain.Len()+extra {
+			return 0, fmt.Errorf("invalid slot %q (1-%d)", args[i], chain.Len()+extra)
+		}
+		return s - 1, nil
+	}
+
+	var err error
+	switch action {
+	case "list":
+		return formatChain(name, chain), nil
+	case "move":
+		var from, to int
+		if from, err = slot(0, 0); err == nil {
+			if to, err = slot(1, 0); err == nil {
+				err = chain.Move(from, to)
+			}
+		}
+	case "bypass":
+		var position int
+		if position, err = slot(0, 0); err != nil {
+			break
+		}
+		bypass := !chain.Slots()[position].Bypass
+		if len(args) > 1 {
+			switch args[1] {
+			case "on":
+				bypass = true
+			case "off":
+				bypass = false
+			default:
+				return "", fmt.Errorf("expected on or off, got %q", args[1])
+			}
+		}
+		err = chain.SetBypass(position, bypass)
+	case "add":
+		if len(args) < 1 || len(args) > 2 {
+			return "", fmt.Errorf("usage: chain <input|master|aux<n>> add <type> [slot] (types: %s)", strings.Join(audio.ProcessorTypes(), ", "))
+		}
+		position := chain.Len()
+		if len(args) == 2 {
+			if position, err = slot(1, 1); err != nil {
+				break
+			}
+		}
+		var p audio.Processor
+		if p, err = audio.NewProcessor(args[0], cfg.Channels, cfg.SampleRate); err == nil {
+			err = chain.Insert(position, p)
+		}
+	case "remove":
+		var position int
+		if position, err = slot(0, 0); err == nil {
+			err = chain.Remove(position)
+		}
+	case "set":
+		if len(args) != 3 {
+			return "", fmt.Errorf("usage: chain <input|master|aux<n>> set <slot> <param> <value>")
+		}
+		var position int
+		if position, err = slot(0, 0); err != nil {
+			break
+		}
+		if chain.Slots()[position].Builtin {
+			return "", fmt.Errorf("slot %d holds the built-in %s; use its own command", position+1, chain.Processor(position).Type())
+		}
+		value, perr := strconv.ParseFloat(args[2], 64)
+		if perr != nil {
+			return "", fmt.Errorf("invalid value %q", args[2])
+		}
+		err = chain.Processor(position).SetParameter(args[1], value)
+	default:
+		return "", fmt.Errorf("unknown chain action %q", action)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	*inserts = config.InsertsFromMixer(chain.Config())
+	return formatChain(name, chain), nil
+}
+
+// formatChain lists the slots of an insert chain with their parameters
+func formatChain(name string, chain *audio.InsertChain) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s chain, latency %v:", name, chain.Latency().Round(time.Microsecond))
+	for i, slot := range chain.Slots() {
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, slot.Processor.Type())
+		if slot.Builtin {
+			b.WriteString(" (built in)")
+		} else {
+			for _, param := range slot.Processor.Parameters() {
+				value, _ := slot.Processor.Parameter(param.Name)
+				fmt.Fprintf(&b, " %s=%g%s", param.Name, value, param.Unit)
+			}
+		}
+		if latency := slot.Processor.Latency(); latency > 0 {
+			fmt.Fprintf(&b, " (latency %v)", latency.Round(time.Microsecond))
+		}
+		if slot.Bypass {
+			b.WriteString(" [bypassed]")
+		}
+	}
+	return b.String()
+}
+
+// parseEQBand parses "<type> <Hz> [dB] [Q]" into an equalizer band
+func parseEQBand(fields []string) (config.EQBandConfig, error) {
+	if len(fields) < 2 || len(fields) > 4 {
+		return config.EQBandConfig{}, fmt.Errorf("usage: eq <input|master> add <type> <Hz> [dB] [Q]")
+	}
+
+	band := config.EQBandConfig{Type: fields[0], Q: 0.707}
+	values := []*float64{&band.FrequencyHz, new(float64), new(float64)}
+	for i, field := range fields[1:] {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return config.EQBandConfig{}, fmt.Errorf("invalid value %q", field)
+		}
+		*values[i] = value
+	}
+	if len(fields) > 2 {
+		band.GainDB = float32(*values[1])
+	}
+	if len(fields) > 3 {
+		band.Q = float32(*values[2])
+	}
+	return band, nil
+}
+
+// formatEQ lists the bands of an equalizer
+func formatEQ(name string, eq config.EQConfig) string {
+	state := "off"
+	if eq.Enabled {
+		state = "on"
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s EQ: %s", name, state)
+	for i, band := range eq.Bands {
+		fmt.Fprintf(&out, "\n  %d: %s %.0f Hz %+.1f dB Q %.2f", i+1, band.Type, band.FrequencyHz, band.GainDB, band.Q)
+	}
+	return out.String()
+}
+
+// parseInputNumber parses a 1-based input number typed by the user and
+// returns it with the mixer index
+func parseInputNumber(field string, mixer *audio.Mixer) (n, index int, err error) {
+	n, err = strconv.Atoi(field)
+	if err != nil || n < 1 || n > mixer.NumInputs() {
+		return 0, 0, fmt.Errorf("invalid input %q (1-%d)", field, mixer.NumInputs())
+	}
+	return n, n - 1, nil
+}
+
+// parseAuxNumber parses a 1-based aux bus number typed by the user and
+// returns it with the mixer index
+func parseAuxNumber(field string, mixer *audio.Mixer) (n, bus int, err error) {
+	n, err = strconv.Atoi(field)
+	if err != nil || n < 1 || n > mixer.NumAuxBuses() {
+		return 0, 0, fmt.Errorf("invalid aux bus %q (1-%d)", field, mixer.NumAuxBuses())
+	}
+	return n, n - 1, nil
+}
+
+// mixerDucking converts ducking settings for the mixer, whose inputs map
+// to configured inputs through configIndex
+func mixerDucking(c config.DuckingConfig, configIndex []int) audio.DuckingConfig {
+	trigger := -1
+	for mixerIndex, i := range configIndex {
+		if i == c.TriggerInput-1 {
+			trigger = mixerIndex
+		}
+	}
+	return c.Mixer(trigger)
+}
+
+// inputFilter returns an input's filter settings, creating default ones
+// first if the input has none
+func inputFilter(input *config.InputConfig) *config.FilterConfig {
+	if input.Filter == nil {
+		defaults := config.DefaultFilterConfig()
+		input.Filter = &defaults
+	}
+	return input.Filter
+}
+
+// inputDenoiser returns an input's noise suppression settings, creating
+// default ones first if the input has none
+func inputDenoiser(input *config.InputConfig) *config.DenoiserConfig {
+	if input.Denoiser == nil {
+		defaults := config.DefaultDenoiserConfig()
+		input.Denoiser = &defaults
+	}
+	return input.Denoiser
+}
+
+// inputAGC returns an input's AGC settings, creating default ones first
+// if the input has none
+func inputAGC(input *config.InputConfig) *config.AGCConfig {
+	if input.AGC == nil {
+		defaults := config.DefaultAGCConfig()
+		input.AGC = &defaults
+	}
+	return input.AGC
+}
+
+// inputGate returns an input's noise gate settings, creating default ones
+// first if the input has none
+func inputGate(input *config.InputConfig) *config.GateConfig {
+	if input.Gate == nil {
+		defaults := config.DefaultGateConfig()
+		input.Gate = &defaults
+	}
+	return input.Gate
+}
+
+// inputCompressor returns an input's compressor settings, creating
+// default ones first if the input has none
+func inputCompressor(input *config.InputConfig) *config.CompressorConfig {
+	if input.Compressor == nil {
+		defaults := config.DefaultCompressorConfig()
+		input.Compressor = &defaults
+	}
+	return input.Compressor
+}
+
+// inputDeEsser returns an input's de-esser settings, creating default ones
+// first if the input has none
+func inputDeEsser(input *config.InputConfig) *config.DeEsserConfig {
+	if input.DeEsser == nil {
+		defaults := config.DefaultDeEsserConfig()
+		input.DeEsser = &defaults
+	}
+	return input.DeEsser
+}
+
+// resolveInputDevice returns the device for an input, or nil if the input is disabled
+func resolveInputDevice(deviceManager *audio.DeviceManager, input config.InputConfig) (*audio.DeviceInfo, error) {
+	switch {
+	case input.DeviceIndex >= 0:
+		return deviceManager.GetDeviceByIndex(input.DeviceIndex)
+	case input.DeviceIndex == config.DeviceIndexDefault:
+		if input.Loopback {
+			loopback, err := audio.FindLoopbackDevice(deviceManager)
+			if err == nil {
+				return loopback.Device, nil
+			}
+			fmt.Printf("Warning: %v, using default input device for %s\n", err, input.Name)
+		}
+		return deviceManager.GetDefaultInputDevice()
+	default:
+		return nil, nil
+	}
+}
+
 // readInt reads an integer from stdin with a default value
 func readInt(reader *bufio.Reader, defaultValue int) int {
 	input, _ := reader.ReadString('\n')
@@ -262,8 +1344,13 @@ func readInt(reader *bufio.Reader, defaultValue int) int {
 	return value
 }
 
-// readFloat32 reads a float32 from stdin with a default value
+// readFloat32 reads a gain from stdin with a default value
 func readFloat32(reader *bufio.Reader, defaultValue float32) float32 {
+	return readFloat32Range(reader, defaultValue, 0, 2.0)
+}
+
+// readFloat32Range reads a float32 between lo and hi from stdin with a default value
+func readFloat32Range(reader *bufio.Reader, defaultValue, lo, hi float32) float32 {
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 
@@ -277,8 +1364,8 @@ func readFloat32(reader *bufio.Reader, defaultValue float32) float32 {
 		return defaultValue
 	}
 
-	if value < 0 || value > 2.0 {
-		fmt.Printf("Value out of range (0.0-2.0), using default: %.2f\n", defaultValue)
+	if float32(value) < lo || float32(value) > hi {
+		fmt.Printf("Value out of range (%.1f-%.1f), using default: %.2f\n", lo, hi, defaultValue)
 		return defaultValue
 	}
 